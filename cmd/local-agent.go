@@ -0,0 +1,154 @@
+// Copyright © 2026 Iggy <iggy@theiggy.com>
+// All rights reserved.
+//
+// Redistribution and use in source and binary forms, with or without
+// modification, are permitted provided that the following conditions are met:
+//
+// 1. Redistributions of source code must retain the above copyright notice,
+//    this list of conditions and the following disclaimer.
+//
+// 2. Redistributions in binary form must reproduce the above copyright notice,
+//    this list of conditions and the following disclaimer in the documentation
+//    and/or other materials provided with the distribution.
+//
+// 3. Neither the name of the copyright holder nor the names of its contributors
+//    may be used to endorse or promote products derived from this software
+//    without specific prior written permission.
+//
+// THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS "AS IS"
+// AND ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT LIMITED TO, THE
+// IMPLIED WARRANTIES OF MERCHANTABILITY AND FITNESS FOR A PARTICULAR PURPOSE
+// ARE DISCLAIMED. IN NO EVENT SHALL THE COPYRIGHT HOLDER OR CONTRIBUTORS BE
+// LIABLE FOR ANY DIRECT, INDIRECT, INCIDENTAL, SPECIAL, EXEMPLARY, OR
+// CONSEQUENTIAL DAMAGES (INCLUDING, BUT NOT LIMITED TO, PROCUREMENT OF
+// SUBSTITUTE GOODS OR SERVICES; LOSS OF USE, DATA, OR PROFITS; OR BUSINESS
+// INTERRUPTION) HOWEVER CAUSED AND ON ANY THEORY OF LIABILITY, WHETHER IN
+// CONTRACT, STRICT LIABILITY, OR TORT (INCLUDING NEGLIGENCE OR OTHERWISE)
+// ARISING IN ANY WAY OUT OF THE USE OF THIS SOFTWARE, EVEN IF ADVISED OF THE
+// POSSIBILITY OF SUCH DAMAGE.
+
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"os/signal"
+	"syscall"
+
+	"github.com/iggy/govern/pkg/laws"
+	"github.com/rs/zerolog/log"
+	"github.com/spf13/cobra"
+)
+
+// DefaultAgentListen - where the agent serves its status
+const DefaultAgentListen = "127.0.0.1:63010"
+
+// exit codes for agent --once
+const (
+	agentInSync  = 0
+	agentFailed  = 1
+	agentDrifted = 2
+)
+
+// agentCmd represents the agent command
+var agentCmd = &cobra.Command{
+	Use:   "agent",
+	Short: "keep checking the laws and report drift",
+	Long: `Keep checking the system against the laws. Every interval the laws are
+parsed again and pretended, laws that would change have drifted. Files that
+are templated or changed by the laws are watched too, touching one checks
+straight away.
+
+With --remediate, drifted laws tagged remediate (--remediate-tag) are
+applied, and journaled like any other apply.
+
+The last check is served as json on --listen (/status), with a 503 if
+anything has drifted or failed. --once checks a single time and exits 0 if
+the system matches the laws, 2 if it has drifted, 1 if the laws couldn't be
+parsed or failed.
+
+i.e. govern local agent -d /etc/govern/laws --interval 10m
+     govern local agent -d /etc/govern/laws --once
+`,
+	Run: func(cmd *cobra.Command, args []string) {
+		file, _ := cmd.Flags().GetString("file")
+		directory, _ := cmd.Flags().GetString("directory")
+		once, _ := cmd.Flags().GetBool("once")
+		listen, _ := cmd.Flags().GetString("listen")
+
+		agent := &laws.Agent{Path: file, Selector: selectorFromFlags(cmd)}
+		if directory != "" {
+			agent.Path = directory
+		}
+		if agent.Path == "" || agent.Path == laws.Stdin {
+			log.Fatal().Msg("agent: needs a laws file or directory")
+		}
+		agent.Interval, _ = cmd.Flags().GetDuration("interval")
+		agent.Workers, _ = cmd.Flags().GetInt("workers")
+		agent.Remediate, _ = cmd.Flags().GetBool("remediate")
+		agent.RemediateTag, _ = cmd.Flags().GetString("remediate-tag")
+		if agent.Interval <= 0 {
+			log.Fatal().Dur("interval", agent.Interval).Msg("agent: interval has to be more than 0")
+		}
+
+		ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+		defer stop()
+
+		if once {
+			status := agent.Check(ctx, "once")
+			for _, r := range status.Drifted {
+				fmt.Printf("drifted %s: %s\n", r.ID, r.Message)
+			}
+			for _, r := range status.Remediated {
+				fmt.Printf("remediated %s: %s\n", r.ID, r.Message)
+			}
+			for _, r := range status.Failed {
+				fmt.Printf("failed %s: %s\n", r.ID, r.Error)
+			}
+			switch {
+			case status.Error != "" || len(status.Failed) > 0:
+				os.Exit(agentFailed)
+			case len(status.Drifted) > 0:
+				os.Exit(agentDrifted)
+			}
+			fmt.Println("in sync")
+			os.Exit(agentInSync)
+		}
+
+		if listen != "" {
+			mux := http.NewServeMux()
+			mux.Handle("/status", agent)
+			server := &http.Server{Addr: listen, Handler: mux}
+			go func() {
+				err := server.ListenAndServe()
+				if err != nil && !errors.Is(err, http.ErrServerClosed) {
+					log.Fatal().Err(err).Str("listen", listen).Msg("agent: failed to serve status")
+				}
+			}()
+			defer server.Close()
+			log.Info().Str("listen", listen).Msg("agent: serving status")
+		}
+
+		err := agent.Run(ctx)
+		if err != nil {
+			log.Fatal().Err(err).Msg("agent: failed")
+		}
+	},
+}
+
+func init() {
+	localCmd.AddCommand(agentCmd)
+
+	agentCmd.Flags().StringP("file", "f", "", "local Laws yaml file")
+	agentCmd.Flags().StringP("directory", "d", "", "directory with Laws yaml files")
+	agentCmd.Flags().Duration("interval", laws.DefaultAgentInterval, "how often to check the laws")
+	agentCmd.Flags().Bool("once", false, "check once and exit, 0 in sync, 2 drifted, 1 failed")
+	agentCmd.Flags().String("listen", DefaultAgentListen, "address to serve the status on, empty to not serve it")
+	agentCmd.Flags().Bool("remediate", false, "apply drifted laws that have the remediate tag")
+	agentCmd.Flags().String("remediate-tag", laws.DefaultRemediateTag, "the tag of laws to remediate")
+	agentCmd.Flags().IntP("workers", "j", laws.DefaultWorkers, "how many laws to ensure at once")
+	addSelectorFlags(agentCmd)
+}
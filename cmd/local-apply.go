@@ -30,6 +30,10 @@
 package cmd
 
 import (
+	"context"
+	"fmt"
+	"path/filepath"
+
 	"github.com/iggy/govern/pkg/laws"
 	"github.com/rs/zerolog/log"
 	"github.com/spf13/cobra"
@@ -41,12 +45,13 @@ var applyCmd = &cobra.Command{
 	Short: "apply changes to a system from a local config",
 	Long: `Apply changes to a system using laws yaml templates from a
 local directory.
+
+With --plan only the changes in a plan from govern local plan are made, and
+only if the system hasn't changed since the plan was made.
 `,
 	Run: func(cmd *cobra.Command, args []string) {
 		file, _ := cmd.Flags().GetString("file")
 		directory, _ := cmd.Flags().GetString("directory")
-		log.Info().Str("file", file).Str("dir", directory).Msg("applying config")
-
 		var toParse string
 
 		if file != "" {
@@ -55,14 +60,40 @@ local directory.
 		if directory != "" {
 			toParse = directory
 		}
+		planFile, _ := cmd.Flags().GetString("plan")
+		var plan *laws.Plan
+		if planFile != "" {
+			var err error
+			plan, err = laws.ReadPlan(planFile)
+			if err != nil {
+				log.Fatal().Err(err).Msg("apply: failed to read plan")
+			}
+			toParse, err = planLaws(plan, toParse)
+			if err != nil {
+				log.Fatal().Err(err).Msg("apply: can't use plan")
+			}
+		}
+
+		log.Info().Str("laws", toParse).Str("plan", planFile).Msg("applying config")
+
 		sorted, err := laws.ParseFiles(toParse)
 		if err != nil {
 			log.Fatal().Msgf("lint: failed to process (%s): %v\n", toParse, err)
 		}
-		for _, v := range sorted {
-			l := v.Label().Law
-			l.Ensure(false)
+		if plan != nil {
+			workers, _ := cmd.Flags().GetInt("workers")
+			// Check pretends the laws again, what watches trigger when
+			// pretending doesn't carry over to the apply
+			sorted, err = plan.Check(context.Background(), sorted, workers)
+			if err != nil {
+				log.Fatal().Err(err).Str("plan", planFile).Msg("apply: refusing to apply plan")
+			}
+			if len(sorted) == 0 {
+				fmt.Println("the plan doesn't change anything")
+				return
+			}
 		}
+		runLaws(cmd, toParse, sorted, false)
 	},
 }
 
@@ -78,6 +109,24 @@ func init() {
 	// Cobra supports local flags which will only run when this command
 	// is called directly, e.g.:
 	// applyCmd.Flags().BoolP("toggle", "t", false, "Help message for toggle")
-	applyCmd.Flags().StringP("file", "f", "", "local Laws yaml file")
+	applyCmd.Flags().StringP("file", "f", "", "local Laws yaml file, - for stdin")
 	applyCmd.Flags().StringP("directory", "d", "", "directory with Laws yaml files")
+	applyCmd.Flags().String("plan", "", "only make the changes in a plan from govern local plan")
+	addRunFlags(applyCmd)
+}
+
+// planLaws - the laws a plan was made from, which have to be the ones being
+// applied if they were given too
+func planLaws(plan *laws.Plan, given string) (string, error) {
+	if given == "" {
+		return plan.Laws, nil
+	}
+	abs, err := filepath.Abs(given)
+	if err != nil {
+		return "", err
+	}
+	if abs != plan.Laws {
+		return "", fmt.Errorf("the plan was made from %s, not %s", plan.Laws, abs)
+	}
+	return abs, nil
 }
@@ -0,0 +1,100 @@
+// Copyright © 2026 Iggy <iggy@theiggy.com>
+// All rights reserved.
+//
+// Redistribution and use in source and binary forms, with or without
+// modification, are permitted provided that the following conditions are met:
+//
+// 1. Redistributions of source code must retain the above copyright notice,
+//    this list of conditions and the following disclaimer.
+//
+// 2. Redistributions in binary form must reproduce the above copyright notice,
+//    this list of conditions and the following disclaimer in the documentation
+//    and/or other materials provided with the distribution.
+//
+// 3. Neither the name of the copyright holder nor the names of its contributors
+//    may be used to endorse or promote products derived from this software
+//    without specific prior written permission.
+//
+// THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS "AS IS"
+// AND ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT LIMITED TO, THE
+// IMPLIED WARRANTIES OF MERCHANTABILITY AND FITNESS FOR A PARTICULAR PURPOSE
+// ARE DISCLAIMED. IN NO EVENT SHALL THE COPYRIGHT HOLDER OR CONTRIBUTORS BE
+// LIABLE FOR ANY DIRECT, INDIRECT, INCIDENTAL, SPECIAL, EXEMPLARY, OR
+// CONSEQUENTIAL DAMAGES (INCLUDING, BUT NOT LIMITED TO, PROCUREMENT OF
+// SUBSTITUTE GOODS OR SERVICES; LOSS OF USE, DATA, OR PROFITS; OR BUSINESS
+// INTERRUPTION) HOWEVER CAUSED AND ON ANY THEORY OF LIABILITY, WHETHER IN
+// CONTRACT, STRICT LIABILITY, OR TORT (INCLUDING NEGLIGENCE OR OTHERWISE)
+// ARISING IN ANY WAY OUT OF THE USE OF THIS SOFTWARE, EVEN IF ADVISED OF THE
+// POSSIBILITY OF SUCH DAMAGE.
+
+package cmd
+
+import (
+	"os"
+	"strings"
+
+	"github.com/iggy/govern/pkg/laws"
+	"github.com/rs/zerolog/log"
+	"github.com/spf13/cobra"
+)
+
+// graphCmd represents the graph command
+var graphCmd = &cobra.Command{
+	Use:   "graph",
+	Short: "show the dependency graph of the local config",
+	Long: `Draw the graph of laws, and the order they depend on each other, as a
+text tree, graphviz dot or mermaid.
+
+Each law is labeled with its group::type::name and the file it came from.
+Laws with no ordering relations at all, nothing they come after and nothing
+that comes after them, only hang off of the root and are marked as
+unattached. A law that others come after isn't, even if it comes after
+nothing itself. Use --from and --to to highlight how two laws are connected.
+
+i.e. govern local graph -d laws/ --format dot | dot -Tsvg > laws.svg
+`,
+	Run: func(cmd *cobra.Command, args []string) {
+		file, _ := cmd.Flags().GetString("file")
+		directory, _ := cmd.Flags().GetString("directory")
+		format, _ := cmd.Flags().GetString("format")
+		from, _ := cmd.Flags().GetString("from")
+		to, _ := cmd.Flags().GetString("to")
+		var toParse string
+
+		if file != "" {
+			toParse = file
+		}
+		if directory != "" {
+			toParse = directory
+		}
+		sorted, err := laws.ParseFiles(toParse)
+		if err != nil {
+			log.Fatal().Msgf("graph: failed to process (%s): %v\n", toParse, err)
+		}
+
+		graph := laws.NewLawGraph(sorted)
+		if from != "" || to != "" {
+			if from == "" || to == "" {
+				log.Fatal().Msg("graph: --from and --to have to be used together")
+			}
+			err = graph.Highlight(from, to)
+			if err != nil {
+				log.Fatal().Err(err).Msg("graph: can't highlight path")
+			}
+		}
+		err = graph.Write(os.Stdout, format)
+		if err != nil {
+			log.Fatal().Err(err).Msg("graph: failed to write graph")
+		}
+	},
+}
+
+func init() {
+	localCmd.AddCommand(graphCmd)
+
+	graphCmd.Flags().StringP("file", "f", "", "local Laws yaml file, - for stdin")
+	graphCmd.Flags().StringP("directory", "d", "", "directory with Laws yaml files")
+	graphCmd.Flags().String("format", "text", "output format ("+strings.Join(laws.GraphFormats, "|")+")")
+	graphCmd.Flags().String("from", "", "highlight the path from this law (group::type::name)")
+	graphCmd.Flags().String("to", "", "highlight the path to this law (group::type::name)")
+}
@@ -0,0 +1,142 @@
+// Copyright © 2026 Iggy <iggy@theiggy.com>
+// All rights reserved.
+//
+// Redistribution and use in source and binary forms, with or without
+// modification, are permitted provided that the following conditions are met:
+//
+// 1. Redistributions of source code must retain the above copyright notice,
+//    this list of conditions and the following disclaimer.
+//
+// 2. Redistributions in binary form must reproduce the above copyright notice,
+//    this list of conditions and the following disclaimer in the documentation
+//    and/or other materials provided with the distribution.
+//
+// 3. Neither the name of the copyright holder nor the names of its contributors
+//    may be used to endorse or promote products derived from this software
+//    without specific prior written permission.
+//
+// THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS "AS IS"
+// AND ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT LIMITED TO, THE
+// IMPLIED WARRANTIES OF MERCHANTABILITY AND FITNESS FOR A PARTICULAR PURPOSE
+// ARE DISCLAIMED. IN NO EVENT SHALL THE COPYRIGHT HOLDER OR CONTRIBUTORS BE
+// LIABLE FOR ANY DIRECT, INDIRECT, INCIDENTAL, SPECIAL, EXEMPLARY, OR
+// CONSEQUENTIAL DAMAGES (INCLUDING, BUT NOT LIMITED TO, PROCUREMENT OF
+// SUBSTITUTE GOODS OR SERVICES; LOSS OF USE, DATA, OR PROFITS; OR BUSINESS
+// INTERRUPTION) HOWEVER CAUSED AND ON ANY THEORY OF LIABILITY, WHETHER IN
+// CONTRACT, STRICT LIABILITY, OR TORT (INCLUDING NEGLIGENCE OR OTHERWISE)
+// ARISING IN ANY WAY OUT OF THE USE OF THIS SOFTWARE, EVEN IF ADVISED OF THE
+// POSSIBILITY OF SUCH DAMAGE.
+
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"text/tabwriter"
+	"time"
+
+	"github.com/iggy/govern/pkg/laws"
+	"github.com/rs/zerolog/log"
+	"github.com/spf13/cobra"
+)
+
+// historyCmd represents the history command
+var historyCmd = &cobra.Command{
+	Use:   "history [run-id]",
+	Short: "show what govern has changed on this system",
+	Long: `List the runs in the journal, or show everything about one of them:
+each law, what it changed, the files it touched (by hash) and the commands it
+ran. A run can be given by a unique prefix of its id, or "last".
+
+i.e. govern local history
+     govern local history last
+`,
+	Args: cobra.MaximumNArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		asJSON, _ := cmd.Flags().GetBool("json")
+
+		if len(args) == 0 {
+			runs, err := laws.ListJournal()
+			if err != nil {
+				log.Fatal().Err(err).Msg("history: failed to read journal")
+			}
+			if asJSON {
+				writeJSON(os.Stdout, runs)
+				return
+			}
+			w := tabwriter.NewWriter(os.Stdout, 0, 8, 2, ' ', 0)
+			fmt.Fprintln(w, "RUN\tSTARTED\tDURATION\tLAWS\tSUMMARY")
+			for _, run := range runs {
+				fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\n",
+					run.ID,
+					run.Started.Local().Format(time.DateTime),
+					run.Duration.Round(time.Millisecond),
+					run.Laws,
+					run.Summary)
+			}
+			w.Flush()
+			return
+		}
+
+		run, err := laws.ReadJournal(args[0])
+		if err != nil {
+			log.Fatal().Err(err).Msg("history: failed to read run")
+		}
+		if asJSON {
+			writeJSON(os.Stdout, run)
+			return
+		}
+		printRun(os.Stdout, run)
+	},
+}
+
+// writeJSON - indented json, or die trying
+func writeJSON(w io.Writer, v interface{}) {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	err := enc.Encode(v)
+	if err != nil {
+		log.Fatal().Err(err).Msg("failed to write json")
+	}
+}
+
+// printRun - a journaled run, law by law
+func printRun(w io.Writer, run *laws.JournalRun) {
+	fmt.Fprintf(w, "run:      %s\n", run.ID)
+	fmt.Fprintf(w, "started:  %s (%s)\n", run.Started.Local().Format(time.DateTime), run.Duration.Round(time.Millisecond))
+	fmt.Fprintf(w, "host:     %s\n", run.Host.Hostname)
+	fmt.Fprintf(w, "laws:     %s\n", run.Laws)
+	fmt.Fprintf(w, "summary:  %s\n", run.Summary)
+	if run.Error != "" {
+		fmt.Fprintf(w, "error:    %s\n", run.Error)
+	}
+	for _, r := range run.Results {
+		fmt.Fprintf(w, "\n%s %s\n", r.Status, r.ID)
+		if r.File != "" {
+			fmt.Fprintf(w, "  from:    %s\n", r.File)
+		}
+		if r.Message != "" {
+			fmt.Fprintf(w, "  message: %s\n", r.Message)
+		}
+		if r.Before != "" || r.After != "" {
+			fmt.Fprintf(w, "  change:  %q -> %q\n", r.Before, r.After)
+		}
+		if r.Error != "" {
+			fmt.Fprintf(w, "  error:   %s\n", r.Error)
+		}
+		for _, f := range r.Files {
+			fmt.Fprintf(w, "  file:    %s %s -> %s\n", f.Path, f.Before, f.After)
+		}
+		for _, c := range r.Commands {
+			fmt.Fprintf(w, "  command: %s (exit %d)\n", c.Command, c.ExitCode)
+		}
+	}
+}
+
+func init() {
+	localCmd.AddCommand(historyCmd)
+
+	historyCmd.Flags().Bool("json", false, "print the journal as json")
+}
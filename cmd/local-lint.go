@@ -30,6 +30,10 @@
 package cmd
 
 import (
+	"encoding/json"
+	"fmt"
+	"os"
+
 	"github.com/iggy/govern/pkg/laws"
 	"github.com/rs/zerolog/log"
 	"github.com/spf13/cobra"
@@ -39,14 +43,18 @@ import (
 var lintCmd = &cobra.Command{
 	Use:   "lint",
 	Short: "check syntax of the local config",
-	Long: `Check syntax of laws files.
+	Long: `Check laws files for problems without applying anything.
 
-Currently unimplemented
+Reports unknown keys, values of the wrong type, requisites that don't
+point at a law, dependency cycles and template errors. Each problem is
+printed as file:line:column: message, or use --format json for editor
+integration. Exits non-zero if any problems are found.
 `,
 	Run: func(cmd *cobra.Command, args []string) {
 		log.Trace().Msg("lint called")
 		file, _ := cmd.Flags().GetString("file")
 		directory, _ := cmd.Flags().GetString("directory")
+		format, _ := cmd.Flags().GetString("format")
 		var toParse string
 
 		if file != "" {
@@ -55,11 +63,33 @@ Currently unimplemented
 		if directory != "" {
 			toParse = directory
 		}
-		sorted, err := laws.ParseFiles(toParse)
+		diags, err := laws.Lint(toParse)
 		if err != nil {
 			log.Fatal().Msgf("lint: failed to process (%s): %v\n", toParse, err)
 		}
-		log.Trace().Interface("sorted", sorted).Msg("lint: ")
+
+		switch format {
+		case "json":
+			if diags == nil {
+				diags = []laws.Diagnostic{}
+			}
+			enc := json.NewEncoder(os.Stdout)
+			enc.SetEscapeHTML(false)
+			enc.SetIndent("", "  ")
+			err := enc.Encode(diags)
+			if err != nil {
+				log.Fatal().Err(err).Msg("lint: failed to marshal diagnostics")
+			}
+		case "text":
+			for _, d := range diags {
+				fmt.Println(d)
+			}
+		default:
+			log.Fatal().Str("format", format).Msg("lint: unknown format, expected text or json")
+		}
+		if len(diags) > 0 {
+			os.Exit(1)
+		}
 	},
 }
 
@@ -75,6 +105,7 @@ func init() {
 	// Cobra supports local flags which will only run when this command
 	// is called directly, e.g.:
 	// lintCmd.Flags().BoolP("toggle", "t", false, "Help message for toggle")
-	lintCmd.Flags().StringP("file", "f", "", "local state file")
+	lintCmd.Flags().StringP("file", "f", "", "local Laws yaml file, - for stdin")
 	lintCmd.Flags().StringP("directory", "d", "", "directory with Laws yaml files")
+	lintCmd.Flags().String("format", "text", "output format (text|json)")
 }
@@ -0,0 +1,122 @@
+// Copyright © 2026 Iggy <iggy@theiggy.com>
+// All rights reserved.
+//
+// Redistribution and use in source and binary forms, with or without
+// modification, are permitted provided that the following conditions are met:
+//
+// 1. Redistributions of source code must retain the above copyright notice,
+//    this list of conditions and the following disclaimer.
+//
+// 2. Redistributions in binary form must reproduce the above copyright notice,
+//    this list of conditions and the following disclaimer in the documentation
+//    and/or other materials provided with the distribution.
+//
+// 3. Neither the name of the copyright holder nor the names of its contributors
+//    may be used to endorse or promote products derived from this software
+//    without specific prior written permission.
+//
+// THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS "AS IS"
+// AND ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT LIMITED TO, THE
+// IMPLIED WARRANTIES OF MERCHANTABILITY AND FITNESS FOR A PARTICULAR PURPOSE
+// ARE DISCLAIMED. IN NO EVENT SHALL THE COPYRIGHT HOLDER OR CONTRIBUTORS BE
+// LIABLE FOR ANY DIRECT, INDIRECT, INCIDENTAL, SPECIAL, EXEMPLARY, OR
+// CONSEQUENTIAL DAMAGES (INCLUDING, BUT NOT LIMITED TO, PROCUREMENT OF
+// SUBSTITUTE GOODS OR SERVICES; LOSS OF USE, DATA, OR PROFITS; OR BUSINESS
+// INTERRUPTION) HOWEVER CAUSED AND ON ANY THEORY OF LIABILITY, WHETHER IN
+// CONTRACT, STRICT LIABILITY, OR TORT (INCLUDING NEGLIGENCE OR OTHERWISE)
+// ARISING IN ANY WAY OUT OF THE USE OF THIS SOFTWARE, EVEN IF ADVISED OF THE
+// POSSIBILITY OF SUCH DAMAGE.
+
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/iggy/govern/pkg/laws"
+	"github.com/rs/zerolog/log"
+	"github.com/spf13/cobra"
+)
+
+// planCmd represents the plan command
+var planCmd = &cobra.Command{
+	Use:   "plan",
+	Short: "save what an apply would change, to apply later",
+	Long: `Pretend the laws and save every law with the change it would make, and
+the state the change depends on (file hashes, package versions), as json.
+The plan can be reviewed, then applied with apply --plan, which only makes
+the planned changes and refuses if the system changed since the plan.
+
+i.e. govern local plan -d /etc/govern/laws -o plan.json
+     govern local apply --plan plan.json
+`,
+	Run: func(cmd *cobra.Command, args []string) {
+		file, _ := cmd.Flags().GetString("file")
+		directory, _ := cmd.Flags().GetString("directory")
+		output, _ := cmd.Flags().GetString("output")
+		workers, _ := cmd.Flags().GetInt("workers")
+
+		toParse := file
+		if directory != "" {
+			toParse = directory
+		}
+		if toParse == "" || toParse == laws.Stdin {
+			log.Fatal().Msg("plan: needs a laws file or directory, it's read again when the plan is applied")
+		}
+		if abs, err := filepath.Abs(toParse); err == nil {
+			toParse = abs
+		}
+		sorted, err := laws.ParseFiles(toParse)
+		if err != nil {
+			log.Fatal().Msgf("plan: failed to process (%s): %v\n", toParse, err)
+		}
+		sorted, err = selectorFromFlags(cmd).Filter(sorted)
+		if err != nil {
+			log.Fatal().Err(err).Msg("bad law selection")
+		}
+		plan, err := laws.NewPlan(context.Background(), toParse, sorted, workers)
+		if err != nil {
+			log.Fatal().Err(err).Msg("plan: failed to check law state")
+		}
+
+		out := os.Stdout
+		if output == "-" {
+			writeJSON(os.Stdout, plan)
+			out = os.Stderr
+		} else {
+			err = plan.Write(output)
+			if err != nil {
+				log.Fatal().Err(err).Str("file", output).Msg("plan: failed to write plan")
+			}
+		}
+		printPlan(out, plan)
+	},
+}
+
+// printPlan - the changes in a plan, one per line with the diffs of files
+func printPlan(w *os.File, plan *laws.Plan) {
+	for _, s := range plan.Steps {
+		switch s.Status {
+		case laws.StatusChanged:
+			fmt.Fprintf(w, "~ %s: %s (%q -> %q)\n", s.ID, s.Message, s.Before, s.After)
+			if s.Diff != "" {
+				fmt.Fprintln(w, colorDiff(w, s.Diff))
+			}
+		case laws.StatusFailed:
+			fmt.Fprintf(w, "! %s: %s\n", s.ID, s.Error)
+		}
+	}
+	fmt.Fprintln(w, plan.Summary)
+}
+
+func init() {
+	localCmd.AddCommand(planCmd)
+
+	planCmd.Flags().StringP("file", "f", "", "local Laws yaml file")
+	planCmd.Flags().StringP("directory", "d", "", "directory with Laws yaml files")
+	planCmd.Flags().StringP("output", "o", "-", "where to write the plan, - for stdout")
+	planCmd.Flags().IntP("workers", "j", laws.DefaultWorkers, "how many laws to ensure at once")
+	addSelectorFlags(planCmd)
+}
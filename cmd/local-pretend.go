@@ -59,46 +59,8 @@ var pretendCmd = &cobra.Command{
 		if err != nil {
 			log.Fatal().Msgf("lint: failed to process (%s): %v\n", toParse, err)
 		}
-		for _, v := range sorted {
-			log.Debug().Interface("v", v).Interface("v.Label", v.Label())
-			// vValues := reflect.ValueOf(v.Label().Law)
-			// vTypes := vValues.Type()
-
-			// for i := 0; i < vValues.Elem().NumField(); i++ {
-			// 	log.Debug().
-			// 		Interface("vValues i", vValues.Field(i)).
-			// 		Msgf("vValues i: %v - %v", vValues.Field(i), vValues.Field(i).Type())
-			// }
-
-			l := v.Label().Law
-			err = l.Ensure(true)
-			if err != nil {
-				// we don't need to fatal on a pretend
-				log.Error().
-					Err(err).
-					Str("name", v.Label().Name).
-					Str("type", v.Label().Type).
-					Msg("failed to ensure")
-			}
-
-			// log.Trace().
-			// 	Interface("v", v).
-			// 	Interface("vValues", vValues).
-			// 	Interface("vTypes", vTypes.Elem().Field(0)).
-			// 	Interface("type", reflect.TypeOf(v.Label().Law)).
-			// 	Msgf("90: %v - %v - %v",
-			// 		v,
-			// 		reflect.TypeOf(reflect.ValueOf(v.Label().Law).Interface()).String(),
-			// 		reflect.ValueOf(v.Label().Law).Interface(),
-			// 	)
-			// switch lt := reflect.ValueOf(v.Label().Law).Interface().(type) {
-			// case laws.User:
-			// 	log.Debug().Interface("lt", lt).Msgf("%v", lt)
-			// default:
-			// 	log.Debug().Interface("lt", lt).Msgf("default %v", lt)
-
-			// }
-		}
+		// we don't need to fatal on a pretend, failures are in the summary
+		runLaws(cmd, toParse, sorted, true)
 		// 		log.Debug().Msgf("distro slug: %s\n", facts.Facts.Distro.Slug)
 		// log.Debug().Msgf("hostname: %v\n", facts.Facts.Hostname)
 
@@ -135,6 +97,7 @@ func init() {
 	// Cobra supports local flags which will only run when this command
 	// is called directly, e.g.:
 	// pretendCmd.Flags().BoolP("toggle", "t", false, "Help message for toggle")
-	pretendCmd.Flags().StringP("file", "f", "", "local state file")
+	pretendCmd.Flags().StringP("file", "f", "", "local Laws yaml file, - for stdin")
 	pretendCmd.Flags().StringP("directory", "d", "", "directory with Laws yaml files")
+	addRunFlags(pretendCmd)
 }
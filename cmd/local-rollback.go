@@ -0,0 +1,98 @@
+// Copyright © 2026 Iggy <iggy@theiggy.com>
+// All rights reserved.
+//
+// Redistribution and use in source and binary forms, with or without
+// modification, are permitted provided that the following conditions are met:
+//
+// 1. Redistributions of source code must retain the above copyright notice,
+//    this list of conditions and the following disclaimer.
+//
+// 2. Redistributions in binary form must reproduce the above copyright notice,
+//    this list of conditions and the following disclaimer in the documentation
+//    and/or other materials provided with the distribution.
+//
+// 3. Neither the name of the copyright holder nor the names of its contributors
+//    may be used to endorse or promote products derived from this software
+//    without specific prior written permission.
+//
+// THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS "AS IS"
+// AND ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT LIMITED TO, THE
+// IMPLIED WARRANTIES OF MERCHANTABILITY AND FITNESS FOR A PARTICULAR PURPOSE
+// ARE DISCLAIMED. IN NO EVENT SHALL THE COPYRIGHT HOLDER OR CONTRIBUTORS BE
+// LIABLE FOR ANY DIRECT, INDIRECT, INCIDENTAL, SPECIAL, EXEMPLARY, OR
+// CONSEQUENTIAL DAMAGES (INCLUDING, BUT NOT LIMITED TO, PROCUREMENT OF
+// SUBSTITUTE GOODS OR SERVICES; LOSS OF USE, DATA, OR PROFITS; OR BUSINESS
+// INTERRUPTION) HOWEVER CAUSED AND ON ANY THEORY OF LIABILITY, WHETHER IN
+// CONTRACT, STRICT LIABILITY, OR TORT (INCLUDING NEGLIGENCE OR OTHERWISE)
+// ARISING IN ANY WAY OUT OF THE USE OF THIS SOFTWARE, EVEN IF ADVISED OF THE
+// POSSIBILITY OF SUCH DAMAGE.
+
+package cmd
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/iggy/govern/pkg/laws"
+	"github.com/rs/zerolog/log"
+	"github.com/spf13/cobra"
+)
+
+// rollbackCmd represents the rollback command
+var rollbackCmd = &cobra.Command{
+	Use:   "rollback <run-id>",
+	Short: "put the files a run changed back how they were",
+	Long: `Restore every file a journaled run changed from the backups taken during
+the run, and remove the files it created. Files that have changed since the
+run are skipped unless --force is given. Only files are rolled back, not
+packages, services, users etc.
+
+i.e. govern local rollback last --pretend
+     govern local rollback 20260101T120000Z
+`,
+	Args: cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		pretend, _ := cmd.Flags().GetBool("pretend")
+		force, _ := cmd.Flags().GetBool("force")
+
+		run, err := laws.ReadJournal(args[0])
+		if err != nil {
+			log.Fatal().Err(err).Msg("rollback: failed to read run")
+		}
+
+		actions := laws.Rollback(run, pretend, force)
+		if len(actions) == 0 {
+			fmt.Printf("run %s didn't change any files\n", run.ID)
+			return
+		}
+		failed := 0
+		for _, a := range actions {
+			action := a.Action
+			if pretend && a.Action != laws.RollbackSkip {
+				action = "would " + action
+			}
+			switch {
+			case a.Err != nil:
+				failed++
+				fmt.Printf("%s %s: %v\n", action, a.Path, a.Err)
+			case a.Action == laws.RollbackSkip:
+				fmt.Printf("%s %s: %s\n", action, a.Path, a.Reason)
+			case a.Hash != "":
+				fmt.Printf("%s %s (%s)\n", action, a.Path, a.Hash)
+			default:
+				fmt.Printf("%s %s\n", action, a.Path)
+			}
+		}
+		if failed > 0 {
+			log.Error().Int("failed", failed).Str("run", run.ID).Msg("rollback failed")
+			os.Exit(1)
+		}
+	},
+}
+
+func init() {
+	localCmd.AddCommand(rollbackCmd)
+
+	rollbackCmd.Flags().BoolP("pretend", "p", false, "show what would be restored without changing anything")
+	rollbackCmd.Flags().Bool("force", false, "restore files even if they changed since the run")
+}
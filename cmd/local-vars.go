@@ -0,0 +1,91 @@
+// Copyright © 2026 Iggy <iggy@theiggy.com>
+// All rights reserved.
+//
+// Redistribution and use in source and binary forms, with or without
+// modification, are permitted provided that the following conditions are met:
+//
+// 1. Redistributions of source code must retain the above copyright notice,
+//    this list of conditions and the following disclaimer.
+//
+// 2. Redistributions in binary form must reproduce the above copyright notice,
+//    this list of conditions and the following disclaimer in the documentation
+//    and/or other materials provided with the distribution.
+//
+// 3. Neither the name of the copyright holder nor the names of its contributors
+//    may be used to endorse or promote products derived from this software
+//    without specific prior written permission.
+//
+// THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS "AS IS"
+// AND ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT LIMITED TO, THE
+// IMPLIED WARRANTIES OF MERCHANTABILITY AND FITNESS FOR A PARTICULAR PURPOSE
+// ARE DISCLAIMED. IN NO EVENT SHALL THE COPYRIGHT HOLDER OR CONTRIBUTORS BE
+// LIABLE FOR ANY DIRECT, INDIRECT, INCIDENTAL, SPECIAL, EXEMPLARY, OR
+// CONSEQUENTIAL DAMAGES (INCLUDING, BUT NOT LIMITED TO, PROCUREMENT OF
+// SUBSTITUTE GOODS OR SERVICES; LOSS OF USE, DATA, OR PROFITS; OR BUSINESS
+// INTERRUPTION) HOWEVER CAUSED AND ON ANY THEORY OF LIABILITY, WHETHER IN
+// CONTRACT, STRICT LIABILITY, OR TORT (INCLUDING NEGLIGENCE OR OTHERWISE)
+// ARISING IN ANY WAY OUT OF THE USE OF THIS SOFTWARE, EVEN IF ADVISED OF THE
+// POSSIBILITY OF SUCH DAMAGE.
+
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"text/tabwriter"
+
+	"github.com/iggy/govern/pkg/laws"
+	"github.com/rs/zerolog/log"
+	"github.com/spf13/cobra"
+)
+
+// varsCmd represents the vars command
+var varsCmd = &cobra.Command{
+	Use:   "vars",
+	Short: "show the vars the laws templates get",
+	Long: `Show the merged vars the laws templates get as .vars, and which layer
+each value came from.
+
+Vars are read from the vars directory next to the laws, each layer
+overriding the ones before it
+  vars/defaults.yaml
+  vars/family/<Distro.Family>.yaml
+  vars/distro/<Distro.Slug>.yaml
+  vars/hosts/<hostname>.yaml
+  --var key=value
+
+i.e. govern local vars -d laws/ --var nginx.port=8080
+`,
+	Run: func(cmd *cobra.Command, args []string) {
+		file, _ := cmd.Flags().GetString("file")
+		directory, _ := cmd.Flags().GetString("directory")
+		var toLoad string
+
+		if file != "" {
+			toLoad = file
+		}
+		if directory != "" {
+			toLoad = directory
+		}
+		vars, err := laws.LoadVars(toLoad)
+		if err != nil {
+			log.Fatal().Err(err).Msg("vars: failed to load vars")
+		}
+
+		w := tabwriter.NewWriter(os.Stdout, 0, 8, 2, ' ', 0)
+		fmt.Fprintln(w, "KEY\tVALUE\tFROM")
+		for _, k := range vars.Keys() {
+			value, _ := json.Marshal(vars.Get(k))
+			fmt.Fprintf(w, "%s\t%s\t%s\n", k, value, vars.From[k])
+		}
+		w.Flush()
+	},
+}
+
+func init() {
+	localCmd.AddCommand(varsCmd)
+
+	varsCmd.Flags().StringP("file", "f", "", "local Laws yaml file, - for stdin")
+	varsCmd.Flags().StringP("directory", "d", "", "directory with Laws yaml files")
+}
@@ -30,6 +30,19 @@
 package cmd
 
 import (
+	"context"
+	"fmt"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/hmdsefi/gograph"
+	"github.com/iggy/govern/pkg/laws"
+	"github.com/mattn/go-isatty"
+	"github.com/rs/zerolog/log"
 	"github.com/spf13/cobra"
 )
 
@@ -43,10 +56,182 @@ Includes commands to apply local configs and do linting of states.
 This is just the parent command for all the local commands. It doesn't do
 anything on it's own.
 `,
+	PersistentPreRun: func(cmd *cobra.Command, args []string) {
+		pluginDir, _ := cmd.Flags().GetString("plugin-dir")
+		err := laws.LoadPlugins(pluginDir)
+		if err != nil {
+			log.Fatal().Err(err).Str("dir", pluginDir).Msg("failed to load plugins")
+		}
+		laws.IdentityFile, _ = cmd.Flags().GetString("identity")
+		laws.JournalDir, _ = cmd.Flags().GetString("journal-dir")
+		vars, _ := cmd.Flags().GetStringArray("var")
+		err = laws.SetVarOverrides(vars)
+		if err != nil {
+			log.Fatal().Err(err).Msg("bad --var")
+		}
+	},
 	Run: func(cmd *cobra.Command, args []string) {
 	},
 }
 
 func init() {
 	rootCmd.AddCommand(localCmd)
+
+	localCmd.PersistentFlags().String("plugin-dir", laws.DefaultPluginDir, "directory of external law plugins")
+	localCmd.PersistentFlags().String("journal-dir", laws.DefaultJournalDir, "where applied runs are journaled")
+	localCmd.PersistentFlags().String("identity", laws.DefaultIdentityFile, "key used to decrypt secrets in laws templates")
+	localCmd.PersistentFlags().StringArray("var", nil, "set a template var, overriding the vars files (i.e. --var nginx.port=8080)")
+}
+
+// addRunFlags - flags for the commands that run laws
+func addRunFlags(cmd *cobra.Command) {
+	cmd.Flags().IntP("workers", "j", laws.DefaultWorkers, "how many laws to ensure at once")
+	cmd.Flags().String("on-error", string(laws.OnErrorContinue), "when a law fails, continue with unrelated laws or abort the run (continue|abort)")
+	cmd.Flags().String("report", "", "write a report of the run ("+strings.Join(laws.ReportFormats, "|")+")")
+	cmd.Flags().String("report-file", "-", "where to write the report, - for stdout")
+	addSelectorFlags(cmd)
+}
+
+// addSelectorFlags - flags for picking which laws to run
+func addSelectorFlags(cmd *cobra.Command) {
+	cmd.Flags().StringSlice("only", nil, "only run these laws, by id (group::type::name), group::type or group")
+	cmd.Flags().StringSlice("skip", nil, "don't run these laws, by id (group::type::name), group::type or group")
+	cmd.Flags().StringSlice("tags", nil, "only run laws with any of these tags")
+	cmd.Flags().Bool("no-deps", false, "don't run the laws that the selected laws come after")
+}
+
+// selectorFromFlags - the law selector from --only/--skip/--tags/--no-deps
+func selectorFromFlags(cmd *cobra.Command) laws.Selector {
+	var sel laws.Selector
+	sel.Only, _ = cmd.Flags().GetStringSlice("only")
+	sel.Skip, _ = cmd.Flags().GetStringSlice("skip")
+	sel.Tags, _ = cmd.Flags().GetStringSlice("tags")
+	sel.NoDeps, _ = cmd.Flags().GetBool("no-deps")
+	return sel
+}
+
+// runLaws - ensure the laws using the run flags, print a summary and exit
+// non-zero if anything failed. Ctrl-C cancels the run, anything that never
+// ran is listed. Applied runs are added to the journal.
+func runLaws(cmd *cobra.Command, lawsPath string, sorted []*gograph.Vertex[*laws.LawNode], pretend bool) {
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	workers, _ := cmd.Flags().GetInt("workers")
+	onError, _ := cmd.Flags().GetString("on-error")
+	reportFormat, _ := cmd.Flags().GetString("report")
+	reportFile, _ := cmd.Flags().GetString("report-file")
+	executor := laws.NewExecutor(workers, pretend)
+	var err error
+	executor.OnError, err = laws.ParseErrorPolicy(onError)
+	if err != nil {
+		log.Fatal().Err(err).Msg("bad --on-error")
+	}
+	sorted, err = selectorFromFlags(cmd).Filter(sorted)
+	if err != nil {
+		log.Fatal().Err(err).Msg("bad law selection")
+	}
+	started := time.Now()
+	results := executor.Run(ctx, sorted)
+	report := laws.NewReport(results, pretend, started)
+
+	if !pretend {
+		if abs, err := filepath.Abs(lawsPath); err == nil && lawsPath != laws.Stdin {
+			lawsPath = abs
+		}
+		run := &laws.JournalRun{ID: laws.NewRunID(), Laws: lawsPath, Report: *report}
+		err = laws.WriteJournal(run)
+		if err != nil {
+			log.Error().Err(err).Str("dir", laws.JournalDir).Msg("failed to write journal")
+		} else {
+			log.Info().Str("run", run.ID).Msg("run journaled")
+		}
+	}
+
+	// keep stdout clean for the report if that's where it's going
+	out := os.Stdout
+	if reportFormat != "" {
+		if reportFile == "-" {
+			out = os.Stderr
+		}
+		err = writeReport(report, reportFormat, reportFile)
+		if err != nil {
+			log.Error().Err(err).Str("file", reportFile).Msg("failed to write report")
+		}
+	}
+
+	if pretend {
+		for _, r := range report.Results {
+			if r.Diff != "" {
+				fmt.Fprintf(out, "~ %s\n%s\n", r.ID, colorDiff(out, r.Diff))
+			}
+		}
+	}
+
+	summary := laws.Summarize(results)
+	if summary.Cancelled > 0 {
+		fmt.Fprintln(out, "cancelled, these laws never ran:")
+		for _, r := range results {
+			if r.Status == laws.StatusCancelled {
+				fmt.Fprintf(out, "  %s\n", r.ID)
+			}
+		}
+	}
+	fmt.Fprintln(out, summary)
+	if summary.Failed > 0 || summary.Cancelled > 0 {
+		os.Exit(1)
+	}
+}
+
+// diff colors, for terminals
+const (
+	colorReset  = "\033[0m"
+	colorBold   = "\033[1m"
+	colorRed    = "\033[31m"
+	colorGreen  = "\033[32m"
+	colorYellow = "\033[33m"
+	colorCyan   = "\033[36m"
+)
+
+// colorDiff - color a diff from a law if it's going to a terminal
+func colorDiff(out *os.File, diff string) string {
+	if os.Getenv("NO_COLOR") != "" || !isatty.IsTerminal(out.Fd()) {
+		return strings.TrimRight(diff, "\n")
+	}
+	lines := strings.Split(strings.TrimRight(diff, "\n"), "\n")
+	for i, line := range lines {
+		color := ""
+		switch {
+		case strings.HasPrefix(line, "---"), strings.HasPrefix(line, "+++"):
+			color = colorBold
+		case strings.HasPrefix(line, "@@"):
+			color = colorCyan
+		case strings.HasPrefix(line, "+"):
+			color = colorGreen
+		case strings.HasPrefix(line, "-"):
+			color = colorRed
+		case strings.HasPrefix(line, "mode "), strings.HasPrefix(line, "owner "), strings.HasPrefix(line, "new file mode "):
+			color = colorYellow
+		}
+		if color != "" {
+			lines[i] = color + line + colorReset
+		}
+	}
+	return strings.Join(lines, "\n")
+}
+
+// writeReport - write a run report to a file, or stdout for -
+func writeReport(report *laws.Report, format, path string) error {
+	if path == "-" {
+		return report.Write(os.Stdout, format)
+	}
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	err = report.Write(f, format)
+	if cerr := f.Close(); err == nil {
+		err = cerr
+	}
+	return err
 }
@@ -10,6 +10,7 @@ import (
 	"github.com/rs/zerolog/log"
 	"github.com/spf13/cobra"
 
+	"github.com/iggy/govern/pkg/laws"
 	"github.com/iggy/govern/pkg/mesh"
 )
 
@@ -192,6 +193,12 @@ var (
 	meshApplyNode    string
 	meshApplyFiles   []string
 	meshApplyDryRun  bool
+	meshApplyWorkers int
+	meshApplyOnError string
+	meshApplyOnly    []string
+	meshApplySkip    []string
+	meshApplyTags    []string
+	meshApplyNoDeps  bool
 	meshApplyTimeout int
 )
 
@@ -210,6 +217,14 @@ var meshApplyCmd = &cobra.Command{
 		payload := mesh.ApplyLawsPayload{
 			LawFiles: meshApplyFiles,
 			DryRun:   meshApplyDryRun,
+			Workers:  meshApplyWorkers,
+			OnError:  meshApplyOnError,
+			Selector: laws.Selector{
+				Only:   meshApplyOnly,
+				Skip:   meshApplySkip,
+				Tags:   meshApplyTags,
+				NoDeps: meshApplyNoDeps,
+			},
 		}
 
 		payloadData, err := json.Marshal(payload)
@@ -242,12 +257,64 @@ var meshApplyCmd = &cobra.Command{
 	},
 }
 
+var (
+	meshJournalNode    string
+	meshJournalRun     string
+	meshJournalLimit   int
+	meshJournalTimeout int
+)
+
+var meshJournalCmd = &cobra.Command{
+	Use:   "journal",
+	Short: "Get the journal from mesh node",
+	Long:  `Get the runs govern has journaled on a specific mesh node, or a single run.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		if meshJournalNode == "" {
+			log.Fatal().Msg("node address is required (use --node)")
+		}
+
+		payload := mesh.JournalPayload{
+			RunID: meshJournalRun,
+			Limit: meshJournalLimit,
+		}
+
+		payloadData, err := json.Marshal(payload)
+		if err != nil {
+			log.Fatal().Err(err).Msg("failed to marshal payload")
+		}
+
+		command := mesh.Command{
+			Type:    mesh.CommandTypeJournal,
+			Payload: payloadData,
+		}
+
+		client := mesh.NewClient(fmt.Sprintf("http://%s", meshJournalNode), log.Logger)
+
+		timeout := time.Duration(meshJournalTimeout) * time.Second
+		ctx, cancel := context.WithTimeout(context.Background(), timeout)
+		defer cancel()
+
+		result, err := client.ExecuteCommand(ctx, command)
+		if err != nil {
+			log.Fatal().Err(err).Msg("failed to get journal")
+		}
+
+		output, err := json.MarshalIndent(result, "", "  ")
+		if err != nil {
+			log.Fatal().Err(err).Msg("failed to marshal result")
+		}
+
+		fmt.Println(string(output))
+	},
+}
+
 func init() {
 	meshCmd.AddCommand(statusCmd)
 	meshCmd.AddCommand(nodesCmd)
 	meshCmd.AddCommand(execCmd)
 	meshCmd.AddCommand(meshFactsCmd)
 	meshCmd.AddCommand(meshApplyCmd)
+	meshCmd.AddCommand(meshJournalCmd)
 
 	// Status command flags
 	statusCmd.Flags().StringVar(&meshStatusNode, "node", "", "HTTP address of mesh node (host:port)")
@@ -277,7 +344,20 @@ func init() {
 	meshApplyCmd.Flags().StringVar(&meshApplyNode, "node", "", "HTTP address of mesh node (host:port)")
 	meshApplyCmd.Flags().StringSliceVar(&meshApplyFiles, "files", nil, "Law files to apply")
 	meshApplyCmd.Flags().BoolVar(&meshApplyDryRun, "dry-run", false, "Perform dry run without applying changes")
+	meshApplyCmd.Flags().IntVar(&meshApplyWorkers, "workers", 0, "How many laws to ensure at once (default: node decides)")
+	meshApplyCmd.Flags().StringVar(&meshApplyOnError, "on-error", "", "When a law fails, continue with unrelated laws or abort the run (continue|abort)")
+	meshApplyCmd.Flags().StringSliceVar(&meshApplyOnly, "only", nil, "Only run these laws, by id (group::type::name), group::type or group")
+	meshApplyCmd.Flags().StringSliceVar(&meshApplySkip, "skip", nil, "Don't run these laws, by id (group::type::name), group::type or group")
+	meshApplyCmd.Flags().StringSliceVar(&meshApplyTags, "tags", nil, "Only run laws with any of these tags")
+	meshApplyCmd.Flags().BoolVar(&meshApplyNoDeps, "no-deps", false, "Don't run the laws that the selected laws come after")
 	meshApplyCmd.Flags().IntVar(&meshApplyTimeout, "timeout", 60, "Timeout in seconds")
 	meshApplyCmd.MarkFlagRequired("node")
 	meshApplyCmd.MarkFlagRequired("files")
+
+	// Journal command flags
+	meshJournalCmd.Flags().StringVar(&meshJournalNode, "node", "", "HTTP address of mesh node (host:port)")
+	meshJournalCmd.Flags().StringVar(&meshJournalRun, "run", "", "Only this run, by id, unique prefix of one, or last")
+	meshJournalCmd.Flags().IntVar(&meshJournalLimit, "limit", 0, "Only the most recent runs")
+	meshJournalCmd.Flags().IntVar(&meshJournalTimeout, "timeout", 30, "Timeout in seconds")
+	meshJournalCmd.MarkFlagRequired("node")
 }
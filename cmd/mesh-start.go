@@ -43,6 +43,7 @@ import (
 	"github.com/spf13/cobra"
 	"github.com/spf13/viper"
 
+	"github.com/iggy/govern/pkg/laws"
 	"github.com/iggy/govern/pkg/mesh"
 )
 
@@ -53,6 +54,9 @@ var (
 	meshDataDir        string
 	meshInitialMembers []string
 	meshJoin           bool
+	meshPluginDir      string
+	meshIdentity       string
+	meshJournalDir     string
 )
 
 // startCmd represents the start command
@@ -110,6 +114,14 @@ Examples:
 			log.Fatal().Err(err).Str("dir", meshDataDir).Msg("failed to create data directory")
 		}
 
+		// a broken plugin shouldn't keep the node from joining the mesh,
+		// its laws will just fail to parse
+		if err := laws.LoadPlugins(meshPluginDir); err != nil {
+			log.Error().Err(err).Str("dir", meshPluginDir).Msg("failed to load plugins")
+		}
+		laws.IdentityFile = meshIdentity
+		laws.JournalDir = meshJournalDir
+
 		cfg := mesh.Config{
 			ReplicaID:      meshReplicaID,
 			RaftAddress:    meshRaftAddress,
@@ -174,6 +186,9 @@ func init() {
 	startCmd.Flags().StringVar(&meshDataDir, "data-dir", "", "Data directory for mesh storage (default: ~/.govern/mesh-data)")
 	startCmd.Flags().StringSliceVar(&meshInitialMembers, "initial-members", nil, "Initial cluster members in format id=address (required when joining)")
 	startCmd.Flags().BoolVar(&meshJoin, "join", false, "Join existing cluster instead of creating new one")
+	startCmd.Flags().StringVar(&meshPluginDir, "plugin-dir", laws.DefaultPluginDir, "Directory of external law plugins")
+	startCmd.Flags().StringVar(&meshJournalDir, "journal-dir", laws.DefaultJournalDir, "Where applied runs are journaled")
+	startCmd.Flags().StringVar(&meshIdentity, "identity", laws.DefaultIdentityFile, "Key used to decrypt secrets in laws templates")
 
 	startCmd.MarkFlagRequired("replica-id")
 	startCmd.MarkFlagRequired("raft-address")
@@ -185,4 +200,7 @@ func init() {
 	viper.BindPFlag("mesh.data-dir", startCmd.Flags().Lookup("data-dir"))
 	viper.BindPFlag("mesh.initial-members", startCmd.Flags().Lookup("initial-members"))
 	viper.BindPFlag("mesh.join", startCmd.Flags().Lookup("join"))
+	viper.BindPFlag("mesh.plugin-dir", startCmd.Flags().Lookup("plugin-dir"))
+	viper.BindPFlag("mesh.identity", startCmd.Flags().Lookup("identity"))
+	viper.BindPFlag("mesh.journal-dir", startCmd.Flags().Lookup("journal-dir"))
 }
@@ -0,0 +1,73 @@
+// Copyright © 2026 Iggy <iggy@theiggy.com>
+// All rights reserved.
+//
+// Redistribution and use in source and binary forms, with or without
+// modification, are permitted provided that the following conditions are met:
+//
+// 1. Redistributions of source code must retain the above copyright notice,
+//    this list of conditions and the following disclaimer.
+//
+// 2. Redistributions in binary form must reproduce the above copyright notice,
+//    this list of conditions and the following disclaimer in the documentation
+//    and/or other materials provided with the distribution.
+//
+// 3. Neither the name of the copyright holder nor the names of its contributors
+//    may be used to endorse or promote products derived from this software
+//    without specific prior written permission.
+//
+// THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS "AS IS"
+// AND ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT LIMITED TO, THE
+// IMPLIED WARRANTIES OF MERCHANTABILITY AND FITNESS FOR A PARTICULAR PURPOSE
+// ARE DISCLAIMED. IN NO EVENT SHALL THE COPYRIGHT HOLDER OR CONTRIBUTORS BE
+// LIABLE FOR ANY DIRECT, INDIRECT, INCIDENTAL, SPECIAL, EXEMPLARY, OR
+// CONSEQUENTIAL DAMAGES (INCLUDING, BUT NOT LIMITED TO, PROCUREMENT OF
+// SUBSTITUTE GOODS OR SERVICES; LOSS OF USE, DATA, OR PROFITS; OR BUSINESS
+// INTERRUPTION) HOWEVER CAUSED AND ON ANY THEORY OF LIABILITY, WHETHER IN
+// CONTRACT, STRICT LIABILITY, OR TORT (INCLUDING NEGLIGENCE OR OTHERWISE)
+// ARISING IN ANY WAY OUT OF THE USE OF THIS SOFTWARE, EVEN IF ADVISED OF THE
+// POSSIBILITY OF SUCH DAMAGE.
+
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/iggy/govern/pkg/laws"
+	"github.com/rs/zerolog/log"
+	"github.com/spf13/cobra"
+)
+
+// encryptCmd represents the secret encrypt command
+var encryptCmd = &cobra.Command{
+	Use:   "encrypt [file]",
+	Short: "encrypt a secret",
+	Long: `Encrypt a file, or stdin, to one or more recipients. The encrypted value
+can go straight into a laws file or in a file of its own, either way the
+secret template function decrypts it.
+
+i.e. printf hunter2 | govern secret encrypt -R recipients > secrets/db_password
+     password: '{{ secret "secrets/db_password" }}'
+`,
+	Args: cobra.MaximumNArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		recipients, err := recipientsFromFlags(cmd)
+		if err != nil {
+			log.Fatal().Err(err).Msg("encrypt: bad recipients")
+		}
+		plain, err := readInput(args)
+		if err != nil {
+			log.Fatal().Err(err).Msg("encrypt: failed to read secret")
+		}
+		value, err := laws.EncryptSecret(plain, recipients)
+		if err != nil {
+			log.Fatal().Err(err).Msg("encrypt: failed to encrypt")
+		}
+		fmt.Println(value)
+	},
+}
+
+func init() {
+	secretCmd.AddCommand(encryptCmd)
+
+	addRecipientFlags(encryptCmd)
+}
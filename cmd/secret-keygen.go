@@ -0,0 +1,81 @@
+// Copyright © 2026 Iggy <iggy@theiggy.com>
+// All rights reserved.
+//
+// Redistribution and use in source and binary forms, with or without
+// modification, are permitted provided that the following conditions are met:
+//
+// 1. Redistributions of source code must retain the above copyright notice,
+//    this list of conditions and the following disclaimer.
+//
+// 2. Redistributions in binary form must reproduce the above copyright notice,
+//    this list of conditions and the following disclaimer in the documentation
+//    and/or other materials provided with the distribution.
+//
+// 3. Neither the name of the copyright holder nor the names of its contributors
+//    may be used to endorse or promote products derived from this software
+//    without specific prior written permission.
+//
+// THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS "AS IS"
+// AND ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT LIMITED TO, THE
+// IMPLIED WARRANTIES OF MERCHANTABILITY AND FITNESS FOR A PARTICULAR PURPOSE
+// ARE DISCLAIMED. IN NO EVENT SHALL THE COPYRIGHT HOLDER OR CONTRIBUTORS BE
+// LIABLE FOR ANY DIRECT, INDIRECT, INCIDENTAL, SPECIAL, EXEMPLARY, OR
+// CONSEQUENTIAL DAMAGES (INCLUDING, BUT NOT LIMITED TO, PROCUREMENT OF
+// SUBSTITUTE GOODS OR SERVICES; LOSS OF USE, DATA, OR PROFITS; OR BUSINESS
+// INTERRUPTION) HOWEVER CAUSED AND ON ANY THEORY OF LIABILITY, WHETHER IN
+// CONTRACT, STRICT LIABILITY, OR TORT (INCLUDING NEGLIGENCE OR OTHERWISE)
+// ARISING IN ANY WAY OUT OF THE USE OF THIS SOFTWARE, EVEN IF ADVISED OF THE
+// POSSIBILITY OF SUCH DAMAGE.
+
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/iggy/govern/pkg/laws"
+	"github.com/rs/zerolog/log"
+	"github.com/spf13/cobra"
+)
+
+// keygenCmd represents the secret keygen command
+var keygenCmd = &cobra.Command{
+	Use:   "keygen",
+	Short: "make a new identity for decrypting secrets",
+	Long: `Make a new identity (private key) and print its public key, which is
+what secrets get encrypted to. An existing identity is never overwritten.
+
+i.e. govern secret keygen -o /etc/govern/secret.key
+`,
+	Run: func(cmd *cobra.Command, args []string) {
+		output, _ := cmd.Flags().GetString("output")
+
+		id, err := laws.GenerateIdentity()
+		if err != nil {
+			log.Fatal().Err(err).Msg("keygen: failed to make identity")
+		}
+		recipient := id.Recipient()
+
+		err = os.MkdirAll(filepath.Dir(output), 0o700)
+		if err != nil {
+			log.Fatal().Err(err).Str("file", output).Msg("keygen: failed to make directory")
+		}
+		f, err := os.OpenFile(output, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0o600)
+		if err != nil {
+			log.Fatal().Err(err).Str("file", output).Msg("keygen: failed to create identity file")
+		}
+		fmt.Fprintf(f, "# public key: %s\n%s\n", recipient, id)
+		err = f.Close()
+		if err != nil {
+			log.Fatal().Err(err).Str("file", output).Msg("keygen: failed to write identity file")
+		}
+		fmt.Println(recipient)
+	},
+}
+
+func init() {
+	secretCmd.AddCommand(keygenCmd)
+
+	keygenCmd.Flags().StringP("output", "o", laws.DefaultIdentityFile, "where to write the identity")
+}
@@ -0,0 +1,109 @@
+// Copyright © 2026 Iggy <iggy@theiggy.com>
+// All rights reserved.
+//
+// Redistribution and use in source and binary forms, with or without
+// modification, are permitted provided that the following conditions are met:
+//
+// 1. Redistributions of source code must retain the above copyright notice,
+//    this list of conditions and the following disclaimer.
+//
+// 2. Redistributions in binary form must reproduce the above copyright notice,
+//    this list of conditions and the following disclaimer in the documentation
+//    and/or other materials provided with the distribution.
+//
+// 3. Neither the name of the copyright holder nor the names of its contributors
+//    may be used to endorse or promote products derived from this software
+//    without specific prior written permission.
+//
+// THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS "AS IS"
+// AND ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT LIMITED TO, THE
+// IMPLIED WARRANTIES OF MERCHANTABILITY AND FITNESS FOR A PARTICULAR PURPOSE
+// ARE DISCLAIMED. IN NO EVENT SHALL THE COPYRIGHT HOLDER OR CONTRIBUTORS BE
+// LIABLE FOR ANY DIRECT, INDIRECT, INCIDENTAL, SPECIAL, EXEMPLARY, OR
+// CONSEQUENTIAL DAMAGES (INCLUDING, BUT NOT LIMITED TO, PROCUREMENT OF
+// SUBSTITUTE GOODS OR SERVICES; LOSS OF USE, DATA, OR PROFITS; OR BUSINESS
+// INTERRUPTION) HOWEVER CAUSED AND ON ANY THEORY OF LIABILITY, WHETHER IN
+// CONTRACT, STRICT LIABILITY, OR TORT (INCLUDING NEGLIGENCE OR OTHERWISE)
+// ARISING IN ANY WAY OUT OF THE USE OF THIS SOFTWARE, EVEN IF ADVISED OF THE
+// POSSIBILITY OF SUCH DAMAGE.
+
+package cmd
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/iggy/govern/pkg/laws"
+	"github.com/rs/zerolog/log"
+	"github.com/spf13/cobra"
+)
+
+// rekeyCmd represents the secret rekey command
+var rekeyCmd = &cobra.Command{
+	Use:   "rekey file...",
+	Short: "re-encrypt secrets to a new set of recipients",
+	Long: `Re-encrypt every secret in the files to a new set of recipients, i.e.
+when a host is added or a key is retired. Files can be secrets on their own,
+or laws files with secrets in them, they're rewritten in place.
+
+i.e. govern secret rekey -R recipients secrets/* laws/*.yaml
+`,
+	Args: cobra.MinimumNArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		identityFile, _ := cmd.Flags().GetString("identity")
+		id, err := laws.LoadIdentity(identityFile)
+		if err != nil {
+			log.Fatal().Err(err).Msg("rekey: failed to load identity")
+		}
+		recipients, err := recipientsFromFlags(cmd)
+		if err != nil {
+			log.Fatal().Err(err).Msg("rekey: bad recipients")
+		}
+		if len(recipients) == 0 {
+			log.Fatal().Msg("rekey: no recipients to encrypt to")
+		}
+
+		failed := false
+		for _, file := range args {
+			fi, err := os.Stat(file)
+			if err != nil {
+				log.Error().Err(err).Str("file", file).Msg("rekey: failed to read file")
+				failed = true
+				continue
+			}
+			text, err := os.ReadFile(file)
+			if err != nil {
+				log.Error().Err(err).Str("file", file).Msg("rekey: failed to read file")
+				failed = true
+				continue
+			}
+			// nothing is written unless every secret in the file rekeyed
+			rekeyed, count, err := laws.RekeySecrets(text, id, recipients)
+			if err != nil {
+				log.Error().Err(err).Str("file", file).Msg("rekey: failed to rekey secrets")
+				failed = true
+				continue
+			}
+			if count == 0 {
+				continue
+			}
+			err = os.WriteFile(file, rekeyed, fi.Mode())
+			if err != nil {
+				log.Error().Err(err).Str("file", file).Msg("rekey: failed to write file")
+				failed = true
+				continue
+			}
+			fmt.Printf("%s: rekeyed %d secrets\n", file, count)
+		}
+		if failed {
+			os.Exit(1)
+		}
+	},
+}
+
+func init() {
+	secretCmd.AddCommand(rekeyCmd)
+
+	rekeyCmd.Flags().StringP("identity", "i", laws.DefaultIdentityFile, "key to decrypt with")
+	addRecipientFlags(rekeyCmd)
+}
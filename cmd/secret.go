@@ -0,0 +1,94 @@
+// Copyright © 2026 Iggy <iggy@theiggy.com>
+// All rights reserved.
+//
+// Redistribution and use in source and binary forms, with or without
+// modification, are permitted provided that the following conditions are met:
+//
+// 1. Redistributions of source code must retain the above copyright notice,
+//    this list of conditions and the following disclaimer.
+//
+// 2. Redistributions in binary form must reproduce the above copyright notice,
+//    this list of conditions and the following disclaimer in the documentation
+//    and/or other materials provided with the distribution.
+//
+// 3. Neither the name of the copyright holder nor the names of its contributors
+//    may be used to endorse or promote products derived from this software
+//    without specific prior written permission.
+//
+// THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS "AS IS"
+// AND ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT LIMITED TO, THE
+// IMPLIED WARRANTIES OF MERCHANTABILITY AND FITNESS FOR A PARTICULAR PURPOSE
+// ARE DISCLAIMED. IN NO EVENT SHALL THE COPYRIGHT HOLDER OR CONTRIBUTORS BE
+// LIABLE FOR ANY DIRECT, INDIRECT, INCIDENTAL, SPECIAL, EXEMPLARY, OR
+// CONSEQUENTIAL DAMAGES (INCLUDING, BUT NOT LIMITED TO, PROCUREMENT OF
+// SUBSTITUTE GOODS OR SERVICES; LOSS OF USE, DATA, OR PROFITS; OR BUSINESS
+// INTERRUPTION) HOWEVER CAUSED AND ON ANY THEORY OF LIABILITY, WHETHER IN
+// CONTRACT, STRICT LIABILITY, OR TORT (INCLUDING NEGLIGENCE OR OTHERWISE)
+// ARISING IN ANY WAY OUT OF THE USE OF THIS SOFTWARE, EVEN IF ADVISED OF THE
+// POSSIBILITY OF SUCH DAMAGE.
+
+package cmd
+
+import (
+	"io"
+	"os"
+
+	"github.com/iggy/govern/pkg/laws"
+	"github.com/spf13/cobra"
+)
+
+// secretCmd represents the secret command
+var secretCmd = &cobra.Command{
+	Use:   "secret",
+	Short: "manage encrypted secrets for laws",
+	Long: `Encrypt values so they can live in the laws tree, and be decrypted with
+the secret template function on the hosts that have the key.
+
+Secrets are encrypted to recipients (govern1... public keys), each host has
+an identity (private key) at /etc/govern/secret.key by default.
+
+This is just the parent command for all the secret commands. It doesn't do
+anything on it's own.
+`,
+	Run: func(cmd *cobra.Command, args []string) {
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(secretCmd)
+}
+
+// addRecipientFlags - flags for the commands that encrypt
+func addRecipientFlags(cmd *cobra.Command) {
+	cmd.Flags().StringSliceP("recipient", "r", nil, "public key to encrypt to, can be repeated")
+	cmd.Flags().StringP("recipients-file", "R", "", "file of public keys to encrypt to, one per line")
+}
+
+// recipientsFromFlags - the recipients from --recipient and --recipients-file
+func recipientsFromFlags(cmd *cobra.Command) ([]*laws.Recipient, error) {
+	var recipients []*laws.Recipient
+	keys, _ := cmd.Flags().GetStringSlice("recipient")
+	for _, k := range keys {
+		r, err := laws.ParseRecipient(k)
+		if err != nil {
+			return nil, err
+		}
+		recipients = append(recipients, r)
+	}
+	if file, _ := cmd.Flags().GetString("recipients-file"); file != "" {
+		more, err := laws.LoadRecipients(file)
+		if err != nil {
+			return nil, err
+		}
+		recipients = append(recipients, more...)
+	}
+	return recipients, nil
+}
+
+// readInput - the file named in args, or stdin
+func readInput(args []string) ([]byte, error) {
+	if len(args) == 0 || args[0] == "-" {
+		return io.ReadAll(os.Stdin)
+	}
+	return os.ReadFile(args[0])
+}
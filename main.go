@@ -34,12 +34,14 @@ import (
 	"os"
 
 	"github.com/iggy/govern/cmd"
+	"github.com/iggy/govern/pkg/laws"
 	"github.com/rs/zerolog"
 	"github.com/rs/zerolog/log"
 )
 
 func main() {
-	log.Logger = log.Output(zerolog.ConsoleWriter{Out: os.Stderr})
+	// decrypted secrets get redacted before anything is logged
+	log.Logger = log.Output(zerolog.ConsoleWriter{Out: laws.RedactWriter(os.Stderr)})
 	zerolog.TimeFieldFormat = zerolog.TimeFormatUnix
 	zerolog.SetGlobalLevel(zerolog.InfoLevel)
 	// zerolog.SetGlobalLevel(zerolog.DebugLevel)
@@ -0,0 +1,301 @@
+// Copyright © 2026 Iggy <iggy@theiggy.com>
+// All rights reserved.
+//
+// Redistribution and use in source and binary forms, with or without
+// modification, are permitted provided that the following conditions are met:
+//
+// 1. Redistributions of source code must retain the above copyright notice,
+//    this list of conditions and the following disclaimer.
+//
+// 2. Redistributions in binary form must reproduce the above copyright notice,
+//    this list of conditions and the following disclaimer in the documentation
+//    and/or other materials provided with the distribution.
+//
+// 3. Neither the name of the copyright holder nor the names of its contributors
+//    may be used to endorse or promote products derived from this software
+//    without specific prior written permission.
+//
+// THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS "AS IS"
+// AND ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT LIMITED TO, THE
+// IMPLIED WARRANTIES OF MERCHANTABILITY AND FITNESS FOR A PARTICULAR PURPOSE
+// ARE DISCLAIMED. IN NO EVENT SHALL THE COPYRIGHT HOLDER OR CONTRIBUTORS BE
+// LIABLE FOR ANY DIRECT, INDIRECT, INCIDENTAL, SPECIAL, EXEMPLARY, OR
+// CONSEQUENTIAL DAMAGES (INCLUDING, BUT NOT LIMITED TO, PROCUREMENT OF
+// SUBSTITUTE GOODS OR SERVICES; LOSS OF USE, DATA, OR PROFITS; OR BUSINESS
+// INTERRUPTION) HOWEVER CAUSED AND ON ANY THEORY OF LIABILITY, WHETHER IN
+// CONTRACT, STRICT LIABILITY, OR TORT (INCLUDING NEGLIGENCE OR OTHERWISE)
+// ARISING IN ANY WAY OUT OF THE USE OF THIS SOFTWARE, EVEN IF ADVISED OF THE
+// POSSIBILITY OF SUCH DAMAGE.
+
+package laws
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"path/filepath"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/fsnotify/fsnotify"
+	"github.com/hmdsefi/gograph"
+	"github.com/rs/zerolog/log"
+)
+
+// DefaultRemediateTag - drifted laws with this tag are applied by the agent
+// when it's remediating
+const DefaultRemediateTag = "remediate"
+
+// DefaultAgentInterval - how often the agent checks the laws
+const DefaultAgentInterval = 5 * time.Minute
+
+// watchDelay - how long to wait for a burst of file events to settle
+const watchDelay = 2 * time.Second
+
+// Agent - keeps checking the laws against the system, reporting drift and
+// fixing the laws tagged for it if Remediate is set
+type Agent struct {
+	Path         string // laws file or directory, re-parsed every check
+	Interval     time.Duration
+	Workers      int
+	Selector     Selector
+	Remediate    bool
+	RemediateTag string
+
+	mu      sync.Mutex
+	status  AgentStatus
+	watched []string // files the laws manage, from the last parse
+}
+
+// AgentStatus - what the agent found on its last check
+type AgentStatus struct {
+	Checked    time.Time     `json:"checked"`
+	Duration   time.Duration `json:"duration"`
+	Checks     int           `json:"checks"`  // since the agent started
+	Trigger    string        `json:"trigger"` // what started the check, interval or a file
+	InSync     bool          `json:"in_sync"`
+	Drifted    []*Result     `json:"drifted,omitempty"`    // laws that would change, after remediating
+	Remediated []*Result     `json:"remediated,omitempty"` // laws the agent applied
+	Failed     []*Result     `json:"failed,omitempty"`
+	Error      string        `json:"error,omitempty"` // the laws couldn't be parsed
+}
+
+// Status - the last check
+func (a *Agent) Status() AgentStatus {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	return a.status
+}
+
+// ServeHTTP - the last check as json, 503 if the system has drifted or the
+// check failed so it's easy to point a health check at
+func (a *Agent) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	status := a.Status()
+	w.Header().Set("Content-Type", "application/json")
+	if status.Checks > 0 && !status.InSync {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	_ = enc.Encode(status)
+}
+
+// Check - parse the laws, pretend them and remediate whatever drifted that's
+// tagged for it
+func (a *Agent) Check(ctx context.Context, trigger string) AgentStatus {
+	started := time.Now()
+	status := AgentStatus{Checked: started, Trigger: trigger}
+	defer func() {
+		status.Duration = time.Since(started)
+		status.InSync = status.Error == "" && len(status.Drifted) == 0 && len(status.Failed) == 0
+		a.mu.Lock()
+		status.Checks = a.status.Checks + 1
+		a.status = status
+		a.mu.Unlock()
+	}()
+
+	sorted, err := ParseFiles(a.Path)
+	if err == nil {
+		sorted, err = a.Selector.Filter(sorted)
+	}
+	if err != nil {
+		log.Error().Err(err).Str("laws", a.Path).Msg("agent: failed to parse laws")
+		status.Error = Redact(err.Error())
+		return status
+	}
+	var watched []string
+	byID := map[string]*LawNode{}
+	for _, v := range sorted {
+		byID[v.Label().ID()] = v.Label()
+		switch l := v.Label().Law.(type) {
+		case *FileTemplate:
+			watched = append(watched, l.Name)
+		case *FileChange:
+			watched = append(watched, l.Name)
+		}
+	}
+	a.mu.Lock()
+	a.watched = watched
+	a.mu.Unlock()
+
+	results := NewExecutor(a.Workers, true).Run(ctx, sorted)
+	report := NewReport(results, true, started)
+	var remediate []string
+	for _, r := range report.Results {
+		switch r.Status {
+		case StatusChanged:
+			status.Drifted = append(status.Drifted, r)
+			if a.Remediate && tagged(byID[r.ID], []string{a.RemediateTag}) {
+				remediate = append(remediate, r.ID)
+			}
+		case StatusFailed:
+			status.Failed = append(status.Failed, r)
+		}
+	}
+	for _, r := range status.Drifted {
+		log.Warn().Str("law", r.ID).Str("before", r.Before).Str("after", r.After).Msg("drifted")
+	}
+	if len(remediate) > 0 {
+		report := a.remediate(ctx, sorted, remediate)
+		for _, r := range report.Results {
+			switch {
+			case r.Status == StatusFailed:
+				status.Failed = append(status.Failed, r)
+			case slices.Contains(remediate, r.ID):
+				status.Remediated = append(status.Remediated, r)
+			}
+		}
+		status.Drifted = slices.DeleteFunc(status.Drifted, func(r *Result) bool {
+			return slices.ContainsFunc(status.Remediated, func(f *Result) bool { return f.ID == r.ID })
+		})
+	}
+	log.Info().
+		Str("trigger", trigger).
+		Int("drifted", len(status.Drifted)).
+		Int("remediated", len(status.Remediated)).
+		Int("failed", len(status.Failed)).
+		Msg("agent: checked laws")
+	return status
+}
+
+// remediate - apply just the drifted laws tagged for it, not what they come
+// after, the run is journaled like any other apply. sorted was just
+// pretended, which is fine as watch triggers don't outlast a run.
+func (a *Agent) remediate(ctx context.Context, sorted []*gograph.Vertex[*LawNode], ids []string) *Report {
+	started := time.Now()
+	sorted, err := Selector{Only: ids, NoDeps: true}.Filter(sorted)
+	if err != nil {
+		// the ids came from the laws, so they always match
+		log.Error().Err(err).Msg("agent: failed to select laws to remediate")
+		return &Report{}
+	}
+	sorted = slices.DeleteFunc(sorted, func(v *gograph.Vertex[*LawNode]) bool {
+		return !tagged(v.Label(), []string{a.RemediateTag})
+	})
+	log.Info().Strs("laws", ids).Msg("agent: remediating")
+	report := NewReport(NewExecutor(a.Workers, false).Run(ctx, sorted), false, started)
+
+	lawsPath := a.Path
+	if abs, err := filepath.Abs(lawsPath); err == nil && lawsPath != Stdin {
+		lawsPath = abs
+	}
+	run := &JournalRun{ID: NewRunID(), Laws: lawsPath, Report: *report}
+	err = WriteJournal(run)
+	if err != nil {
+		log.Error().Err(err).Str("dir", JournalDir).Msg("failed to write journal")
+	} else {
+		log.Info().Str("run", run.ID).Msg("run journaled")
+	}
+	return report
+}
+
+// Run - check the laws every Interval, and soon after any of the files
+// the laws template or change are touched, until the context is done
+func (a *Agent) Run(ctx context.Context) error {
+	watcher, err := fsnotify.NewWatcher()
+	if err != nil {
+		return err
+	}
+	defer watcher.Close()
+
+	ticker := time.NewTicker(a.Interval)
+	defer ticker.Stop()
+	settle := time.NewTimer(0)
+	<-settle.C
+	var touched string
+	var quiet time.Time // ignore file events until then
+
+	a.Check(ctx, "start")
+	dirs := a.watch(watcher, nil)
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+			a.Check(ctx, "interval")
+		case ev, ok := <-watcher.Events:
+			if !ok {
+				return nil
+			}
+			if time.Now().Before(quiet) || !slices.Contains(a.watchedFiles(), filepath.Clean(ev.Name)) {
+				continue
+			}
+			log.Debug().Str("file", ev.Name).Str("op", ev.Op.String()).Msg("agent: managed file touched")
+			touched = ev.Name
+			settle.Reset(watchDelay)
+			continue
+		case err, ok := <-watcher.Errors:
+			if !ok {
+				return nil
+			}
+			log.Error().Err(err).Msg("agent: file watcher failed")
+			continue
+		case <-settle.C:
+			a.Check(ctx, touched)
+		}
+		// remediating writes the files we're watching, those events aren't
+		// drift
+		quiet = time.Now().Add(watchDelay)
+		dirs = a.watch(watcher, dirs)
+	}
+}
+
+// watchedFiles - the files from the last parse
+func (a *Agent) watchedFiles() []string {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	return a.watched
+}
+
+// watch - watch the directories of the managed files, rather than the files
+// themselves, so files that are replaced or created are seen too. Returns
+// the directories now being watched.
+func (a *Agent) watch(watcher *fsnotify.Watcher, dirs []string) []string {
+	var want []string
+	for i, f := range a.watchedFiles() {
+		f = filepath.Clean(f)
+		a.mu.Lock()
+		a.watched[i] = f
+		a.mu.Unlock()
+		if dir := filepath.Dir(f); !slices.Contains(want, dir) {
+			want = append(want, dir)
+		}
+	}
+	for _, dir := range dirs {
+		if !slices.Contains(want, dir) {
+			_ = watcher.Remove(dir)
+		}
+	}
+	var watching []string
+	for _, dir := range want {
+		if !slices.Contains(dirs, dir) {
+			if err := watcher.Add(dir); err != nil {
+				log.Warn().Err(err).Str("dir", dir).Msg("agent: can't watch directory")
+				continue
+			}
+		}
+		watching = append(watching, dir)
+	}
+	return watching
+}
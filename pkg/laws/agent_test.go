@@ -0,0 +1,71 @@
+// Copyright © 2026 Iggy <iggy@theiggy.com>
+// All rights reserved.
+//
+// Redistribution and use in source and binary forms, with or without
+// modification, are permitted provided that the following conditions are met:
+//
+// 1. Redistributions of source code must retain the above copyright notice,
+//    this list of conditions and the following disclaimer.
+//
+// 2. Redistributions in binary form must reproduce the above copyright notice,
+//    this list of conditions and the following disclaimer in the documentation
+//    and/or other materials provided with the distribution.
+//
+// 3. Neither the name of the copyright holder nor the names of its contributors
+//    may be used to endorse or promote products derived from this software
+//    without specific prior written permission.
+//
+// THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS "AS IS"
+// AND ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT LIMITED TO, THE
+// IMPLIED WARRANTIES OF MERCHANTABILITY AND FITNESS FOR A PARTICULAR PURPOSE
+// ARE DISCLAIMED. IN NO EVENT SHALL THE COPYRIGHT HOLDER OR CONTRIBUTORS BE
+// LIABLE FOR ANY DIRECT, INDIRECT, INCIDENTAL, SPECIAL, EXEMPLARY, OR
+// CONSEQUENTIAL DAMAGES (INCLUDING, BUT NOT LIMITED TO, PROCUREMENT OF
+// SUBSTITUTE GOODS OR SERVICES; LOSS OF USE, DATA, OR PROFITS; OR BUSINESS
+// INTERRUPTION) HOWEVER CAUSED AND ON ANY THEORY OF LIABILITY, WHETHER IN
+// CONTRACT, STRICT LIABILITY, OR TORT (INCLUDING NEGLIGENCE OR OTHERWISE)
+// ARISING IN ANY WAY OUT OF THE USE OF THIS SOFTWARE, EVEN IF ADVISED OF THE
+// POSSIBILITY OF SUCH DAMAGE.
+
+package laws
+
+import (
+	"context"
+	"testing"
+)
+
+func TestAgentRemediateIgnoresPretendTriggers(t *testing.T) {
+	journalDir := JournalDir
+	JournalDir = t.TempDir()
+	t.Cleanup(func() { JournalDir = journalDir })
+
+	// the file drifted but isn't tagged for remediation, so the service
+	// watching it would restart when pretending but mustn't when remediated
+	file := fakeNode("file", &fakeLaw{ensure: func(context.Context, bool) *Result {
+		return Changed("drifted", "", "")
+	}})
+	service := &fakeReactor{fakeLaw: fakeLaw{ensure: func(context.Context, bool) *Result {
+		return Unchanged("running")
+	}}}
+	serviceNode := fakeNode("service", service)
+	serviceNode.Watches = []string{file.ID()}
+	serviceNode.Options = &Options{Tags: []string{DefaultRemediateTag}}
+	sorted := testGraph(t, []*LawNode{file, serviceNode}, [2]string{file.ID(), serviceNode.ID()})
+
+	a := &Agent{Path: "laws.yaml", Workers: 1, Remediate: true, RemediateTag: DefaultRemediateTag}
+	checked := resultsByID(NewExecutor(a.Workers, true).Run(context.Background(), sorted))
+	if r := checked[serviceNode.ID()]; r.Status != StatusChanged {
+		t.Fatalf("check: service %s %s, want it to drift", r.Status, r.Message)
+	}
+	report := a.remediate(context.Background(), sorted, []string{file.ID(), serviceNode.ID()})
+	remediated := resultsByID(report.Results)
+	if _, ok := remediated[file.ID()]; ok {
+		t.Error("remediated the file, which isn't tagged for it")
+	}
+	if r := remediated[serviceNode.ID()]; r == nil || r.Status != StatusUnchanged {
+		t.Errorf("remediate: service %+v, want it unchanged", r)
+	}
+	if len(service.reacted) != 1 || !service.reacted[0] {
+		t.Errorf("service reacted %v, want only when pretending", service.reacted)
+	}
+}
@@ -0,0 +1,310 @@
+// Copyright © 2026 Iggy <iggy@theiggy.com>
+// All rights reserved.
+//
+// Redistribution and use in source and binary forms, with or without
+// modification, are permitted provided that the following conditions are met:
+//
+// 1. Redistributions of source code must retain the above copyright notice,
+//    this list of conditions and the following disclaimer.
+//
+// 2. Redistributions in binary form must reproduce the above copyright notice,
+//    this list of conditions and the following disclaimer in the documentation
+//    and/or other materials provided with the distribution.
+//
+// 3. Neither the name of the copyright holder nor the names of its contributors
+//    may be used to endorse or promote products derived from this software
+//    without specific prior written permission.
+//
+// THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS "AS IS"
+// AND ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT LIMITED TO, THE
+// IMPLIED WARRANTIES OF MERCHANTABILITY AND FITNESS FOR A PARTICULAR PURPOSE
+// ARE DISCLAIMED. IN NO EVENT SHALL THE COPYRIGHT HOLDER OR CONTRIBUTORS BE
+// LIABLE FOR ANY DIRECT, INDIRECT, INCIDENTAL, SPECIAL, EXEMPLARY, OR
+// CONSEQUENTIAL DAMAGES (INCLUDING, BUT NOT LIMITED TO, PROCUREMENT OF
+// SUBSTITUTE GOODS OR SERVICES; LOSS OF USE, DATA, OR PROFITS; OR BUSINESS
+// INTERRUPTION) HOWEVER CAUSED AND ON ANY THEORY OF LIABILITY, WHETHER IN
+// CONTRACT, STRICT LIABILITY, OR TORT (INCLUDING NEGLIGENCE OR OTHERWISE)
+// ARISING IN ANY WAY OUT OF THE USE OF THIS SOFTWARE, EVEN IF ADVISED OF THE
+// POSSIBILITY OF SUCH DAMAGE.
+
+package laws
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+	"syscall"
+
+	"github.com/rs/zerolog/log"
+)
+
+// absent - the hash recorded for a file that doesn't exist
+const absent = "absent"
+
+// backupPath - where the old content of changed files is kept, named by
+// hash so the same content is only stored once however many runs see it
+func backupPath() string {
+	return filepath.Join(JournalDir, "backups")
+}
+
+// backupFile - the path of the backup with a hash
+func backupFile(hash string) string {
+	return filepath.Join(backupPath(), strings.TrimPrefix(hash, "sha256:"))
+}
+
+// fileSnapshot - a managed file as it was before a law was ensured
+type fileSnapshot struct {
+	path    string
+	hash    string
+	content []byte
+	mode    fs.FileMode
+	owner   string // uid:gid
+}
+
+// snapshotFiles - read the files a law manages before it's ensured
+func snapshotFiles(files []string) []fileSnapshot {
+	snaps := make([]fileSnapshot, 0, len(files))
+	for _, f := range files {
+		s := fileSnapshot{path: f, hash: absent}
+		content, err := os.ReadFile(f)
+		if err == nil {
+			s.content = content
+			s.hash = hashContent(content)
+			s.mode, s.owner = fileAttrs(f)
+		}
+		snaps = append(snaps, s)
+	}
+	return snaps
+}
+
+// fileAttrs - the permissions and owner of a file
+func fileAttrs(path string) (fs.FileMode, string) {
+	fi, err := os.Stat(path)
+	if err != nil {
+		return 0, ""
+	}
+	var owner string
+	if st, ok := fi.Sys().(*syscall.Stat_t); ok {
+		owner = fmt.Sprintf("%d:%d", st.Uid, st.Gid)
+	}
+	return fi.Mode().Perm(), owner
+}
+
+// record - what goes in the journal for the file
+func (s fileSnapshot) record(after string) FileHash {
+	fh := FileHash{Path: s.path, Before: s.hash, After: after, Owner: s.owner}
+	if s.mode != 0 {
+		fh.Mode = fmt.Sprintf("%04o", s.mode)
+	}
+	return fh
+}
+
+// backup - keep the content the file had before the law changed it, and
+// the .bak copy next to it if the law asked for one
+func (s fileSnapshot) backup(bak bool) {
+	if s.hash == absent {
+		return
+	}
+	err := storeBackup(s.hash, s.content)
+	if err != nil {
+		log.Error().Err(err).Str("file", s.path).Msg("failed to backup file")
+	}
+	if !bak {
+		return
+	}
+	err = os.WriteFile(s.path+".bak", s.content, s.mode)
+	if err != nil {
+		log.Error().Err(err).Str("file", s.path).Msg("failed to write .bak file")
+	}
+}
+
+// storeBackup - add some content to the backups, if it isn't already there
+func storeBackup(hash string, content []byte) error {
+	path := backupFile(hash)
+	if _, err := os.Stat(path); err == nil {
+		return nil
+	}
+	err := os.MkdirAll(backupPath(), 0o700)
+	if err != nil {
+		return err
+	}
+	tmp, err := os.CreateTemp(backupPath(), ".backup-*")
+	if err != nil {
+		return err
+	}
+	_, err = tmp.Write(content)
+	if cerr := tmp.Close(); err == nil {
+		err = cerr
+	}
+	if err != nil {
+		os.Remove(tmp.Name())
+		return err
+	}
+	return os.Rename(tmp.Name(), path)
+}
+
+// readBackup - the content with a hash, checked against it
+func readBackup(hash string) ([]byte, error) {
+	content, err := os.ReadFile(backupFile(hash))
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, fmt.Errorf("no backup of %s", hash)
+	}
+	if err != nil {
+		return nil, err
+	}
+	if hashContent(content) != hash {
+		return nil, fmt.Errorf("backup of %s is corrupt", hash)
+	}
+	return content, nil
+}
+
+// backupper is implemented by laws with a backup option, they keep a .bak
+// copy of the file next to it as well
+type backupper interface {
+	keepBackup() bool
+}
+
+// Rollback actions
+const (
+	RollbackRestore = "restore"
+	RollbackRemove  = "remove"
+	RollbackSkip    = "skip"
+)
+
+// RollbackAction - what rolling back a run did, or would do, to a file
+type RollbackAction struct {
+	Path   string `json:"path"`
+	Action string `json:"action"`
+	Hash   string `json:"hash,omitempty"`   // the content the file goes back to
+	Reason string `json:"reason,omitempty"` // why it was skipped
+	Err    error  `json:"-"`
+}
+
+// Rollback - put the files a run changed back how they were before the
+// run. Files that have changed since the run are skipped unless forced.
+func Rollback(run *JournalRun, pretend, force bool) []RollbackAction {
+	var paths []string
+	changes := map[string][]FileHash{}
+	for _, r := range run.Results {
+		for _, f := range r.Files {
+			if f.Before == f.After {
+				continue
+			}
+			if _, ok := changes[f.Path]; !ok {
+				paths = append(paths, f.Path)
+			}
+			changes[f.Path] = append(changes[f.Path], f)
+		}
+	}
+
+	actions := make([]RollbackAction, 0, len(paths))
+	for _, path := range paths {
+		a := rollbackFile(path, changes[path], force)
+		if !pretend && a.Action != RollbackSkip {
+			a.Err = a.apply(changes[path])
+		}
+		actions = append(actions, a)
+	}
+	return actions
+}
+
+// rollbackFile - work out what rolling back a file means. Several laws can
+// change the same file in a run, so follow the changes back from what's
+// there now to the content from before the first one.
+func rollbackFile(path string, changes []FileHash, force bool) RollbackAction {
+	current := hashFiles([]string{path})[0]
+	byAfter := map[string]FileHash{}
+	for _, c := range changes {
+		byAfter[c.After] = c
+	}
+	c, ok := byAfter[current]
+	if !ok {
+		for _, c := range changes {
+			if _, ok := byAfter[c.Before]; !ok && c.Before == current {
+				return RollbackAction{Path: path, Action: RollbackSkip, Reason: "already restored"}
+			}
+		}
+		if !force {
+			return RollbackAction{Path: path, Action: RollbackSkip, Reason: "changed since the run"}
+		}
+		c = changes[len(changes)-1]
+	}
+	seen := map[string]bool{c.After: true}
+	for {
+		prev, ok := byAfter[c.Before]
+		if !ok || seen[prev.After] {
+			break
+		}
+		seen[prev.After] = true
+		c = prev
+	}
+
+	switch {
+	case c.Before == current:
+		return RollbackAction{Path: path, Action: RollbackSkip, Reason: "already restored"}
+	case c.Before == absent:
+		return RollbackAction{Path: path, Action: RollbackRemove}
+	}
+	return RollbackAction{Path: path, Action: RollbackRestore, Hash: c.Before}
+}
+
+// apply - restore or remove the file
+func (a RollbackAction) apply(changes []FileHash) error {
+	if a.Action == RollbackRemove {
+		err := os.Remove(a.Path)
+		if errors.Is(err, os.ErrNotExist) {
+			return nil
+		}
+		return err
+	}
+	content, err := readBackup(a.Hash)
+	if err != nil {
+		return err
+	}
+	var fh FileHash
+	for _, c := range changes {
+		if c.Before == a.Hash {
+			fh = c
+			break
+		}
+	}
+	mode := fs.FileMode(0o644)
+	if fh.Mode != "" {
+		_, err = fmt.Sscanf(fh.Mode, "%o", &mode)
+		if err != nil {
+			return fmt.Errorf("bad mode %s: %w", fh.Mode, err)
+		}
+	}
+
+	err = os.MkdirAll(filepath.Dir(a.Path), 0o755)
+	if err != nil {
+		return err
+	}
+	// write then rename, a half restored file is worse than none
+	tmp, err := os.CreateTemp(filepath.Dir(a.Path), "."+filepath.Base(a.Path)+"-*")
+	if err != nil {
+		return err
+	}
+	_, err = tmp.Write(content)
+	if cerr := tmp.Close(); err == nil {
+		err = cerr
+	}
+	if err == nil {
+		err = os.Chmod(tmp.Name(), mode)
+	}
+	if err == nil && fh.Owner != "" {
+		var uid, gid int
+		_, err = fmt.Sscanf(fh.Owner, "%d:%d", &uid, &gid)
+		if err == nil {
+			err = os.Chown(tmp.Name(), uid, gid)
+		}
+	}
+	if err != nil {
+		os.Remove(tmp.Name())
+		return err
+	}
+	return os.Rename(tmp.Name(), a.Path)
+}
@@ -0,0 +1,125 @@
+// Copyright © 2026 Iggy <iggy@theiggy.com>
+// All rights reserved.
+//
+// Redistribution and use in source and binary forms, with or without
+// modification, are permitted provided that the following conditions are met:
+//
+// 1. Redistributions of source code must retain the above copyright notice,
+//    this list of conditions and the following disclaimer.
+//
+// 2. Redistributions in binary form must reproduce the above copyright notice,
+//    this list of conditions and the following disclaimer in the documentation
+//    and/or other materials provided with the distribution.
+//
+// 3. Neither the name of the copyright holder nor the names of its contributors
+//    may be used to endorse or promote products derived from this software
+//    without specific prior written permission.
+//
+// THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS "AS IS"
+// AND ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT LIMITED TO, THE
+// IMPLIED WARRANTIES OF MERCHANTABILITY AND FITNESS FOR A PARTICULAR PURPOSE
+// ARE DISCLAIMED. IN NO EVENT SHALL THE COPYRIGHT HOLDER OR CONTRIBUTORS BE
+// LIABLE FOR ANY DIRECT, INDIRECT, INCIDENTAL, SPECIAL, EXEMPLARY, OR
+// CONSEQUENTIAL DAMAGES (INCLUDING, BUT NOT LIMITED TO, PROCUREMENT OF
+// SUBSTITUTE GOODS OR SERVICES; LOSS OF USE, DATA, OR PROFITS; OR BUSINESS
+// INTERRUPTION) HOWEVER CAUSED AND ON ANY THEORY OF LIABILITY, WHETHER IN
+// CONTRACT, STRICT LIABILITY, OR TORT (INCLUDING NEGLIGENCE OR OTHERWISE)
+// ARISING IN ANY WAY OUT OF THE USE OF THIS SOFTWARE, EVEN IF ADVISED OF THE
+// POSSIBILITY OF SUCH DAMAGE.
+
+package laws
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"gopkg.in/yaml.v3"
+)
+
+func TestRollback(t *testing.T) {
+	journalDir := JournalDir
+	JournalDir = t.TempDir()
+	t.Cleanup(func() { JournalDir = journalDir })
+
+	path := filepath.Join(t.TempDir(), "motd")
+	if err := os.WriteFile(path, []byte("a\nb\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	owner := ""
+	if os.Getuid() == 0 {
+		// root can give the file away, so the laws have an owner to undo too
+		if err := os.Chown(path, 65534, 65534); err != nil {
+			t.Fatal(err)
+		}
+		owner = "\nuser: \"0\"\ngroup: \"0\""
+	}
+	wantMode, wantOwner := fileAttrs(path)
+
+	change, insert := &FileChange{}, &FileInsert{}
+	err := yaml.Unmarshal([]byte("search: ^a$\nreplace: one\nmode: 0644"+owner), change)
+	if err != nil {
+		t.Fatal(err)
+	}
+	change.Name = path
+	err = yaml.Unmarshal([]byte("after_line: one\ntext: two"), insert)
+	if err != nil {
+		t.Fatal(err)
+	}
+	insert.Name = path
+	nodes := []*LawNode{
+		{Law: change, Group: "files", Type: "changes", Name: path},
+		{Law: insert, Group: "files", Type: "inserts", Name: path},
+	}
+	apply := func() *JournalRun {
+		run := &JournalRun{ID: NewRunID()}
+		for _, n := range nodes {
+			r := n.Ensure(context.Background(), false)
+			if r.Status == StatusFailed {
+				t.Fatalf("%s: %s: %v", r.ID, r.Message, r.Err)
+			}
+			run.Results = append(run.Results, r)
+		}
+		return run
+	}
+
+	first := apply()
+	second := apply()
+	content, _ := os.ReadFile(path)
+	if string(content) != "one\ntwo\nb\n" {
+		t.Fatalf("applied content = %q", content)
+	}
+	if mode, owner := fileAttrs(path); mode != 0o644 || (os.Getuid() == 0 && owner != "0:0") {
+		t.Fatalf("applied mode and owner = %04o %s", mode, owner)
+	}
+	for _, r := range second.Results {
+		if r.Status != StatusUnchanged {
+			t.Errorf("second apply: %s %s", r.ID, r.Status)
+		}
+	}
+	if actions := Rollback(second, false, false); len(actions) != 0 {
+		t.Errorf("rolling back a run that changed nothing: %+v", actions)
+	}
+
+	actions := Rollback(first, false, false)
+	if len(actions) != 1 || actions[0].Action != RollbackRestore || actions[0].Err != nil {
+		t.Fatalf("rollback = %+v, want one restore", actions)
+	}
+	content, _ = os.ReadFile(path)
+	if string(content) != "a\nb\n" {
+		t.Errorf("rolled back content = %q, want %q", content, "a\nb\n")
+	}
+	mode, owner := fileAttrs(path)
+	if mode != wantMode {
+		t.Errorf("rolled back mode = %04o, want %04o", mode, wantMode)
+	}
+	if owner != wantOwner {
+		t.Errorf("rolled back owner = %s, want %s", owner, wantOwner)
+	}
+
+	actions = Rollback(first, false, false)
+	if len(actions) != 1 || actions[0].Action != RollbackSkip || actions[0].Reason != "already restored" {
+		t.Errorf("second rollback = %+v, want already restored", actions)
+	}
+}
@@ -30,6 +30,7 @@
 package laws
 
 import (
+	"context"
 	"fmt"
 	"strconv"
 	"strings"
@@ -42,105 +43,86 @@ import (
 
 // HealthCheckOpts - This is a struct for the healthcheck options
 type HealthCheckOpts struct {
-	Enabled     bool
-	Command     string
-	Interval    string
-	Retries     int
-	StartPeriod string
-	Timeout     string
+	Enabled     bool   `yaml:"enabled"`
+	Command     string `yaml:"command"`
+	Interval    string `yaml:"interval"`
+	Retries     int    `yaml:"retries"`
+	StartPeriod string `yaml:"start_period"`
+	Timeout     string `yaml:"timeout"`
 }
 
 // LogOpts - This is a struct for the log options
 type LogOpts struct {
-	Driver string // none|json-file|syslog|journald|gelf|fluentd|awslogs|splunk
-	Opt    map[string]string
+	Driver string            `yaml:"driver"` // none|json-file|syslog|journald|gelf|fluentd|awslogs|splunk
+	Opt    map[string]string `yaml:"opt"`
 }
 
 // Container -  This is a struct for the container
 type Container struct {
-	// Name          string
-	Image         string
-	Running       bool
-	Volumes       map[string]string
-	Environment   map[string]string
-	Labels        map[string]string
-	LogDriver     string
-	Hostname      string
-	Network       string // bridge|none|container:<name|id>|host|<network-name|network-id>
-	HealthCheck   HealthCheckOpts
-	Privileged    bool
-	PublishAll    bool
-	Publish       map[string]string
-	RestartPolicy string // no|on-failure[:max-retries]|always|unless-stopped
+	CommonFields `yaml:"-"`
 
-	// CommonFields
-	Name   string
-	Before []string
-	After  []string
+	Image         string            `yaml:"image"`
+	Running       bool              `yaml:"running"`
+	Volumes       map[string]string `yaml:"volumes"`
+	Environment   map[string]string `yaml:"environment"`
+	Labels        map[string]string `yaml:"labels"`
+	LogDriver     string            `yaml:"log_driver"`
+	Hostname      string            `yaml:"hostname"`
+	Network       string            `yaml:"network"` // bridge|none|container:<name|id>|host|<network-name|network-id>
+	HealthCheck   HealthCheckOpts   `yaml:"health_check"`
+	Privileged    bool              `yaml:"privileged"`
+	PublishAll    bool              `yaml:"publish_all"`
+	Publish       map[string]string `yaml:"publish"`
+	RestartPolicy string            `yaml:"restart_policy" pattern:"^(no|always|unless-stopped|on-failure(:[0-9]+)?)$"` // no|on-failure[:max-retries]|always|unless-stopped
 }
 
 // UnmarshalYAML - This fills in default values if they aren't specified
 func (c *Container) UnmarshalYAML(value *yaml.Node) error {
-	// set default values
+	// set default values, labels from the yaml get added to ours
 	c.Running = true
 	c.Labels = map[string]string{"StartedBy": "Govern"}
 	c.Privileged = false
-	var err error // for use in the switch below
 
 	log.Trace().Interface("Node", value).Interface("node type", value.Content).Msg("Container UnmarshalYAML")
-	if value.Tag != "!!map" {
-		return fmt.Errorf("unable to unmarshal yaml: value not map (%s)", value.Tag)
-	}
 
-	for i, node := range value.Content {
-		log.Trace().Interface("node1", node).Msg("")
-		switch node.Value {
-		case "name":
-			log.Trace().Str("key", value.Content[i].Value).Str("value", value.Content[i+1].Value).Msg("name yaml tag")
-			c.Name = value.Content[i+1].Value
-			if c.Name == "" {
-				return nil
-			}
-		case "image":
-			c.Image = value.Content[i+1].Value
-		case "running":
-			c.Running, err = strconv.ParseBool(value.Content[i+1].Value)
-			if err != nil {
-				log.Error().Err(err).Msg("can't parse running field")
-				return err
-			}
-		case "labels":
-			// for l := range node.
-			log.Trace().Interface("node", node).Msg("labels")
+	type rawContainer Container
+	if err := value.Decode((*rawContainer)(c)); err != nil {
+		return err
+	}
+	if err := checkRestartPolicy(c.RestartPolicy); err != nil {
+		return fmt.Errorf("line %d: %w", value.Line, err)
+	}
+	return nil
+}
 
-		case "before":
-			for _, j := range value.Content[i+1].Content {
-				c.Before = append(c.Before, j.Value)
-			}
-		case "after":
-			for _, j := range value.Content[i+1].Content {
-				c.After = append(c.After, j.Value)
-			}
+// checkRestartPolicy - validate a docker restart policy, on-failure can carry a max retry count
+func checkRestartPolicy(policy string) error {
+	switch policy {
+	case "", "no", "always", "unless-stopped", "on-failure":
+		return nil
+	}
+	if retries, ok := strings.CutPrefix(policy, "on-failure:"); ok {
+		if n, err := strconv.Atoi(retries); err == nil && n >= 0 {
+			return nil
 		}
+		return fmt.Errorf("restart_policy %q: max retries must be a non-negative integer", policy)
 	}
-
-	return nil
+	return fmt.Errorf("restart_policy %q: must be no, on-failure[:max-retries], always or unless-stopped", policy)
 }
 
 // IsRunning -  This checks if the container is running
-func (c *Container) IsRunning() (bool, error) {
+func (c *Container) IsRunning(ctx context.Context) (bool, error) {
 	log.Trace().Interface("c", c).Msg("Container.Running()")
 
-	// ctx := context.Background()
-
 	client, err := docker.NewClientFromEnv()
 	if err != nil {
-		log.Fatal().Err(err).Msg("failed to create docker client")
+		log.Error().Err(err).Msg("failed to create docker client")
+		return false, err
 	}
 
 	lOpts := docker.ListContainersOptions{
 		// Limit:   1,
-		// Context: ctx,
+		Context: ctx,
 		Filters: map[string][]string{
 			"name":   {c.Name},
 			"status": {"running"},
@@ -149,6 +131,7 @@ func (c *Container) IsRunning() (bool, error) {
 	l, err := client.ListContainers(lOpts)
 	if err != nil {
 		log.Warn().Err(err).Msg("failed to get container list")
+		return false, err
 	}
 	for _, cList := range l {
 		log.Trace().
@@ -166,34 +149,42 @@ func (c *Container) IsRunning() (bool, error) {
 }
 
 // Ensure - run the container if it isn't running
-func (c *Container) Ensure(pretend bool) error {
+func (c *Container) Ensure(ctx context.Context, pretend bool) *Result {
 	client, err := docker.NewClientFromEnv()
 	if err != nil {
 		log.Error().Err(err).Msg("failed to create client connection")
+		return Failed("failed to create docker client", err)
+	}
+	running, rErr := c.IsRunning(ctx)
+	if rErr != nil {
+		return Failed("failed to check if container is running", rErr)
 	}
-	running, rErr := c.IsRunning()
 	if !running && c.Running {
 		log.Debug().Err(rErr).Msgf("container not running: %s", c.Name)
 		if pretend {
 			log.Info().Msgf("Container not running, would start: %s", c.Name)
+			return Changed("container would be started", "stopped", "running")
 		} else {
-			if !isImagePulled(*client, c.Image) {
+			if !isImagePulled(ctx, *client, c.Image) {
 				log.Info().Str("image", c.Image).Msg("image doesn't exist, pulling")
 				imageData := strings.Split(c.Image, ":")
 				pullImageOpts := docker.PullImageOptions{
 					Repository: imageData[0],
 					Tag:        imageData[1],
+					Context:    ctx,
 				}
 				err := client.PullImage(pullImageOpts, docker.AuthConfiguration{})
 				if err != nil {
 					log.Error().Err(err).Str("image", c.Image).Msg("failed to pull image")
+					return Failed("failed to pull image", err)
 				}
 			}
 
-			if !isContainerCreated(*client, c.Name) {
+			if !isContainerCreated(ctx, *client, c.Name) {
 				log.Trace().Msg("container doesn't exist, creating")
 				createContainerOpts := docker.CreateContainerOptions{
-					Name: c.Name,
+					Context: ctx,
+					Name:    c.Name,
 					Config: &docker.Config{
 						Labels: c.Labels,
 						Image:  c.Image,
@@ -205,27 +196,33 @@ func (c *Container) Ensure(pretend bool) error {
 				cnt, err := client.CreateContainer(createContainerOpts)
 				if err != nil {
 					log.Error().Err(err).Interface("container", cnt).Msg("failed to create container")
+					return Failed("failed to create container", err)
 				}
 			}
-			running, err := c.IsRunning()
+			running, err := c.IsRunning(ctx)
 			if err != nil {
 				log.Error().Err(err).Msg("failed to see if container running")
 			}
 			if !running {
-				err := client.StartContainer("ffd57aa8a6ea1bf28e9ab00114e7c9dd36f2edeee763a67f18d9f76062cec33d", &docker.HostConfig{})
+				err := client.StartContainerWithContext("ffd57aa8a6ea1bf28e9ab00114e7c9dd36f2edeee763a67f18d9f76062cec33d", &docker.HostConfig{}, ctx)
 				if err != nil {
 					log.Error().Err(err).Msg("failed to start container")
+					return Failed("failed to start container", err)
 				}
 			}
-
+			return Changed("container started", "stopped", "running")
 		}
 	}
-	return nil
+	if running {
+		return Unchanged("container running")
+	}
+	return Unchanged("container not running")
 }
 
-func isImagePulled(dc docker.Client, name string) bool {
+func isImagePulled(ctx context.Context, dc docker.Client, name string) bool {
 	listImagesOpts := docker.ListImagesOptions{
-		Filter: name,
+		Filter:  name,
+		Context: ctx,
 	}
 	imgs, err := dc.ListImages(listImagesOpts)
 	if err != nil {
@@ -247,8 +244,9 @@ func isImagePulled(dc docker.Client, name string) bool {
 	return false
 }
 
-func isContainerCreated(dc docker.Client, name string) bool {
+func isContainerCreated(ctx context.Context, dc docker.Client, name string) bool {
 	listContainersOpts := docker.ListContainersOptions{
+		Context: ctx,
 		Filters: map[string][]string{
 			// 	"name": {name},
 			"status": {"created", "restarting", "paused", "exited", "dead"},
@@ -0,0 +1,121 @@
+// Copyright © 2026 Iggy <iggy@theiggy.com>
+// All rights reserved.
+//
+// Redistribution and use in source and binary forms, with or without
+// modification, are permitted provided that the following conditions are met:
+//
+// 1. Redistributions of source code must retain the above copyright notice,
+//    this list of conditions and the following disclaimer.
+//
+// 2. Redistributions in binary form must reproduce the above copyright notice,
+//    this list of conditions and the following disclaimer in the documentation
+//    and/or other materials provided with the distribution.
+//
+// 3. Neither the name of the copyright holder nor the names of its contributors
+//    may be used to endorse or promote products derived from this software
+//    without specific prior written permission.
+//
+// THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS "AS IS"
+// AND ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT LIMITED TO, THE
+// IMPLIED WARRANTIES OF MERCHANTABILITY AND FITNESS FOR A PARTICULAR PURPOSE
+// ARE DISCLAIMED. IN NO EVENT SHALL THE COPYRIGHT HOLDER OR CONTRIBUTORS BE
+// LIABLE FOR ANY DIRECT, INDIRECT, INCIDENTAL, SPECIAL, EXEMPLARY, OR
+// CONSEQUENTIAL DAMAGES (INCLUDING, BUT NOT LIMITED TO, PROCUREMENT OF
+// SUBSTITUTE GOODS OR SERVICES; LOSS OF USE, DATA, OR PROFITS; OR BUSINESS
+// INTERRUPTION) HOWEVER CAUSED AND ON ANY THEORY OF LIABILITY, WHETHER IN
+// CONTRACT, STRICT LIABILITY, OR TORT (INCLUDING NEGLIGENCE OR OTHERWISE)
+// ARISING IN ANY WAY OUT OF THE USE OF THIS SOFTWARE, EVEN IF ADVISED OF THE
+// POSSIBILITY OF SUCH DAMAGE.
+
+package laws
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// commonYAML - everything a law can have no matter its kind
+type commonYAML struct {
+	CommonFields `yaml:",inline"`
+	Options      `yaml:",inline"`
+}
+
+// commonFieldKeys - the yaml keys in commonYAML
+var commonFieldKeys = yamlKeys(reflect.TypeOf(commonYAML{}))
+
+// decodeLaw - decode a single law of a kind. The common fields and options
+// are split off and decoded here, the law itself only gets its own keys and
+// any key it doesn't have a field for is an error.
+func decodeLaw(kind *Kind, value *yaml.Node) (Law, *Options, error) {
+	if value.Kind != yaml.MappingNode {
+		return nil, nil, fmt.Errorf("line %d: expected a law, got %s", value.Line, value.ShortTag())
+	}
+
+	common := &yaml.Node{Kind: yaml.MappingNode, Tag: "!!map", Line: value.Line, Column: value.Column}
+	own := &yaml.Node{Kind: yaml.MappingNode, Tag: "!!map", Line: value.Line, Column: value.Column}
+	for i := 0; i+1 < len(value.Content); i += 2 {
+		if _, ok := commonFieldKeys[value.Content[i].Value]; ok {
+			common.Content = append(common.Content, value.Content[i], value.Content[i+1])
+		} else {
+			own.Content = append(own.Content, value.Content[i], value.Content[i+1])
+		}
+	}
+
+	var c commonYAML
+	err := common.Decode(&c)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	law := kind.New()
+	keys := yamlKeys(reflect.TypeOf(law))
+	if _, any := keys[""]; !any {
+		for i := 0; i+1 < len(own.Content); i += 2 {
+			if _, ok := keys[own.Content[i].Value]; !ok {
+				return nil, nil, fmt.Errorf("line %d: unknown key %q", own.Content[i].Line, own.Content[i].Value)
+			}
+		}
+	}
+	err = own.Decode(law)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	*kind.common(law) = c.CommonFields
+	return law, &c.Options, nil
+}
+
+// yamlKeys - the keys yaml.v3 will decode into a struct, including the ones
+// from inlined structs. An inlined map takes any key, that's marked with "".
+func yamlKeys(t reflect.Type) map[string]struct{} {
+	keys := map[string]struct{}{}
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	if t.Kind() != reflect.Struct {
+		return map[string]struct{}{"": {}}
+	}
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		tag := f.Tag.Get("yaml")
+		name, opts, _ := strings.Cut(tag, ",")
+		switch {
+		case tag == "-":
+		case strings.Contains(opts, "inline") && f.Type.Kind() == reflect.Map:
+			keys[""] = struct{}{}
+		case strings.Contains(opts, "inline"):
+			for k := range yamlKeys(f.Type) {
+				keys[k] = struct{}{}
+			}
+		case !f.IsExported():
+		case name != "":
+			keys[name] = struct{}{}
+		default:
+			keys[strings.ToLower(f.Name)] = struct{}{}
+		}
+	}
+	return keys
+}
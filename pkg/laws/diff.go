@@ -0,0 +1,177 @@
+// Copyright © 2026 Iggy <iggy@theiggy.com>
+// All rights reserved.
+//
+// Redistribution and use in source and binary forms, with or without
+// modification, are permitted provided that the following conditions are met:
+//
+// 1. Redistributions of source code must retain the above copyright notice,
+//    this list of conditions and the following disclaimer.
+//
+// 2. Redistributions in binary form must reproduce the above copyright notice,
+//    this list of conditions and the following disclaimer in the documentation
+//    and/or other materials provided with the distribution.
+//
+// 3. Neither the name of the copyright holder nor the names of its contributors
+//    may be used to endorse or promote products derived from this software
+//    without specific prior written permission.
+//
+// THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS "AS IS"
+// AND ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT LIMITED TO, THE
+// IMPLIED WARRANTIES OF MERCHANTABILITY AND FITNESS FOR A PARTICULAR PURPOSE
+// ARE DISCLAIMED. IN NO EVENT SHALL THE COPYRIGHT HOLDER OR CONTRIBUTORS BE
+// LIABLE FOR ANY DIRECT, INDIRECT, INCIDENTAL, SPECIAL, EXEMPLARY, OR
+// CONSEQUENTIAL DAMAGES (INCLUDING, BUT NOT LIMITED TO, PROCUREMENT OF
+// SUBSTITUTE GOODS OR SERVICES; LOSS OF USE, DATA, OR PROFITS; OR BUSINESS
+// INTERRUPTION) HOWEVER CAUSED AND ON ANY THEORY OF LIABILITY, WHETHER IN
+// CONTRACT, STRICT LIABILITY, OR TORT (INCLUDING NEGLIGENCE OR OTHERWISE)
+// ARISING IN ANY WAY OUT OF THE USE OF THIS SOFTWARE, EVEN IF ADVISED OF THE
+// POSSIBILITY OF SUCH DAMAGE.
+
+package laws
+
+import (
+	"fmt"
+	"os"
+	"os/user"
+	"strconv"
+	"strings"
+
+	"github.com/pmezard/go-difflib/difflib"
+)
+
+// contentDiff - a unified diff from the file's current content to what the
+// law wants, nil current means the file doesn't exist. Secrets are redacted
+// before diffing, a multi line secret split over the diff's lines wouldn't
+// be caught after.
+func contentDiff(path string, current []byte, want string) string {
+	from := path
+	if current == nil {
+		from = "/dev/null"
+	}
+	diff, err := difflib.GetUnifiedDiffString(difflib.UnifiedDiff{
+		A:        splitLines(Redact(string(current))),
+		B:        splitLines(Redact(want)),
+		FromFile: from,
+		ToFile:   path,
+		Context:  3,
+	})
+	if err != nil {
+		return ""
+	}
+	return diff
+}
+
+// splitLines - the lines of some text for difflib, each ending in a newline
+func splitLines(text string) []string {
+	lines := strings.SplitAfter(text, "\n")
+	if lines[len(lines)-1] == "" {
+		return lines[:len(lines)-1]
+	}
+	lines[len(lines)-1] += "\n"
+	return lines
+}
+
+// attrDiff - how the file's mode and owner differ from the law, a line each
+func (f *fileCommon) attrDiff() string {
+	fi, err := os.Stat(f.Name)
+	if err != nil {
+		if f.Mode != 0 {
+			return fmt.Sprintf("new file mode %04o\n", f.Mode.FileMode().Perm())
+		}
+		return ""
+	}
+	var diff strings.Builder
+	if f.Mode != 0 && fi.Mode().Perm() != f.Mode.FileMode().Perm() {
+		fmt.Fprintf(&diff, "mode %04o -> %04o\n", fi.Mode().Perm(), f.Mode.FileMode().Perm())
+	}
+	if !f.ownerMatches() {
+		_, owner := fileAttrs(f.Name)
+		uid, gid, _ := strings.Cut(owner, ":")
+		fmt.Fprintf(&diff, "owner %s:%s -> %s:%s\n", userName(uid), groupName(gid), f.userOr(userName(uid)), f.groupOr(groupName(gid)))
+	}
+	return diff.String()
+}
+
+// userOr - the user the law wants, or the current one if it doesn't care
+func (f *fileCommon) userOr(current string) string {
+	if f.User == "" {
+		return current
+	}
+	return userName(f.User)
+}
+
+// groupOr - the group the law wants, or the current one if it doesn't care
+func (f *fileCommon) groupOr(current string) string {
+	if f.Group == "" {
+		return current
+	}
+	return groupName(f.Group)
+}
+
+// wantOwner - the uid and gid the law wants, -1 for either it doesn't set
+func (f *fileCommon) wantOwner() (int, int, error) {
+	uid, gid := -1, -1
+	if f.User != "" {
+		id := f.User
+		if _, err := strconv.Atoi(id); err != nil {
+			u, err := user.Lookup(f.User)
+			if err != nil {
+				return -1, -1, err
+			}
+			id = u.Uid
+		}
+		uid, _ = strconv.Atoi(id)
+	}
+	if f.Group != "" {
+		id := f.Group
+		if _, err := strconv.Atoi(id); err != nil {
+			g, err := user.LookupGroup(f.Group)
+			if err != nil {
+				return -1, -1, err
+			}
+			id = g.Gid
+		}
+		gid, _ = strconv.Atoi(id)
+	}
+	return uid, gid, nil
+}
+
+// ownerMatches - the file has the user and group the law wants
+func (f *fileCommon) ownerMatches() bool {
+	if f.User == "" && f.Group == "" {
+		return true
+	}
+	uid, gid, err := f.wantOwner()
+	if err != nil {
+		return false
+	}
+	_, owner := fileAttrs(f.Name)
+	if owner == "" {
+		return false
+	}
+	cur := strings.Split(owner, ":")
+	return (uid == -1 || cur[0] == strconv.Itoa(uid)) && (gid == -1 || cur[1] == strconv.Itoa(gid))
+}
+
+// userName - the name of a uid, the uid if it doesn't have one
+func userName(uid string) string {
+	if u, err := user.LookupId(uid); err == nil {
+		return u.Username
+	}
+	return uid
+}
+
+// groupName - the name of a gid, the gid if it doesn't have one
+func groupName(gid string) string {
+	if g, err := user.LookupGroupId(gid); err == nil {
+		return g.Name
+	}
+	return gid
+}
+
+// changedWithDiff - a pretend change, with the diff of the file
+func (f *fileCommon) changedWithDiff(msg, before, after string, current []byte, want string) *Result {
+	r := Changed(msg, before, after)
+	r.Diff = f.attrDiff() + contentDiff(f.Name, current, want)
+	return r
+}
@@ -0,0 +1,178 @@
+// Copyright © 2026 Iggy <iggy@theiggy.com>
+// All rights reserved.
+//
+// Redistribution and use in source and binary forms, with or without
+// modification, are permitted provided that the following conditions are met:
+//
+// 1. Redistributions of source code must retain the above copyright notice,
+//    this list of conditions and the following disclaimer.
+//
+// 2. Redistributions in binary form must reproduce the above copyright notice,
+//    this list of conditions and the following disclaimer in the documentation
+//    and/or other materials provided with the distribution.
+//
+// 3. Neither the name of the copyright holder nor the names of its contributors
+//    may be used to endorse or promote products derived from this software
+//    without specific prior written permission.
+//
+// THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS "AS IS"
+// AND ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT LIMITED TO, THE
+// IMPLIED WARRANTIES OF MERCHANTABILITY AND FITNESS FOR A PARTICULAR PURPOSE
+// ARE DISCLAIMED. IN NO EVENT SHALL THE COPYRIGHT HOLDER OR CONTRIBUTORS BE
+// LIABLE FOR ANY DIRECT, INDIRECT, INCIDENTAL, SPECIAL, EXEMPLARY, OR
+// CONSEQUENTIAL DAMAGES (INCLUDING, BUT NOT LIMITED TO, PROCUREMENT OF
+// SUBSTITUTE GOODS OR SERVICES; LOSS OF USE, DATA, OR PROFITS; OR BUSINESS
+// INTERRUPTION) HOWEVER CAUSED AND ON ANY THEORY OF LIABILITY, WHETHER IN
+// CONTRACT, STRICT LIABILITY, OR TORT (INCLUDING NEGLIGENCE OR OTHERWISE)
+// ARISING IN ANY WAY OUT OF THE USE OF THIS SOFTWARE, EVEN IF ADVISED OF THE
+// POSSIBILITY OF SUCH DAMAGE.
+
+package laws
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"os/exec"
+	"strings"
+	"sync"
+
+	"github.com/rs/zerolog/log"
+	"gopkg.in/yaml.v3"
+)
+
+// CommandError - a command run by a law failed
+type CommandError struct {
+	Command  string `json:"command"` // the command line that was run
+	ExitCode int    `json:"exit_code"`
+	Stdout   string `json:"stdout,omitempty"`
+	Stderr   string `json:"stderr,omitempty"`
+	Err      error  `json:"-"`
+}
+
+// Error - the command, how it failed and what it had to say about it
+func (e *CommandError) Error() string {
+	msg := fmt.Sprintf("%s: %v", e.Command, e.Err)
+	if stderr := strings.TrimSpace(e.Stderr); stderr != "" {
+		msg += ": " + stderr
+	}
+	return msg
+}
+
+// Unwrap - the underlying error from os/exec
+func (e *CommandError) Unwrap() error {
+	return e.Err
+}
+
+// CommandRun - a command a law ran and how it exited, for the journal
+type CommandRun struct {
+	Command  string `json:"command"`
+	ExitCode int    `json:"exit_code"`
+}
+
+// commandLog - the commands run while ensuring a law
+type commandLog struct {
+	mu   sync.Mutex
+	runs []CommandRun
+}
+
+type commandLogKey struct{}
+
+// withCommandLog - record the commands run with ctx
+func withCommandLog(ctx context.Context) (context.Context, *commandLog) {
+	cl := &commandLog{}
+	return context.WithValue(ctx, commandLogKey{}, cl), cl
+}
+
+// recordCommand - add a command to the log for ctx, if there is one
+func recordCommand(ctx context.Context, cmd *exec.Cmd) {
+	cl, ok := ctx.Value(commandLogKey{}).(*commandLog)
+	if !ok {
+		return
+	}
+	run := CommandRun{Command: strings.Join(cmd.Args, " "), ExitCode: -1}
+	if cmd.ProcessState != nil {
+		run.ExitCode = cmd.ProcessState.ExitCode()
+	}
+	cl.mu.Lock()
+	cl.runs = append(cl.runs, run)
+	cl.mu.Unlock()
+}
+
+// runCmd - run a command and capture its output. If it fails the error is
+// a *CommandError with everything we know about what happened.
+func runCmd(ctx context.Context, cmd *exec.Cmd) (string, string, error) {
+	var stdOut, stdErr bytes.Buffer
+	cmd.Stdout = &stdOut
+	cmd.Stderr = &stdErr
+
+	log.Debug().Strs("args", cmd.Args).Msg("running command")
+	err := cmd.Run()
+	recordCommand(ctx, cmd)
+	log.Debug().
+		Str("stdout", stdOut.String()).
+		Str("stderr", stdErr.String()).
+		Strs("args", cmd.Args).
+		Msg("command output")
+	if err != nil {
+		ce := &CommandError{
+			Command:  strings.Join(cmd.Args, " "),
+			ExitCode: -1,
+			Stdout:   stdOut.String(),
+			Stderr:   stdErr.String(),
+			Err:      err,
+		}
+		if cmd.ProcessState != nil {
+			ce.ExitCode = cmd.ProcessState.ExitCode()
+		}
+		return ce.Stdout, ce.Stderr, ce
+	}
+	return stdOut.String(), stdErr.String(), nil
+}
+
+// runCommand - shortcut for runCmd(exec.CommandContext(...)) that only
+// cares about stdout
+func runCommand(ctx context.Context, name string, args ...string) (string, error) {
+	stdOut, _, err := runCmd(ctx, exec.CommandContext(ctx, name, args...))
+	return stdOut, err
+}
+
+// ErrorPolicy - what to do with the rest of a run when a law fails
+type ErrorPolicy string
+
+const (
+	// OnErrorContinue - skip the laws that come after the failed law, but
+	// keep going with everything else
+	OnErrorContinue ErrorPolicy = "continue"
+	// OnErrorAbort - stop starting new laws as soon as one fails
+	OnErrorAbort ErrorPolicy = "abort"
+)
+
+// ParseErrorPolicy - turn a flag or yaml value into an ErrorPolicy
+func ParseErrorPolicy(s string) (ErrorPolicy, error) {
+	switch p := ErrorPolicy(s); p {
+	case OnErrorContinue, OnErrorAbort:
+		return p, nil
+	}
+	return "", fmt.Errorf("unknown error policy %q, expected continue or abort", s)
+}
+
+// UnmarshalYAML - only allow the known policies
+func (p *ErrorPolicy) UnmarshalYAML(value *yaml.Node) error {
+	policy, err := ParseErrorPolicy(value.Value)
+	if err != nil {
+		return fmt.Errorf("line %d: on_error: %w", value.Line, err)
+	}
+	*p = policy
+	return nil
+}
+
+// commandError - the *CommandError in an error chain, if there is one
+func commandError(err error) *CommandError {
+	var ce *CommandError
+	if errors.As(err, &ce) {
+		return ce
+	}
+	return nil
+}
@@ -0,0 +1,253 @@
+// Copyright © 2026 Iggy <iggy@theiggy.com>
+// All rights reserved.
+//
+// Redistribution and use in source and binary forms, with or without
+// modification, are permitted provided that the following conditions are met:
+//
+// 1. Redistributions of source code must retain the above copyright notice,
+//    this list of conditions and the following disclaimer.
+//
+// 2. Redistributions in binary form must reproduce the above copyright notice,
+//    this list of conditions and the following disclaimer in the documentation
+//    and/or other materials provided with the distribution.
+//
+// 3. Neither the name of the copyright holder nor the names of its contributors
+//    may be used to endorse or promote products derived from this software
+//    without specific prior written permission.
+//
+// THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS "AS IS"
+// AND ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT LIMITED TO, THE
+// IMPLIED WARRANTIES OF MERCHANTABILITY AND FITNESS FOR A PARTICULAR PURPOSE
+// ARE DISCLAIMED. IN NO EVENT SHALL THE COPYRIGHT HOLDER OR CONTRIBUTORS BE
+// LIABLE FOR ANY DIRECT, INDIRECT, INCIDENTAL, SPECIAL, EXEMPLARY, OR
+// CONSEQUENTIAL DAMAGES (INCLUDING, BUT NOT LIMITED TO, PROCUREMENT OF
+// SUBSTITUTE GOODS OR SERVICES; LOSS OF USE, DATA, OR PROFITS; OR BUSINESS
+// INTERRUPTION) HOWEVER CAUSED AND ON ANY THEORY OF LIABILITY, WHETHER IN
+// CONTRACT, STRICT LIABILITY, OR TORT (INCLUDING NEGLIGENCE OR OTHERWISE)
+// ARISING IN ANY WAY OUT OF THE USE OF THIS SOFTWARE, EVEN IF ADVISED OF THE
+// POSSIBILITY OF SUCH DAMAGE.
+
+package laws
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"sync/atomic"
+
+	"github.com/hmdsefi/gograph"
+	"github.com/rs/zerolog/log"
+)
+
+// DefaultWorkers - how many laws are ensured at once if not configured
+const DefaultWorkers = 4
+
+// Locker is implemented by laws that can't safely run at the same time as
+// some other laws. Laws that return the same key are run one at a time,
+// i.e. every Package shares the package manager lock.
+type Locker interface {
+	LockKey() string
+}
+
+// Executor - ensures laws as soon as everything they come after is done,
+// so independent branches of the graph run in parallel
+type Executor struct {
+	Workers int         // max number of laws to ensure at once
+	Pretend bool        // only report what would change
+	OnError ErrorPolicy // what to do when a law fails, laws can override it
+
+	mu    sync.Mutex
+	locks map[string]*sync.Mutex
+}
+
+// NewExecutor - setup an executor, workers < 1 uses DefaultWorkers
+func NewExecutor(workers int, pretend bool) *Executor {
+	if workers < 1 {
+		workers = DefaultWorkers
+	}
+	return &Executor{
+		Workers: workers,
+		Pretend: pretend,
+		OnError: OnErrorContinue,
+		locks:   map[string]*sync.Mutex{},
+	}
+}
+
+// lockFor - get the mutex for a lock key, creating it if necessary
+func (e *Executor) lockFor(key string) *sync.Mutex {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	l, ok := e.locks[key]
+	if !ok {
+		l = &sync.Mutex{}
+		e.locks[key] = l
+	}
+	return l
+}
+
+// policy - the error policy for a law, its own on_error wins
+func (e *Executor) policy(n *LawNode) ErrorPolicy {
+	if n.Options != nil && n.Options.OnError != "" {
+		return n.Options.OnError
+	}
+	return e.OnError
+}
+
+type triggeredKey struct{}
+
+// withTriggered - ensure a law with ctx because something it watches changed
+func withTriggered(ctx context.Context) context.Context {
+	return context.WithValue(ctx, triggeredKey{}, true)
+}
+
+// triggered - whether something the law being ensured with ctx watches
+// changed in this run
+func triggered(ctx context.Context) bool {
+	t, _ := ctx.Value(triggeredKey{}).(bool)
+	return t
+}
+
+// finished - a law the executor is done with
+type finished struct {
+	node   *LawNode
+	result *Result
+}
+
+// Run - ensure all of the laws from ParseFiles, returns the results in the
+// same order as sorted. If a law fails, everything that comes after it is
+// skipped. Unrelated branches keep going unless the error policy is abort,
+// then nothing new is started and the rest of the laws are skipped.
+// Cancelling ctx kills anything running and the laws that never ran are
+// reported as cancelled.
+func (e *Executor) Run(ctx context.Context, sorted []*gograph.Vertex[*LawNode]) []*Result {
+	// gograph only tracks children (and hands back copies of the vertices),
+	// so everything here is keyed by the label
+	children := map[*LawNode][]*LawNode{}
+	waiting := map[*LawNode]int{}
+	running := map[*LawNode]bool{} // sorted may only be some of the graph
+	for _, v := range sorted {
+		running[v.Label()] = true
+	}
+	for _, v := range sorted {
+		for _, child := range v.Neighbors() {
+			if !running[child.Label()] {
+				continue
+			}
+			children[v.Label()] = append(children[v.Label()], child.Label())
+			waiting[child.Label()]++
+		}
+	}
+
+	blocked := map[*LawNode]string{}       // law -> id of the failed law blocking it
+	aborted := ""                          // id of the failed law that aborted the run
+	var abortedFlag atomic.Pointer[string] // for laws already waiting on a worker
+	results := map[*LawNode]*Result{}
+	byID := map[string]*Result{} // for looking up watched laws
+	done := make(chan finished)
+	sem := make(chan struct{}, e.Workers)
+
+	skip := func(n *LawNode, msg string) {
+		r := Skipped(msg)
+		r.ID = n.ID()
+		log.Warn().Str("law", r.ID).Msg(r.Message)
+		go func() { done <- finished{n, r} }()
+	}
+	cancelled := func(n *LawNode) *Result {
+		r := Cancelled(ctx.Err())
+		r.ID = n.ID()
+		log.Warn().Str("law", r.ID).Msg(r.Message)
+		return r
+	}
+	launch := func(n *LawNode) {
+		if _, ok := n.Law.(*Root); ok {
+			go func() { done <- finished{n, nil} }()
+			return
+		}
+		if ctx.Err() != nil {
+			r := cancelled(n)
+			go func() { done <- finished{n, r} }()
+			return
+		}
+		if failedID, ok := blocked[n]; ok {
+			skip(n, fmt.Sprintf("skipped because %s failed", failedID))
+			return
+		}
+		if aborted != "" {
+			skip(n, fmt.Sprintf("skipped because %s failed and aborted the run", aborted))
+			return
+		}
+		// the nodes get run more than once (pretend then apply), so
+		// whether they were triggered only lives as long as this run
+		lctx := ctx
+		for _, id := range n.Watches {
+			if r, ok := byID[id]; ok && r.Status == StatusChanged {
+				lctx = withTriggered(ctx)
+			}
+		}
+		go func() {
+			var lock *sync.Mutex
+			if l, ok := n.Law.(Locker); ok {
+				lock = e.lockFor(l.LockKey())
+				lock.Lock()
+			}
+			sem <- struct{}{}
+			var r *Result
+			if id := abortedFlag.Load(); id != nil {
+				r = Skipped(fmt.Sprintf("skipped because %s failed and aborted the run", *id))
+				r.ID = n.ID()
+			} else if ctx.Err() != nil {
+				r = cancelled(n)
+			} else {
+				r = n.Ensure(lctx, e.Pretend)
+			}
+			<-sem
+			if lock != nil {
+				lock.Unlock()
+			}
+			done <- finished{n, r}
+		}()
+	}
+
+	for _, v := range sorted {
+		if waiting[v.Label()] == 0 {
+			launch(v.Label())
+		}
+	}
+	for range sorted {
+		f := <-done
+		results[f.node] = f.result
+		if f.result != nil {
+			f.result.File = f.node.File
+			byID[f.result.ID] = f.result
+		}
+
+		failedID := ""
+		if f.result != nil && f.result.Status == StatusFailed {
+			failedID = f.result.ID
+			if aborted == "" && e.policy(f.node) == OnErrorAbort {
+				log.Error().Str("law", failedID).Msg("aborting run")
+				aborted = failedID
+				abortedFlag.Store(&failedID)
+			}
+		} else if id, ok := blocked[f.node]; ok {
+			failedID = id
+		}
+		for _, child := range children[f.node] {
+			if failedID != "" {
+				blocked[child] = failedID
+			}
+			waiting[child]--
+			if waiting[child] == 0 {
+				launch(child)
+			}
+		}
+	}
+
+	ordered := make([]*Result, 0, len(sorted))
+	for _, v := range sorted {
+		if r := results[v.Label()]; r != nil {
+			ordered = append(ordered, r)
+		}
+	}
+	return ordered
+}
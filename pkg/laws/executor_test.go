@@ -0,0 +1,350 @@
+// Copyright © 2026 Iggy <iggy@theiggy.com>
+// All rights reserved.
+//
+// Redistribution and use in source and binary forms, with or without
+// modification, are permitted provided that the following conditions are met:
+//
+// 1. Redistributions of source code must retain the above copyright notice,
+//    this list of conditions and the following disclaimer.
+//
+// 2. Redistributions in binary form must reproduce the above copyright notice,
+//    this list of conditions and the following disclaimer in the documentation
+//    and/or other materials provided with the distribution.
+//
+// 3. Neither the name of the copyright holder nor the names of its contributors
+//    may be used to endorse or promote products derived from this software
+//    without specific prior written permission.
+//
+// THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS "AS IS"
+// AND ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT LIMITED TO, THE
+// IMPLIED WARRANTIES OF MERCHANTABILITY AND FITNESS FOR A PARTICULAR PURPOSE
+// ARE DISCLAIMED. IN NO EVENT SHALL THE COPYRIGHT HOLDER OR CONTRIBUTORS BE
+// LIABLE FOR ANY DIRECT, INDIRECT, INCIDENTAL, SPECIAL, EXEMPLARY, OR
+// CONSEQUENTIAL DAMAGES (INCLUDING, BUT NOT LIMITED TO, PROCUREMENT OF
+// SUBSTITUTE GOODS OR SERVICES; LOSS OF USE, DATA, OR PROFITS; OR BUSINESS
+// INTERRUPTION) HOWEVER CAUSED AND ON ANY THEORY OF LIABILITY, WHETHER IN
+// CONTRACT, STRICT LIABILITY, OR TORT (INCLUDING NEGLIGENCE OR OTHERWISE)
+// ARISING IN ANY WAY OUT OF THE USE OF THIS SOFTWARE, EVEN IF ADVISED OF THE
+// POSSIBILITY OF SUCH DAMAGE.
+
+package laws
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/hmdsefi/gograph"
+)
+
+// fakeLaw - a law that does whatever the test says
+type fakeLaw struct {
+	ensure func(ctx context.Context, pretend bool) *Result
+}
+
+func (f *fakeLaw) Ensure(ctx context.Context, pretend bool) *Result {
+	return f.ensure(ctx, pretend)
+}
+
+// fakeReactor - a fake law that reacts to what it watches, like a Service
+type fakeReactor struct {
+	fakeLaw
+	reacted []bool // pretend, for each time it reacted
+}
+
+func (f *fakeReactor) React(ctx context.Context, pretend bool) *Result {
+	f.reacted = append(f.reacted, pretend)
+	return Changed("reacted", "", "")
+}
+
+// fakeLocker - a fake law with a lock key
+type fakeLocker struct {
+	fakeLaw
+	key string
+}
+
+func (f *fakeLocker) LockKey() string {
+	return f.key
+}
+
+// fakeNode - a node for a fake law, its id is fake::law::<name>
+func fakeNode(name string, law Law) *LawNode {
+	return &LawNode{Law: law, Group: "fake", Type: "law", Name: name}
+}
+
+// testGraph - the nodes sorted like ParseFiles does, with edges from the
+// first id to the second
+func testGraph(t *testing.T, nodes []*LawNode, edges ...[2]string) []*gograph.Vertex[*LawNode] {
+	t.Helper()
+	graph := gograph.New[*LawNode](gograph.Acyclic())
+	root := gograph.NewVertex(&LawNode{Law: &Root{Name: "root"}, Group: "root", Type: "root", Name: "root"})
+	byID := map[string]*gograph.Vertex[*LawNode]{}
+	for _, n := range nodes {
+		v := gograph.NewVertex(n)
+		if _, err := graph.AddEdge(root, v); err != nil {
+			t.Fatal(err)
+		}
+		byID[n.ID()] = v
+	}
+	for _, e := range edges {
+		addRequisite(graph, root, byID[e[0]], byID[e[1]])
+	}
+	sorted, err := gograph.TopologySort(graph)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return sorted
+}
+
+// resultsByID - the results of a run by law id
+func resultsByID(results []*Result) map[string]*Result {
+	byID := map[string]*Result{}
+	for _, r := range results {
+		byID[r.ID] = r
+	}
+	return byID
+}
+
+func TestExecutorTriggersOnlyLastForARun(t *testing.T) {
+	// the file would change when pretending, but by the time the real run
+	// comes it's already right (or isn't part of it, like when the agent
+	// remediates other laws), so the service mustn't restart
+	file := fakeNode("file", &fakeLaw{ensure: func(_ context.Context, pretend bool) *Result {
+		if pretend {
+			return Changed("would change", "", "")
+		}
+		return Unchanged("already right")
+	}})
+	service := &fakeReactor{fakeLaw: fakeLaw{ensure: func(context.Context, bool) *Result {
+		return Unchanged("running")
+	}}}
+	serviceNode := fakeNode("service", service)
+	serviceNode.Watches = []string{file.ID()}
+	sorted := testGraph(t, []*LawNode{file, serviceNode}, [2]string{file.ID(), serviceNode.ID()})
+
+	pretend := resultsByID(NewExecutor(1, true).Run(context.Background(), sorted))
+	if r := pretend[serviceNode.ID()]; r.Status != StatusChanged {
+		t.Errorf("pretend: service %s %s, want it to react", r.Status, r.Message)
+	}
+	applied := resultsByID(NewExecutor(1, false).Run(context.Background(), sorted))
+	if r := applied[serviceNode.ID()]; r.Status != StatusUnchanged {
+		t.Errorf("apply: service %s %s, want it unchanged", r.Status, r.Message)
+	}
+	if len(service.reacted) != 1 || !service.reacted[0] {
+		t.Errorf("service reacted %v, want only when pretending", service.reacted)
+	}
+
+	// and just the service, like the agent remediating it on its own
+	only, err := Selector{Only: []string{serviceNode.ID()}, NoDeps: true}.Filter(sorted)
+	if err != nil {
+		t.Fatal(err)
+	}
+	applied = resultsByID(NewExecutor(1, false).Run(context.Background(), only))
+	if r := applied[serviceNode.ID()]; r.Status != StatusUnchanged {
+		t.Errorf("apply service alone: %s %s, want it unchanged", r.Status, r.Message)
+	}
+}
+
+func TestExecutorWorkers(t *testing.T) {
+	for _, workers := range []int{1, 3} {
+		t.Run(fmt.Sprint(workers), func(t *testing.T) {
+			var running, most atomic.Int32
+			release := make(chan struct{})
+			var nodes []*LawNode
+			for i := 0; i < 8; i++ {
+				nodes = append(nodes, fakeNode(fmt.Sprint(i), &fakeLaw{ensure: func(context.Context, bool) *Result {
+					n := running.Add(1)
+					for m := most.Load(); n > m && !most.CompareAndSwap(m, n); m = most.Load() {
+					}
+					<-release
+					running.Add(-1)
+					return Changed("done", "", "")
+				}}))
+			}
+			sorted := testGraph(t, nodes)
+
+			done := make(chan []*Result)
+			go func() { done <- NewExecutor(workers, false).Run(context.Background(), sorted) }()
+			// wait for the workers to fill up, then make sure no more start
+			for deadline := time.Now().Add(5 * time.Second); running.Load() < int32(workers); {
+				if time.Now().After(deadline) {
+					t.Fatalf("only %d laws started, want %d", running.Load(), workers)
+				}
+				time.Sleep(time.Millisecond)
+			}
+			time.Sleep(20 * time.Millisecond)
+			if n := running.Load(); n != int32(workers) {
+				t.Errorf("%d laws running at once, want %d", n, workers)
+			}
+			close(release)
+			results := <-done
+			if m := most.Load(); m != int32(workers) {
+				t.Errorf("at most %d laws ran at once, want %d", m, workers)
+			}
+			if len(results) != len(nodes) {
+				t.Errorf("%d results, want %d", len(results), len(nodes))
+			}
+			for _, r := range results {
+				if r.Status != StatusChanged {
+					t.Errorf("%s %s, want changed", r.ID, r.Status)
+				}
+			}
+		})
+	}
+}
+
+func TestExecutorLocks(t *testing.T) {
+	// laws with the same lock key never run at the same time, even with
+	// workers to spare
+	var running, most atomic.Int32
+	var nodes []*LawNode
+	for i := 0; i < 4; i++ {
+		nodes = append(nodes, fakeNode(fmt.Sprint(i), &fakeLocker{key: "pkg", fakeLaw: fakeLaw{ensure: func(context.Context, bool) *Result {
+			n := running.Add(1)
+			for m := most.Load(); n > m && !most.CompareAndSwap(m, n); m = most.Load() {
+			}
+			time.Sleep(5 * time.Millisecond)
+			running.Add(-1)
+			return Unchanged("")
+		}}}))
+	}
+	NewExecutor(4, false).Run(context.Background(), testGraph(t, nodes))
+	if m := most.Load(); m != 1 {
+		t.Errorf("%d laws with the same lock ran at once", m)
+	}
+}
+
+func TestExecutorErrorPolicy(t *testing.T) {
+	// a fails, b and c come after it. slow is unrelated and d comes after
+	// it, so d starts after a's failure is known
+	tests := []struct {
+		name    string
+		policy  ErrorPolicy // the executor's
+		onError ErrorPolicy // a's own
+		want    map[string]string
+	}{
+		{
+			name:   "continue",
+			policy: OnErrorContinue,
+			want: map[string]string{
+				"a": "failed: broken",
+				"b": "skipped: skipped because fake::law::a failed",
+				"c": "skipped: skipped because fake::law::a failed",
+				"d": "changed: done",
+			},
+		},
+		{
+			name:   "abort",
+			policy: OnErrorAbort,
+			want: map[string]string{
+				"a": "failed: broken",
+				"b": "skipped: skipped because fake::law::a failed",
+				"c": "skipped: skipped because fake::law::a failed",
+				"d": "skipped: skipped because fake::law::a failed and aborted the run",
+			},
+		},
+		{
+			name:    "law aborts",
+			policy:  OnErrorContinue,
+			onError: OnErrorAbort,
+			want: map[string]string{
+				"a": "failed: broken",
+				"b": "skipped: skipped because fake::law::a failed",
+				"c": "skipped: skipped because fake::law::a failed",
+				"d": "skipped: skipped because fake::law::a failed and aborted the run",
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			changed := func(context.Context, bool) *Result { return Changed("done", "", "") }
+			a := fakeNode("a", &fakeLaw{ensure: func(context.Context, bool) *Result {
+				return Failed("broken", errors.New("broken"))
+			}})
+			if tt.onError != "" {
+				a.Options = &Options{OnError: tt.onError}
+			}
+			slow := fakeNode("slow", &fakeLaw{ensure: func(context.Context, bool) *Result {
+				time.Sleep(50 * time.Millisecond)
+				return Unchanged("")
+			}})
+			b, c, d := fakeNode("b", &fakeLaw{ensure: changed}), fakeNode("c", &fakeLaw{ensure: changed}), fakeNode("d", &fakeLaw{ensure: changed})
+			sorted := testGraph(t, []*LawNode{a, b, c, slow, d},
+				[2]string{a.ID(), b.ID()}, [2]string{b.ID(), c.ID()}, [2]string{slow.ID(), d.ID()})
+
+			e := NewExecutor(4, false)
+			e.OnError = tt.policy
+			results := resultsByID(e.Run(context.Background(), sorted))
+			for name, want := range tt.want {
+				r := results["fake::law::"+name]
+				if got := fmt.Sprintf("%s: %s", r.Status, r.Message); got != want {
+					t.Errorf("%s = %q, want %q", name, got, want)
+				}
+			}
+		})
+	}
+}
+
+func TestExecutorCancelled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	first := fakeNode("first", &fakeLaw{ensure: func(context.Context, bool) *Result {
+		cancel()
+		return Changed("done", "", "")
+	}})
+	second := fakeNode("second", &fakeLaw{ensure: func(context.Context, bool) *Result {
+		t.Error("second ran after the run was cancelled")
+		return Changed("done", "", "")
+	}})
+	results := resultsByID(NewExecutor(1, false).Run(ctx, testGraph(t, []*LawNode{first, second}, [2]string{first.ID(), second.ID()})))
+	if r := results[first.ID()]; r.Status != StatusChanged {
+		t.Errorf("first %s, want changed", r.Status)
+	}
+	if r := results[second.ID()]; r.Status != StatusCancelled {
+		t.Errorf("second %s, want cancelled", r.Status)
+	}
+}
+
+func TestExecutorWatch(t *testing.T) {
+	tests := []struct {
+		name      string
+		watched   *Result
+		reactor   bool
+		want      Status
+		wantMsg   string
+		reactions int
+	}{
+		{"watched changed", Changed("changed", "", ""), false, StatusChanged, "ran", 0},
+		{"watched unchanged", Unchanged(""), false, StatusSkipped, "nothing watched changed", 0},
+		{"watched failed", Failed("broken", errors.New("broken")), false, StatusSkipped, "skipped because fake::law::watched failed", 0},
+		{"reactor, watched changed", Changed("changed", "", ""), true, StatusChanged, "reacted", 1},
+		{"reactor, watched unchanged", Unchanged(""), true, StatusUnchanged, "ran", 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			watched := fakeNode("watched", &fakeLaw{ensure: func(context.Context, bool) *Result { return tt.watched }})
+			law := fakeLaw{ensure: func(context.Context, bool) *Result { return Unchanged("ran") }}
+			if !tt.reactor {
+				law.ensure = func(context.Context, bool) *Result { return Changed("ran", "", "") }
+			}
+			reactor := &fakeReactor{fakeLaw: law}
+			watcher := fakeNode("watcher", &law)
+			if tt.reactor {
+				watcher = fakeNode("watcher", reactor)
+			}
+			watcher.Watches = []string{watched.ID()}
+			sorted := testGraph(t, []*LawNode{watched, watcher}, [2]string{watched.ID(), watcher.ID()})
+
+			r := resultsByID(NewExecutor(2, false).Run(context.Background(), sorted))[watcher.ID()]
+			if r.Status != tt.want || !strings.Contains(r.Message, tt.wantMsg) {
+				t.Errorf("watcher %s %q, want %s %q", r.Status, r.Message, tt.want, tt.wantMsg)
+			}
+			if len(reactor.reacted) != tt.reactions {
+				t.Errorf("reacted %d times, want %d", len(reactor.reacted), tt.reactions)
+			}
+		})
+	}
+}
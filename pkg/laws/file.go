@@ -37,15 +37,20 @@ package laws
 
 import (
 	"bufio"
+	"context"
+	"errors"
 	"fmt"
 	"io"
 	"io/fs"
 	"os"
 	"path"
+	"path/filepath"
 	"regexp"
 	"strconv"
 	"strings"
+	"syscall"
 
+	"github.com/rs/zerolog"
 	"github.com/rs/zerolog/log"
 	"gopkg.in/yaml.v3"
 )
@@ -59,61 +64,109 @@ import (
 //	}
 
 type fileCommon struct {
-	Name    string `yaml:"name"`
-	Before  []string
-	After   []string
-	MakeDir bool        `yaml:"make_dir"` // make the parent dir
-	User    string      // user/uid owner of the file
-	Group   string      // group/gid owner of the file
-	Mode    fs.FileMode // file mode TODO maybe default to 400?
-	Backup  bool        // whether to backup the file before changing
+	CommonFields `yaml:"-"` // Name is the file's path
 
+	MakeDir bool   `yaml:"make_dir"` // make the parent dir
+	User    string `yaml:"user"`     // user/uid owner of the file
+	Group   string `yaml:"group"`    // group/gid owner of the file
+	Mode    Mode   `yaml:"mode"`     // file mode TODO maybe default to 400?
+	Backup  bool   `yaml:"backup"`   // whether to keep a .bak copy of the file before changing
+}
+
+// Mode - a file mode, always octal in the yaml (0644 or 644) like chmod
+type Mode fs.FileMode
+
+// UnmarshalYAML - parse the mode as octal
+func (m *Mode) UnmarshalYAML(value *yaml.Node) error {
+	fm, err := parseMode(value.Value)
+	if err != nil {
+		return fmt.Errorf("line %d: mode should be octal, i.e. 0644: %w", value.Line, err)
+	}
+	*m = fm
+	return nil
+}
+
+// parseMode - an octal mode, with or without a 0o prefix
+func parseMode(s string) (Mode, error) {
+	fm, err := strconv.ParseUint(strings.TrimPrefix(s, "0o"), 8, 32)
+	return Mode(fm), err
+}
+
+// FileMode - the mode as an fs.FileMode
+func (m Mode) FileMode() fs.FileMode {
+	return fs.FileMode(m)
+}
+
+// changedWithMode - the file was changed, make sure it has the right mode
+// and owner too
+func (f *fileCommon) changedWithMode(msg, before, after string) *Result {
+	err := f.applyMode()
+	if err != nil {
+		log.Error().Err(err).Str("file", f.Name).Msg("failed to chmod")
+		return Failed("failed to chmod file", err)
+	}
+	err = f.applyOwner()
+	if err != nil {
+		log.Error().Err(err).Str("file", f.Name).Msg("failed to chown")
+		return Failed("failed to chown file", err)
+	}
+	return Changed(msg, before, after)
+}
+
+// applyMode - chmod the file if the law has a mode
+func (f *fileCommon) applyMode() error {
+	if f.Mode == 0 {
+		return nil
+	}
+	return os.Chmod(f.Name, f.Mode.FileMode())
+}
+
+// applyOwner - chown the file if the law has a user or group
+func (f *fileCommon) applyOwner() error {
+	if f.User == "" && f.Group == "" {
+		return nil
+	}
+	uid, gid, err := f.wantOwner()
+	if err != nil {
+		return err
+	}
+	return os.Chown(f.Name, uid, gid)
+}
+
+// keepBackup - whether the law wants a .bak copy of the file
+func (f *fileCommon) keepBackup() bool {
+	return f.Backup
+}
+
+// ManagedFiles - the file the law changes
+func (f *fileCommon) ManagedFiles() []string {
+	return []string{f.Name}
+}
+
+// LockKey - laws that change the same file run one at a time
+func (f *fileCommon) LockKey() string {
+	return f.Name
 }
 
 type FileTemplate struct {
-	fileCommon `yaml:",inline"`
-	// Name         string      // file path
-	// MakeDir      bool        `yaml:"make_dir"` // make the parent dir
-	// User         string      // user/uid owner of the file
-	// Group        string      // group/gid owner of the file
-	// Mode         fs.FileMode // file mode TODO maybe default to 400?
-	Text         string // text template
-	TemplatePath string // path to a file to use instead of Text (unimpl)
-	// Backup       bool        // whether to backup the file before changing
-
-	// CommonFields
-	// Name   string
-	// Before []string
-	// After  []string
+	fileCommon   `yaml:",inline"`
+	Text         string `yaml:"text"`     // text template
+	TemplatePath string `yaml:"template"` // template file to use instead of Text, relative to the laws file
 }
 type FileInsert struct {
 	fileCommon `yaml:",inline"`
-	// Name       string
-	// MakeDir    bool `yaml:"make_dir"` // make the parent dir
-	AfterLine  string
-	BeforeLine string
-	LineNum    int64
-	Text       string
-
-	// CommonFields
-	// Name   string
-	// Before []string
-	// After  []string
+	AfterLine  string `yaml:"after_line"`
+	BeforeLine string `yaml:"before_line"`
+	LineNum    int64  `yaml:"line_num"`
+	Text       string `yaml:"text"`
 }
 
 type FileChange struct {
 	fileCommon `yaml:",inline"`
-	Search     string   `yaml:"search"`            // line to search for
-	Replace    string   `yaml:"replace,omitempty"` // line to replace with
+	Search     string   `yaml:"search"`  // line to search for
+	Replace    string   `yaml:"replace"` // line to replace with
 	Done       string   `yaml:"done"`
-	If         []string // should probably convert this into some template logic
-
-	// Name    string `yaml:"name"`
-	// MakeDir bool     `yaml:"make_dir"`          // make the parent dir
-	// CommonFields
-	// Name   string
-	// Before []string
-	// After  []string
+	If         []string `yaml:"-"` // should probably convert this into some template logic
 }
 
 type FileLink struct {
@@ -130,146 +183,119 @@ type FileLink struct {
 // }
 
 func (f *FileTemplate) UnmarshalYAML(value *yaml.Node) error {
-	// f.LineNum = -1
+	log.Trace().Interface("Node", value).Msg("UnmarshalYAML filetemplate")
 
-	log.Trace().Interface("Node", value).Msg("UnmarshalYAML fileinsert")
-	if value.Tag != "!!map" {
-		return fmt.Errorf("unable to unmarshal yaml: value not map (%s)", value.Tag)
-	}
-
-	for i, node := range value.Content {
-		log.Trace().Interface("node1", node).Msg("")
-		switch node.Value {
-		// common fields
-		case "name":
-			f.Name = value.Content[i+1].Value
-		case "before":
-			for _, j := range value.Content[i+1].Content {
-				f.Before = append(f.Before, j.Value)
-			}
-		case "after":
-			for _, j := range value.Content[i+1].Content {
-				f.After = append(f.After, j.Value)
-			}
-		case "make_dir":
-			b, err := strconv.ParseBool(value.Content[i+1].Value)
-			if err != nil {
-				log.Warn().Str("key", node.Value).Str("val", value.Content[i+1].Value).Msg("failed to parse make_dir")
-			}
-			f.MakeDir = b
-		case "user":
-			f.User = value.Content[i+1].Value
-		case "group":
-			f.Group = value.Content[i+1].Value
-		case "mode":
-			// 0644 (etc) is octal
-			fm, err := strconv.ParseInt(value.Content[i+1].Value, 8, 32)
-			if err != nil {
-				log.Error().Err(err).Msg("failed to parse mode")
-			}
-			f.Mode = os.FileMode(fm)
-			// f.Mode, _ = int(value.Content[i+1].Value)
-		case "backup":
-			b, err := strconv.ParseBool(value.Content[i+1].Value)
-			if err != nil {
-				log.Warn().Str("key", node.Value).Str("val", value.Content[i+1].Value).Msg("failed to parse backup")
-			}
-			f.Backup = b
-		// case "after_line":
-		// 	f.AfterLine = value.Content[i+1].Value
-		// case "before_line":
-		// 	f.BeforeLine = value.Content[i+1].Value
-		// case "line_num":
-		// 	f.LineNum, _ = strconv.ParseInt(value.Content[i+1].Value, 10, 64)
-		case "text":
-			f.Text = value.Content[i+1].Value
-
-			// case "":
-			// m. = value.Content[i+1].Value
-			// case "":
-			// m. = value.Content[i+1].Value
-			// case "":
-			// m. = value.Content[i+1].Value
-			// case "":
-			// m. = value.Content[i+1].Value
-		}
-	}
-	log.Trace().Interface("f", f).Msg("what's in the box?!?!")
+	type rawFileTemplate FileTemplate
+	return value.Decode((*rawFileTemplate)(f))
+}
 
+// render - render the template file into Text, it gets the same funcs and
+// data as the laws file
+func (f *FileTemplate) render(src *lawsSource) error {
+	path := f.templatePath(src)
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+	rendered, err := renderTemplate(path, content, src)
+	if err != nil {
+		return err
+	}
+	f.Text = string(rendered)
 	return nil
+}
 
+// templatePath - where the template file is
+func (f *FileTemplate) templatePath(src *lawsSource) string {
+	if filepath.IsAbs(f.TemplatePath) {
+		return f.TemplatePath
+	}
+	return filepath.Join(src.dir(), f.TemplatePath)
 }
 
 // Ensure ensures that the file exists with the correct contents
-func (f *FileTemplate) Ensure(pretend bool) error {
+func (f *FileTemplate) Ensure(ctx context.Context, pretend bool) *Result {
 	log.Trace().Interface("File", f).Msg("file ensure")
 
 	if f.Name == "" {
-		return fmt.Errorf("file template name not set")
+		return Failed("file template name not set", fmt.Errorf("file template name not set"))
+	}
+
+	before := absent
+	existing, err := os.ReadFile(f.Name)
+	if err == nil {
+		before = hashContent(existing)
+	}
+	after := hashContent([]byte(f.Text))
+
+	if f.Exists() && before == after && f.modeMatches() && f.ownerMatches() {
+		log.Trace().Str("file", f.Name).Msg("file already matches")
+		return Unchanged("file already matches")
 	}
 
 	if pretend {
 		if f.Exists() {
-			log.Info().Str("file", f.Name).Msg("file exists, skipping")
-		} else {
-			log.Info().Str("file", f.Name).Msg("file doesn't exist, would create")
+			log.Info().Str("file", f.Name).Msg("file doesn't match, would write")
+			return f.changedWithDiff("file would be written", before, after, existing, f.Text)
 		}
+		log.Info().Str("file", f.Name).Msg("file doesn't exist, would create")
+		return f.changedWithDiff("file would be created", before, after, nil, f.Text)
+	}
+
+	var isDir bool
+	fi, err := os.Stat(path.Dir(f.Name))
+	if err != nil {
+		// this means the path didn't exist, so it's definitely not a dir
+		// not exactly an error, we expect this to be the case sometimes
+		log.Debug().
+			Err(err).
+			Str("file", f.Name).
+			Interface("fi", fi).
+			Msg("failed to stat dir")
+		isDir = false
 	} else {
-		// TODO make sure we aren't overwriting an existing file
-		if f.Backup && f.Exists() {
-			log.Trace().Msg("backing up file before writing")
-			err := os.Rename(f.Name, f.Name+".bak")
-			if err != nil {
-				log.Error().Err(err).Interface("file", f).Msg("failed to backup file")
-			}
-		}
-		var isDir bool
-		fi, err := os.Stat(path.Dir(f.Name))
+		isDir = fi.IsDir()
+	}
+	if f.MakeDir && !isDir {
+		err := os.MkdirAll(path.Dir(f.Name), 0755)
 		if err != nil {
-			// this means the path didn't exist, so it's definitely not a dir
-			// not exactly an error, we expect this to be the case sometimes
-			log.Debug().
+			log.Error().
 				Err(err).
 				Str("file", f.Name).
-				Interface("fi", fi).
-				Msg("failed to stat dir")
-			isDir = false
-		} else {
-			isDir = fi.IsDir()
-		}
-		if f.MakeDir && !isDir {
-			err := os.MkdirAll(path.Dir(f.Name), 0755)
-			if err != nil {
-				log.Error().
-					Err(err).
-					Str("file", f.Name).
-					Msg("failed to mkdirall for file")
-			}
-		}
-		if !f.Exists() {
-			// we just always write the file, opening -< checking -> possibly writing is often slower than just writing
-			err := os.WriteFile(f.Name, []byte(f.Text), f.Mode)
-			if err != nil {
-				log.Error().Err(err).Interface("File", f).Msg("failed to write file")
-			}
-		} else {
-			log.Trace().Msg("updating file to match")
-		}
-		// ->checking -> possibly writing is often slower than just writing
-		err = os.WriteFile(f.Name, []byte(f.Text), f.Mode)
-		if err != nil {
-			log.Error().Err(err).Interface("File", f).Msg("failed to write file")
-		}
-		// } else {
-		//  	log.Trace().Msg("updating file to match")
-		// }
-		err = os.Chmod(f.Name, f.Mode)
-		if err != nil {
-			log.Error().Err(err).Msg("failed to chmod")
+				Msg("failed to mkdirall for file")
 		}
 	}
+	if f.Exists() {
+		log.Trace().Msg("updating file to match")
+	}
+	// ->checking -> possibly writing is often slower than just writing
+	if r := f.writeFile(log.With().Str("file template", f.Name).Logger(), []byte(f.Text)); r != nil {
+		return r
+	}
+	err = f.applyMode()
+	if err != nil {
+		log.Error().Err(err).Msg("failed to chmod")
+		return Failed("failed to chmod file", err)
+	}
+	err = f.applyOwner()
+	if err != nil {
+		log.Error().Err(err).Msg("failed to chown")
+		return Failed("failed to chown file", err)
+	}
 
-	return nil
+	return Changed("file written", before, after)
+}
+
+// modeMatches checks if the existing file has the wanted mode
+func (f *FileTemplate) modeMatches() bool {
+	if f.Mode == 0 {
+		return true // no mode, whatever it has is fine
+	}
+	fi, err := os.Stat(f.Name)
+	if err != nil {
+		return false
+	}
+	return fi.Mode().Perm() == f.Mode.FileMode().Perm()
 }
 
 // Exists checks if the file exists
@@ -284,278 +310,207 @@ func (f *FileInsert) UnmarshalYAML(value *yaml.Node) error {
 	f.LineNum = -1
 
 	log.Trace().Interface("Node", value).Msg("UnmarshalYAML fileinsert")
-	if value.Tag != "!!map" {
-		return fmt.Errorf("unable to unmarshal yaml: value not map (%s)", value.Tag)
-	}
-
-	for i, node := range value.Content {
-		log.Trace().Interface("node1", node).Msg("")
-		switch node.Value {
-		// common fields
-		case "name":
-			f.Name = value.Content[i+1].Value
-		case "before":
-			for _, j := range value.Content[i+1].Content {
-				f.Before = append(f.Before, j.Value)
-			}
-		case "after":
-			for _, j := range value.Content[i+1].Content {
-				f.After = append(f.After, j.Value)
-			}
-		case "make_dir":
-			b, err := strconv.ParseBool(value.Content[i+1].Value)
-			if err != nil {
-				log.Warn().Str("key", node.Value).Str("val", value.Content[i+1].Value).Msg("failed to parse make_dir")
-			}
-			f.MakeDir = b
-		case "user":
-			f.User = value.Content[i+1].Value
-		case "group":
-			f.Group = value.Content[i+1].Value
-		case "mode":
-			// TODO
-			// f.Mode, _ = strconv.ParseInt(value.Content[i+1].Value, 10, 32)
-		case "backup":
-			b, err := strconv.ParseBool(value.Content[i+1].Value)
-			if err != nil {
-				log.Warn().Str("key", node.Value).Str("val", value.Content[i+1].Value).Msg("failed to parse backup")
-			}
-			f.Backup = b
-		case "after_line":
-			f.AfterLine = value.Content[i+1].Value
-		case "before_line":
-			f.BeforeLine = value.Content[i+1].Value
-		case "line_num":
-			f.LineNum, _ = strconv.ParseInt(value.Content[i+1].Value, 10, 64)
-		case "text":
-			f.Text = value.Content[i+1].Value
-
-			// case "":
-			// m. = value.Content[i+1].Value
-			// case "":
-			// m. = value.Content[i+1].Value
-			// case "":
-			// m. = value.Content[i+1].Value
-			// case "":
-			// m. = value.Content[i+1].Value
-		}
-	}
-	log.Trace().Interface("f", f).Msg("what's in the box?!?!")
 
+	type rawFileInsert FileInsert
+	if err := value.Decode((*rawFileInsert)(f)); err != nil {
+		return err
+	}
+	if f.LineNum != -1 && f.AfterLine != "" {
+		return fmt.Errorf("line %d: file insert: line_num and after_line can't both be set", value.Line)
+	}
+	if f.LineNum != -1 && f.LineNum < 1 {
+		return fmt.Errorf("line %d: file insert: line_num starts at 1", value.Line)
+	}
 	return nil
-
 }
 
-func (f *FileInsert) Ensure(pretend bool) error {
+// Ensure - ensure the text is inserted into the file
+func (f *FileInsert) Ensure(ctx context.Context, pretend bool) *Result {
 	fl := log.With().Str("file insert", f.Name).Logger()
 
 	fl.Debug().Interface("fileinsert", f).Msg("")
+	if f.AfterLine == "" && f.LineNum == -1 {
+		fl.Warn().Msg("file insert: no after_line or line_num specified")
+		return Failed("no after_line or line_num specified", fmt.Errorf("file insert: no after_line or line_num specified"))
+	}
+	current, err := os.ReadFile(f.Name)
+	if pretend && err != nil {
+		// another law may well create it first, there's no telling
+		fl.Info().Msg("file doesn't exist yet, would change it")
+		return Changed("file would be changed", "", f.Text)
+	}
+	newContent, r := f.insertedLines(fl)
+	if r != nil {
+		return r
+	}
 	if pretend {
 		fl.Info().Msg("would change file")
-		return nil
+		return f.changedWithDiff("file would be changed", "", f.Text, current, strings.Join(newContent, "\n")+"\n")
 	}
-	if f.AfterLine == "" && f.LineNum == -1 {
-		fl.Warn().Msg("file insert: no after_line or line_num specified")
-		return fmt.Errorf("file insert: no after_line or line_num specified")
+	if r := f.writeLines(fl, newContent); r != nil {
+		return r
 	}
+	return f.changedWithMode("text inserted into file", "", f.Text)
+}
 
-	if f.LineNum != -1 {
-		fp, err := os.Open(f.Name)
-		if err != nil {
-			fl.Error().Err(err).Str("file", f.Name).Msg("failed to open file for scanning")
-			return err
-		}
-		defer fp.Close()
-
-		scanner := bufio.NewScanner(fp)
-		var newContent []string
-		lineN := int64(1)
-		for scanner.Scan() {
-			line := scanner.Text()
-			// fl.Info().Str("line", line).Msg("")
-			if lineN == f.LineNum && line == f.Text {
-				// replacement line already exists in file
-				fl.Debug().
-					Str("name", f.Name).
-					Int64("line_num", f.LineNum).
-					Str("text", f.Text).
-					Msg("already done")
-				return nil
-			}
-			if lineN == f.LineNum {
-				// we found our line num, so we add the text to the output and then the existing line
-				newContent = append(newContent, strings.TrimRight(f.Text, "\r\n"))
-				newContent = append(newContent, strings.TrimRight(line, "\r\n"))
-			} else {
-				newContent = append(newContent, strings.TrimRight(line, "\r\n"))
-			}
-			// fl.Info().Strs("newContent", newContent).Msg("")
-			lineN++
-		}
-		fp.Close()
-
-		fw, err := os.OpenFile(f.Name, os.O_WRONLY|os.O_TRUNC, f.Mode)
-		if err != nil {
-			fl.Error().Err(err).Str("file", f.Name).Msg("failed to seek to start of file")
-		}
-		defer fw.Close()
-		_, err = fw.Write([]byte(strings.Join(newContent, "\n")))
-		if err != nil {
-			fl.Error().Err(err).Str("file", f.Name).Msg("failed to write newContent to file")
-		}
-		_, err = fw.WriteString("\n")
-		if err != nil {
-			fl.Error().Err(err).Str("file", f.Name).Msg("failed to write newline to file")
-		}
+// insertedLines - the lines of the file with the text inserted, or the
+// result if it's already there or there's nowhere to put it
+func (f *FileInsert) insertedLines(fl zerolog.Logger) ([]string, *Result) {
+	fp, err := os.Open(f.Name)
+	if err != nil {
+		fl.Error().Err(err).Str("file", f.Name).Msg("failed to open file for scanning")
+		return nil, Failed("failed to open file for scanning", err)
+	}
+	defer fp.Close()
 
+	var lines []string
+	scanner := bufio.NewScanner(fp)
+	for scanner.Scan() {
+		lines = append(lines, strings.TrimRight(scanner.Text(), "\r\n"))
 	}
-	if f.AfterLine != "" {
-		fp, err := os.Open(f.Name)
-		if err != nil {
-			fl.Error().Err(err).Str("file", f.Name).Msg("failed to open file for scanning")
-			return err
-		}
-		defer fp.Close()
+	text := strings.TrimRight(f.Text, "\r\n")
 
-		scanner := bufio.NewScanner(fp)
-		var newContent []string
-		for scanner.Scan() {
-			line := scanner.Text()
-			// fl.Info().Str("line", line).Msg("")
+	var newContent []string
+	if f.LineNum != -1 {
+		for n, line := range lines {
+			if int64(n+1) == f.LineNum {
+				if line == f.Text {
+					fl.Debug().Int64("line_num", f.LineNum).Str("text", f.Text).Msg("already done")
+					return nil, Unchanged("text already in file")
+				}
+				// the text goes before what's on the line now
+				newContent = append(newContent, text)
+			}
+			newContent = append(newContent, line)
+		}
+	} else {
+		for _, line := range lines {
 			if line == f.Text {
-				// replacement line already exists in file
-				fl.Debug().
-					Str("name", f.Name).
-					Str("after_line", f.AfterLine).
-					Str("text", f.Text).
-					Msg("already done")
-				return nil
+				fl.Debug().Str("after_line", f.AfterLine).Str("text", f.Text).Msg("already done")
+				return nil, Unchanged("text already in file")
 			}
-			// if err != nil {
-			// 	fl.Error().Err(err).Msg("failed to match")
-			// }
+			newContent = append(newContent, line)
 			if line == f.AfterLine {
-				// we found our line, so we add it and the text to the output
-				newContent = append(newContent, strings.TrimRight(line, "\r\n"))
-				newContent = append(newContent, strings.TrimRight(f.Text, "\r\n"))
-			} else {
-				newContent = append(newContent, strings.TrimRight(line, "\r\n"))
+				newContent = append(newContent, text)
 			}
-			// fl.Info().Strs("newContent", newContent).Msg("")
-		}
-		fp.Close()
-
-		fw, err := os.OpenFile(f.Name, os.O_WRONLY|os.O_TRUNC, f.Mode)
-		if err != nil {
-			fl.Error().Err(err).Str("file", f.Name).Msg("failed to seek to start of file")
-		}
-		defer fw.Close()
-		_, err = fw.Write([]byte(strings.Join(newContent, "\n")))
-		if err != nil {
-			fl.Error().Err(err).Str("file", f.Name).Msg("failed to write newContent to file")
-		}
-		_, err = fw.WriteString("\n")
-		if err != nil {
-			fl.Error().Err(err).Str("file", f.Name).Msg("failed to write newline to file")
 		}
-
 	}
-
-	return nil
+	if len(newContent) > len(lines) {
+		return newContent, nil
+	}
+	if f.LineNum != -1 {
+		msg := fmt.Sprintf("line_num %d is past the end of the file (%d lines)", f.LineNum, len(lines))
+		fl.Warn().Int64("line_num", f.LineNum).Int("lines", len(lines)).Msg("nowhere to insert the text")
+		return nil, Failed(msg, fmt.Errorf("file insert: %s", msg))
+	}
+	msg := fmt.Sprintf("after_line %q not found in file", f.AfterLine)
+	fl.Warn().Str("after_line", f.AfterLine).Msg("nowhere to insert the text")
+	return nil, Failed(msg, fmt.Errorf("file insert: %s", msg))
 }
 
 func (f *FileChange) UnmarshalYAML(value *yaml.Node) error {
-	// f.LineNum = -1
 	// we set the default to a random string that should never appear in a file
 	f.Done = "8df59722fca35a8de040c0490e7add0cab6b0751a4c0dc15a066ae174b63f274"
 
-	log.Trace().Interface("Node", value).Msg("UnmarshalYAML fileinsert")
-	if value.Tag != "!!map" {
-		return fmt.Errorf("unable to unmarshal yaml: value not map (%s)", value.Tag)
-	}
-
-	for i, node := range value.Content {
-		log.Trace().Interface("node1", node).Msg("")
-		switch node.Value {
-		// common fields
-		case "name":
-			f.Name = value.Content[i+1].Value
-		case "before":
-			for _, j := range value.Content[i+1].Content {
-				f.Before = append(f.Before, j.Value)
-			}
-		case "after":
-			for _, j := range value.Content[i+1].Content {
-				f.After = append(f.After, j.Value)
-			}
-		case "make_dir":
-			b, err := strconv.ParseBool(value.Content[i+1].Value)
-			if err != nil {
-				log.Warn().Str("key", node.Value).Str("val", value.Content[i+1].Value).Msg("failed to parse make_dir")
-			}
-			f.MakeDir = b
-		case "user":
-			f.User = value.Content[i+1].Value
-		case "group":
-			f.Group = value.Content[i+1].Value
-		case "mode":
-			// TODO
-			// f.Mode, _ = strconv.ParseInt(value.Content[i+1].Value, 10, 32)
-		case "backup":
-			b, err := strconv.ParseBool(value.Content[i+1].Value)
-			if err != nil {
-				log.Warn().Str("key", node.Value).Str("val", value.Content[i+1].Value).Msg("failed to parse backup")
-			}
-			f.Backup = b
-		case "search":
-			f.Search = value.Content[i+1].Value
-		case "replace":
-			f.Replace = value.Content[i+1].Value
-		case "done":
-			f.Done = value.Content[i+1].Value
-
-			// case "line_num":
-			// 	f.LineNum, _ = strconv.ParseInt(value.Content[i+1].Value, 10, 64)
-			// case "text":
-			// 	f.Text = value.Content[i+1].Value
-			// case "":
-			// m. = value.Content[i+1].Value
-			// case "":
-			// m. = value.Content[i+1].Value
-			// case "":
-			// m. = value.Content[i+1].Value
-			// case "":
-			// m. = value.Content[i+1].Value
-		}
-	}
-	log.Trace().Interface("f", f).Msg("what's in the box?!?!")
-
-	return nil
+	log.Trace().Interface("Node", value).Msg("UnmarshalYAML filechange")
 
+	type rawFileChange FileChange
+	return value.Decode((*rawFileChange)(f))
 }
 
 // TODO handle \r's
-func (f *FileChange) Ensure(pretend bool) error {
+// Ensure - ensure the search text is replaced in the file
+func (f *FileChange) Ensure(ctx context.Context, pretend bool) *Result {
 	fl := log.With().Str("file change", f.Name).Logger() // function logger adds some extra info
 
 	fl.Debug().Interface("filechange", f).Msg("")
+	if f.Search == "" && f.Replace == "" {
+		fl.Warn().Str("name", f.Name).Msg("failed to ensure filechange, search and replace not set")
+		return Failed("search and replace not set", fmt.Errorf("file change: search and replace not set"))
+	}
+	if _, err := os.Stat(f.Name); pretend && err != nil {
+		// another law may well create it first, there's no telling
+		fl.Info().Msg("file doesn't exist yet, would change it")
+		return Changed("file would be changed", f.Search, f.Replace)
+	}
+	newContent, r := f.changedLines(fl)
+	if r != nil {
+		return r
+	}
 	if pretend {
 		fl.Info().Msg("would change file")
-		return nil
+		current, _ := os.ReadFile(f.Name)
+		return f.changedWithDiff("file would be changed", f.Search, f.Replace, current, strings.Join(newContent, "\n")+"\n")
 	}
-	if f.Search == "" && f.Replace == "" {
-		fl.Warn().Str("name", f.Name).Msg("failed to ensure filechange, search and replace not set")
-		return nil
+	if r := f.writeLines(fl, newContent); r != nil {
+		return r
+	}
+	return f.changedWithMode("file changed", f.Search, f.Replace)
+}
+
+// writeLines - replace the file's content with some lines, nil if it worked
+func (f *fileCommon) writeLines(fl zerolog.Logger, lines []string) *Result {
+	return f.writeFile(fl, []byte(strings.Join(lines, "\n")+"\n"))
+}
+
+// writeFile - replace the file's content, nil if it worked. The content goes
+// to a temp file that's renamed over the old one, so a failed write leaves
+// the file as it was.
+func (f *fileCommon) writeFile(fl zerolog.Logger, content []byte) *Result {
+	fi, err := os.Stat(f.Name)
+	if err != nil && !errors.Is(err, fs.ErrNotExist) {
+		fl.Error().Err(err).Str("file", f.Name).Msg("failed to stat file")
+		return Failed("failed to stat file", err)
+	}
+	fw, err := os.CreateTemp(filepath.Dir(f.Name), "."+filepath.Base(f.Name)+"-*")
+	if err != nil {
+		fl.Error().Err(err).Str("file", f.Name).Msg("failed to create temp file")
+		return Failed("failed to open file for writing", err)
+	}
+	_, err = fw.Write(content)
+	if cerr := fw.Close(); err == nil {
+		err = cerr
+	}
+	// the new file keeps the old one's mode and owner, the law's own are
+	// applied after
+	perm := fs.FileMode(0o644) // new files without a mode
+	if fi != nil {
+		perm = fi.Mode().Perm()
+	} else if f.Mode != 0 {
+		perm = f.Mode.FileMode()
 	}
+	if err == nil {
+		err = os.Chmod(fw.Name(), perm)
+	}
+	if fi != nil && err == nil {
+		if st, ok := fi.Sys().(*syscall.Stat_t); ok {
+			err = os.Chown(fw.Name(), int(st.Uid), int(st.Gid))
+		}
+	}
+	if err == nil {
+		err = os.Rename(fw.Name(), f.Name)
+	}
+	if err != nil {
+		os.Remove(fw.Name())
+		fl.Error().Err(err).Str("file", f.Name).Msg("failed to write file")
+		return Failed("failed to write file", err)
+	}
+	return nil
+}
+
+// changedLines - the lines of the file with the change made, or the result
+// if there's nothing to change
+func (f *FileChange) changedLines(fl zerolog.Logger) ([]string, *Result) {
 	fp, err := os.Open(f.Name)
 	if err != nil {
 		fl.Error().Err(err).Str("file", f.Name).Msg("failed to open file for scanning")
-		return err
+		return nil, Failed("failed to open file for scanning", err)
 	}
 	defer fp.Close()
 
 	scanner := bufio.NewScanner(fp)
 	var newContent []string
+	matched := false
 	for scanner.Scan() {
 		line := scanner.Text()
 		// fl.Info().Str("line", line).Msg("")
@@ -568,13 +523,14 @@ func (f *FileChange) Ensure(pretend bool) error {
 				Str("search", f.Search).
 				Str("replace", f.Replace).
 				Msg("already done")
-			return nil
+			return nil, Unchanged("already done")
 		}
 		match, err := regexp.MatchString(f.Search, line)
 		if err != nil {
 			fl.Error().Err(err).Msg("failed to match")
 		}
 		if match {
+			matched = true
 			rgx := regexp.MustCompile(f.Search)
 			repl := rgx.ReplaceAllString(line, f.Replace)
 			newContent = append(newContent, strings.TrimRight(repl, "\r\n"))
@@ -585,21 +541,10 @@ func (f *FileChange) Ensure(pretend bool) error {
 	}
 	fp.Close()
 
-	fw, err := os.OpenFile(f.Name, os.O_WRONLY|os.O_TRUNC, f.Mode)
-	if err != nil {
-		fl.Error().Err(err).Str("file", f.Name).Msg("failed to seek to start of file")
-	}
-	defer fw.Close()
-	_, err = fw.Write([]byte(strings.Join(newContent, "\n")))
-	if err != nil {
-		fl.Error().Err(err).Str("file", f.Name).Msg("failed to write newContent to file")
-	}
-	_, err = fw.WriteString("\n")
-	if err != nil {
-		fl.Error().Err(err).Str("file", f.Name).Msg("failed to write newline to file")
+	if !matched {
+		return nil, Unchanged("search text not found")
 	}
-
-	return nil
+	return newContent, nil
 }
 
 func (f *FileLink) UnmarshalYAML(value *yaml.Node) error {
@@ -613,16 +558,22 @@ func (f *FileLink) UnmarshalYAML(value *yaml.Node) error {
 	return nil
 }
 
-func (f *FileLink) Ensure(pretend bool) error {
+// Ensure - ensure the link exists and points at the target
+func (f *FileLink) Ensure(ctx context.Context, pretend bool) *Result {
 	fl := log.With().Str("file link", f.Name).Logger() // function logger adds some extra info
+	current, _ := os.Readlink(f.Name)
+	if current == f.Target {
+		return Unchanged("link already exists")
+	}
 	if pretend {
 		fl.Info().Str("target", f.Target).Msg("would link")
-	} else {
-		// TODO check if target exists and is a symlink
-		err := os.Symlink(f.Target, f.Name)
-		if err != nil {
-			fl.Error().Err(err).Str("target", f.Target).Msg("failed to symlink")
-		}
+		return Changed("link would be created", current, f.Target)
 	}
-	return nil
+	// TODO check if target exists and is a symlink
+	err := os.Symlink(f.Target, f.Name)
+	if err != nil {
+		fl.Error().Err(err).Str("target", f.Target).Msg("failed to symlink")
+		return Failed("failed to symlink", err)
+	}
+	return Changed("link created", current, f.Target)
 }
@@ -0,0 +1,558 @@
+// Copyright © 2026 Iggy <iggy@theiggy.com>
+// All rights reserved.
+//
+// Redistribution and use in source and binary forms, with or without
+// modification, are permitted provided that the following conditions are met:
+//
+// 1. Redistributions of source code must retain the above copyright notice,
+//    this list of conditions and the following disclaimer.
+//
+// 2. Redistributions in binary form must reproduce the above copyright notice,
+//    this list of conditions and the following disclaimer in the documentation
+//    and/or other materials provided with the distribution.
+//
+// 3. Neither the name of the copyright holder nor the names of its contributors
+//    may be used to endorse or promote products derived from this software
+//    without specific prior written permission.
+//
+// THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS "AS IS"
+// AND ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT LIMITED TO, THE
+// IMPLIED WARRANTIES OF MERCHANTABILITY AND FITNESS FOR A PARTICULAR PURPOSE
+// ARE DISCLAIMED. IN NO EVENT SHALL THE COPYRIGHT HOLDER OR CONTRIBUTORS BE
+// LIABLE FOR ANY DIRECT, INDIRECT, INCIDENTAL, SPECIAL, EXEMPLARY, OR
+// CONSEQUENTIAL DAMAGES (INCLUDING, BUT NOT LIMITED TO, PROCUREMENT OF
+// SUBSTITUTE GOODS OR SERVICES; LOSS OF USE, DATA, OR PROFITS; OR BUSINESS
+// INTERRUPTION) HOWEVER CAUSED AND ON ANY THEORY OF LIABILITY, WHETHER IN
+// CONTRACT, STRICT LIABILITY, OR TORT (INCLUDING NEGLIGENCE OR OTHERWISE)
+// ARISING IN ANY WAY OUT OF THE USE OF THIS SOFTWARE, EVEN IF ADVISED OF THE
+// POSSIBILITY OF SUCH DAMAGE.
+
+package laws
+
+import (
+	"bytes"
+	"crypto/rand"
+	"crypto/sha512"
+	"crypto/subtle"
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"os/user"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"text/template"
+
+	"github.com/Masterminds/sprig/v3"
+	"github.com/iggy/govern/pkg/facts"
+)
+
+// templateFuncs - the functions laws templates and file templates get, on
+// top of sprig
+func templateFuncs(src *lawsSource) template.FuncMap {
+	funcMap := sprig.TxtFuncMap()
+	funcMap["secret"] = secretFunc(src.dir(), src.lint)
+	funcMap["fact"] = fact
+	funcMap["fileContents"] = fileContents
+	funcMap["fileExists"] = fileExists
+	funcMap["lookupUser"] = lookupUser
+	funcMap["lookupGroup"] = lookupGroup
+	funcMap["cidrHost"] = cidrHost
+	funcMap["interfaceIP"] = interfaceIP
+	funcMap["hashPassword"] = hashPassword
+	funcMap["toIni"] = toIni
+	funcMap["toToml"] = toToml
+	return funcMap
+}
+
+// fact - a fact by its dotted path, i.e. fact "Distro.Family"
+func fact(path string) (interface{}, error) {
+	v := reflect.ValueOf(facts.Facts)
+	for _, part := range strings.Split(path, ".") {
+		for v.Kind() == reflect.Pointer || v.Kind() == reflect.Interface {
+			v = v.Elem()
+		}
+		switch v.Kind() {
+		case reflect.Struct:
+			f := v.FieldByNameFunc(func(name string) bool { return strings.EqualFold(name, part) })
+			if !f.IsValid() {
+				return nil, fmt.Errorf("fact: no fact %s", path)
+			}
+			v = f
+		case reflect.Map:
+			f := v.MapIndex(reflect.ValueOf(part))
+			if !f.IsValid() {
+				return nil, fmt.Errorf("fact: no fact %s", path)
+			}
+			v = f
+		case reflect.Slice, reflect.Array:
+			i, err := strconv.Atoi(part)
+			if err != nil || i < 0 || i >= v.Len() {
+				return nil, fmt.Errorf("fact: no fact %s", path)
+			}
+			v = v.Index(i)
+		default:
+			return nil, fmt.Errorf("fact: no fact %s", path)
+		}
+	}
+	return v.Interface(), nil
+}
+
+// fileContents - the contents of a file on the host
+func fileContents(path string) (string, error) {
+	content, err := os.ReadFile(path)
+	return string(content), err
+}
+
+// fileExists - whether a file exists on the host
+func fileExists(path string) bool {
+	_, err := os.Stat(path)
+	return err == nil
+}
+
+// lookupUser - a user on the host, nil if there isn't one so it can be used
+// with {{ with }}
+func lookupUser(name string) (*user.User, error) {
+	u, err := user.Lookup(name)
+	if errors.As(err, new(user.UnknownUserError)) {
+		return nil, nil
+	}
+	return u, err
+}
+
+// lookupGroup - a group on the host, nil if there isn't one
+func lookupGroup(name string) (*user.Group, error) {
+	g, err := user.LookupGroup(name)
+	if errors.As(err, new(user.UnknownGroupError)) {
+		return nil, nil
+	}
+	return g, err
+}
+
+// interfaceAddrs - the addresses on an interface, or every interface
+func interfaceAddrs(name string) ([]*net.IPNet, error) {
+	var addrs []*net.IPNet
+	for _, iface := range facts.Facts.Network.Interfaces {
+		if name != "" && iface.Name != name {
+			continue
+		}
+		ifaceAddrs, err := iface.Addrs()
+		if err != nil {
+			return nil, err
+		}
+		for _, a := range ifaceAddrs {
+			if ipnet, ok := a.(*net.IPNet); ok {
+				addrs = append(addrs, ipnet)
+			}
+		}
+	}
+	return addrs, nil
+}
+
+// cidrHost - this host's address in a network, i.e. cidrHost "10.0.0.0/8",
+// empty if it doesn't have one
+func cidrHost(cidr string) (string, error) {
+	_, network, err := net.ParseCIDR(cidr)
+	if err != nil {
+		return "", fmt.Errorf("cidrHost: %w", err)
+	}
+	addrs, err := interfaceAddrs("")
+	if err != nil {
+		return "", fmt.Errorf("cidrHost: %w", err)
+	}
+	for _, a := range addrs {
+		if network.Contains(a.IP) {
+			return a.IP.String(), nil
+		}
+	}
+	return "", nil
+}
+
+// interfaceIP - the first address on an interface, ipv4 if it has one
+func interfaceIP(name string) (string, error) {
+	addrs, err := interfaceAddrs(name)
+	if err != nil {
+		return "", fmt.Errorf("interfaceIP: %w", err)
+	}
+	if len(addrs) == 0 {
+		return "", fmt.Errorf("interfaceIP: no addresses on %s", name)
+	}
+	for _, a := range addrs {
+		if a.IP.To4() != nil {
+			return a.IP.String(), nil
+		}
+	}
+	return addrs[0].IP.String(), nil
+}
+
+const cryptAlphabet = "./0123456789ABCDEFGHIJKLMNOPQRSTUVWXYZabcdefghijklmnopqrstuvwxyz"
+
+// hashPassword - a $6$ SHA-512 crypt hash for /etc/shadow (and users
+// password). The salt is either a plain salt or a $6$ setting, which can
+// have rounds= (an existing hash works too). Without one, a hash already in
+// /etc/shadow that the password matches is reused, so the hash doesn't
+// change from run to run (which would change the password every time), or
+// else the salt is random.
+func hashPassword(password string, salt ...string) (string, error) {
+	switch len(salt) {
+	case 0:
+		if hash, ok := shadowHash(password); ok {
+			return hash, nil
+		}
+		s, err := randomSalt()
+		if err != nil {
+			return "", fmt.Errorf("hashPassword: %w", err)
+		}
+		return cryptPassword(password, s)
+	case 1:
+		return cryptPassword(password, salt[0])
+	default:
+		return "", errors.New("hashPassword: too many arguments")
+	}
+}
+
+// cryptPassword - the $6$ hash of a password with a plain salt or a $6$
+// setting
+func cryptPassword(password, setting string) (string, error) {
+	rounds, prefix := 5000, "$6$"
+	s, ok := strings.CutPrefix(setting, "$6$")
+	if ok {
+		if r, ok := strings.CutPrefix(s, "rounds="); ok {
+			n, rest, found := strings.Cut(r, "$")
+			var err error
+			rounds, err = strconv.Atoi(n)
+			if !found || err != nil {
+				return "", fmt.Errorf("hashPassword: bad rounds in %q", setting)
+			}
+			rounds = min(max(rounds, 1000), 999999999)
+			prefix += fmt.Sprintf("rounds=%d$", rounds)
+			s = rest
+		}
+		// drop the hash if it's a whole one
+		s, _, _ = strings.Cut(s, "$")
+	}
+	if len(s) > 16 {
+		s = s[:16]
+	}
+	if strings.ContainsAny(s, "$:\n") {
+		return "", fmt.Errorf("hashPassword: bad salt %q", s)
+	}
+	return prefix + s + "$" + sha512Crypt([]byte(password), []byte(s), rounds), nil
+}
+
+// checkPassword - whether a $6$ hash is of the password
+func checkPassword(password, hash string) bool {
+	if !strings.HasPrefix(hash, "$6$") {
+		return false
+	}
+	h, err := cryptPassword(password, hash)
+	return err == nil && subtle.ConstantTimeCompare([]byte(h), []byte(hash)) == 1
+}
+
+// shadowHash - a $6$ hash in /etc/shadow that the password matches
+func shadowHash(password string) (string, bool) {
+	shadow, err := os.ReadFile("/etc/shadow")
+	if err != nil {
+		return "", false
+	}
+	for _, line := range strings.Split(string(shadow), "\n") {
+		fields := strings.Split(line, ":")
+		if len(fields) > 1 && checkPassword(password, fields[1]) {
+			return fields[1], true
+		}
+	}
+	return "", false
+}
+
+// randomSalt - 16 random characters of the crypt alphabet
+func randomSalt() (string, error) {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	for i := range b {
+		// 64 characters, so every byte value maps evenly
+		b[i] = cryptAlphabet[int(b[i])%len(cryptAlphabet)]
+	}
+	return string(b), nil
+}
+
+// sha512Crypt - the SHA-512 crypt algorithm from glibc
+// (https://www.akkadia.org/drepper/SHA-crypt.txt)
+func sha512Crypt(password, salt []byte, rounds int) string {
+	b := sha512.New()
+	b.Write(password)
+	b.Write(salt)
+	b.Write(password)
+	digestB := b.Sum(nil)
+
+	a := sha512.New()
+	a.Write(password)
+	a.Write(salt)
+	cnt := len(password)
+	for ; cnt > 64; cnt -= 64 {
+		a.Write(digestB)
+	}
+	a.Write(digestB[:cnt])
+	for cnt = len(password); cnt > 0; cnt >>= 1 {
+		if cnt&1 != 0 {
+			a.Write(digestB)
+		} else {
+			a.Write(password)
+		}
+	}
+	digestA := a.Sum(nil)
+
+	dp := sha512.New()
+	for range password {
+		dp.Write(password)
+	}
+	pSeq := bytes.Repeat(dp.Sum(nil), len(password)/64+1)[:len(password)]
+
+	ds := sha512.New()
+	for i := 0; i < 16+int(digestA[0]); i++ {
+		ds.Write(salt)
+	}
+	sSeq := ds.Sum(nil)[:len(salt)]
+
+	c := digestA
+	for i := 0; i < rounds; i++ {
+		h := sha512.New()
+		if i&1 != 0 {
+			h.Write(pSeq)
+		} else {
+			h.Write(c)
+		}
+		if i%3 != 0 {
+			h.Write(sSeq)
+		}
+		if i%7 != 0 {
+			h.Write(pSeq)
+		}
+		if i&1 != 0 {
+			h.Write(c)
+		} else {
+			h.Write(pSeq)
+		}
+		c = h.Sum(nil)
+	}
+
+	// the bytes get shuffled before being encoded
+	order := [][3]int{
+		{0, 21, 42}, {22, 43, 1}, {44, 2, 23}, {3, 24, 45}, {25, 46, 4},
+		{47, 5, 26}, {6, 27, 48}, {28, 49, 7}, {50, 8, 29}, {9, 30, 51},
+		{31, 52, 10}, {53, 11, 32}, {12, 33, 54}, {34, 55, 13}, {56, 14, 35},
+		{15, 36, 57}, {37, 58, 16}, {59, 17, 38}, {18, 39, 60}, {40, 61, 19},
+		{62, 20, 41},
+	}
+	var out strings.Builder
+	encode := func(w uint, n int) {
+		for ; n > 0; n-- {
+			out.WriteByte(cryptAlphabet[w&0x3f])
+			w >>= 6
+		}
+	}
+	for _, o := range order {
+		encode(uint(c[o[0]])<<16|uint(c[o[1]])<<8|uint(c[o[2]]), 4)
+	}
+	encode(uint(c[63]), 2)
+	return out.String()
+}
+
+// sortedKeys - the keys of a map in order
+func sortedKeys(m map[string]interface{}) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// toIni - a map as an ini file, maps become [sections] and everything else
+// is a key = value
+func toIni(m map[string]interface{}) string {
+	var b strings.Builder
+	var sections []string
+	for _, k := range sortedKeys(m) {
+		if _, ok := m[k].(map[string]interface{}); ok {
+			sections = append(sections, k)
+			continue
+		}
+		fmt.Fprintf(&b, "%s = %s\n", k, iniValue(m[k]))
+	}
+	for _, section := range sections {
+		if b.Len() > 0 {
+			b.WriteString("\n")
+		}
+		fmt.Fprintf(&b, "[%s]\n", section)
+		values := m[section].(map[string]interface{})
+		for _, k := range sortedKeys(values) {
+			fmt.Fprintf(&b, "%s = %s\n", k, iniValue(values[k]))
+		}
+	}
+	return b.String()
+}
+
+// iniValue - ini has no lists, they're written comma separated
+func iniValue(v interface{}) string {
+	if list, ok := v.([]interface{}); ok {
+		parts := make([]string, 0, len(list))
+		for _, item := range list {
+			parts = append(parts, fmt.Sprint(item))
+		}
+		return strings.Join(parts, ",")
+	}
+	return fmt.Sprint(v)
+}
+
+// toToml - a map as a toml document
+func toToml(m map[string]interface{}) (string, error) {
+	var b strings.Builder
+	err := writeToml(&b, m, "")
+	return b.String(), err
+}
+
+// writeToml - write the values of a table, then its sub-tables
+func writeToml(b *strings.Builder, m map[string]interface{}, prefix string) error {
+	var tables, tableArrays []string
+	for _, k := range sortedKeys(m) {
+		switch v := m[k].(type) {
+		case map[string]interface{}:
+			tables = append(tables, k)
+			continue
+		case []interface{}:
+			if len(v) > 0 && isTables(v) {
+				tableArrays = append(tableArrays, k)
+				continue
+			}
+		}
+		value, err := tomlValue(m[k])
+		if err != nil {
+			return fmt.Errorf("toToml: %s: %w", prefix+k, err)
+		}
+		fmt.Fprintf(b, "%s = %s\n", tomlKey(k), value)
+	}
+	for _, k := range tables {
+		name := prefix + tomlKey(k)
+		if b.Len() > 0 {
+			b.WriteString("\n")
+		}
+		fmt.Fprintf(b, "[%s]\n", name)
+		err := writeToml(b, m[k].(map[string]interface{}), name+".")
+		if err != nil {
+			return err
+		}
+	}
+	for _, k := range tableArrays {
+		name := prefix + tomlKey(k)
+		for _, t := range m[k].([]interface{}) {
+			if b.Len() > 0 {
+				b.WriteString("\n")
+			}
+			fmt.Fprintf(b, "[[%s]]\n", name)
+			err := writeToml(b, t.(map[string]interface{}), name+".")
+			if err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// isTables - a list of maps is written as an array of tables
+func isTables(list []interface{}) bool {
+	for _, v := range list {
+		if _, ok := v.(map[string]interface{}); !ok {
+			return false
+		}
+	}
+	return true
+}
+
+// tomlKey - bare keys if they can be, quoted if not
+func tomlKey(k string) string {
+	for _, r := range k {
+		if !(r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r >= '0' && r <= '9' || r == '-' || r == '_') {
+			return tomlString(k)
+		}
+	}
+	if k == "" {
+		return `""`
+	}
+	return k
+}
+
+// tomlString - a basic toml string, which escapes like json
+func tomlString(s string) string {
+	var b strings.Builder
+	b.WriteByte('"')
+	for _, r := range s {
+		switch {
+		case r == '"' || r == '\\':
+			b.WriteByte('\\')
+			b.WriteRune(r)
+		case r == '\n':
+			b.WriteString(`\n`)
+		case r == '\t':
+			b.WriteString(`\t`)
+		case r == '\r':
+			b.WriteString(`\r`)
+		case r < 0x20 || r == 0x7f:
+			fmt.Fprintf(&b, `\u%04X`, r)
+		default:
+			b.WriteRune(r)
+		}
+	}
+	b.WriteByte('"')
+	return b.String()
+}
+
+// tomlValue - a value that goes after the =
+func tomlValue(v interface{}) (string, error) {
+	switch v := v.(type) {
+	case nil:
+		return "", errors.New("toml has no null")
+	case string:
+		return tomlString(v), nil
+	case bool:
+		return strconv.FormatBool(v), nil
+	case int, int8, int16, int32, int64, uint, uint8, uint16, uint32, uint64:
+		return fmt.Sprintf("%d", v), nil
+	case float32, float64:
+		f := strconv.FormatFloat(reflect.ValueOf(v).Float(), 'g', -1, 64)
+		if !strings.ContainsAny(f, ".eIN") {
+			f += ".0" // otherwise it'd be read back as an int
+		}
+		return f, nil
+	case []interface{}:
+		parts := make([]string, 0, len(v))
+		for _, item := range v {
+			s, err := tomlValue(item)
+			if err != nil {
+				return "", err
+			}
+			parts = append(parts, s)
+		}
+		return "[" + strings.Join(parts, ", ") + "]", nil
+	case []string:
+		parts := make([]string, 0, len(v))
+		for _, item := range v {
+			parts = append(parts, tomlString(item))
+		}
+		return "[" + strings.Join(parts, ", ") + "]", nil
+	case map[string]interface{}:
+		parts := make([]string, 0, len(v))
+		for _, k := range sortedKeys(v) {
+			s, err := tomlValue(v[k])
+			if err != nil {
+				return "", err
+			}
+			parts = append(parts, tomlKey(k)+" = "+s)
+		}
+		return "{" + strings.Join(parts, ", ") + "}", nil
+	}
+	return tomlString(fmt.Sprint(v)), nil
+}
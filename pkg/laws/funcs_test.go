@@ -0,0 +1,132 @@
+// Copyright © 2026 Iggy <iggy@theiggy.com>
+// All rights reserved.
+//
+// Redistribution and use in source and binary forms, with or without
+// modification, are permitted provided that the following conditions are met:
+//
+// 1. Redistributions of source code must retain the above copyright notice,
+//    this list of conditions and the following disclaimer.
+//
+// 2. Redistributions in binary form must reproduce the above copyright notice,
+//    this list of conditions and the following disclaimer in the documentation
+//    and/or other materials provided with the distribution.
+//
+// 3. Neither the name of the copyright holder nor the names of its contributors
+//    may be used to endorse or promote products derived from this software
+//    without specific prior written permission.
+//
+// THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS "AS IS"
+// AND ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT LIMITED TO, THE
+// IMPLIED WARRANTIES OF MERCHANTABILITY AND FITNESS FOR A PARTICULAR PURPOSE
+// ARE DISCLAIMED. IN NO EVENT SHALL THE COPYRIGHT HOLDER OR CONTRIBUTORS BE
+// LIABLE FOR ANY DIRECT, INDIRECT, INCIDENTAL, SPECIAL, EXEMPLARY, OR
+// CONSEQUENTIAL DAMAGES (INCLUDING, BUT NOT LIMITED TO, PROCUREMENT OF
+// SUBSTITUTE GOODS OR SERVICES; LOSS OF USE, DATA, OR PROFITS; OR BUSINESS
+// INTERRUPTION) HOWEVER CAUSED AND ON ANY THEORY OF LIABILITY, WHETHER IN
+// CONTRACT, STRICT LIABILITY, OR TORT (INCLUDING NEGLIGENCE OR OTHERWISE)
+// ARISING IN ANY WAY OUT OF THE USE OF THIS SOFTWARE, EVEN IF ADVISED OF THE
+// POSSIBILITY OF SUCH DAMAGE.
+
+package laws
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestCryptPassword(t *testing.T) {
+	// the test vectors from https://www.akkadia.org/drepper/SHA-crypt.txt
+	tests := []struct {
+		setting, password, want string
+	}{
+		{
+			"$6$saltstring", "Hello world!",
+			"$6$saltstring$svn8UoSVapNtMuq1ukKS4tPQd8iKwSMHWjl/O817G3uBnIFNjnQJuesI68u4OTLiBFdcbYEdFCoEOfaS35inz1",
+		},
+		{
+			"$6$rounds=10000$saltstringsaltstring", "Hello world!",
+			"$6$rounds=10000$saltstringsaltst$OW1/O6BYHV6BcXZu8QVeXbDWra3Oeqh0sbHbbMCVNSnCM/UrjmM0Dp8vOuZeHBy/YTBmSK6H9qs/y3RnOaw5v.",
+		},
+		{
+			"$6$rounds=5000$toolongsaltstring", "This is just a test",
+			"$6$rounds=5000$toolongsaltstrin$lQ8jolhgVRVhY4b5pZKaysCLi0QBxGoNeKQzQ3glMhwllF7oGDZxUhx1yxdYcz/e1JSbq3y6JMxxl8audkUEm0",
+		},
+		{
+			"$6$rounds=1400$anotherlongsaltstring", "a very much longer text to encrypt.  This one even stretches over morethan one line.",
+			"$6$rounds=1400$anotherlongsalts$POfYwTEok97VWcjxIiSOjiykti.o/pQs.wPvMxQ6Fm7I6IoYN3CmLs66x9t0oSwbtEW7o7UmJEiDwGqd8p4ur1",
+		},
+		{
+			"$6$rounds=77777$short", "we have a short salt string but not a short password",
+			"$6$rounds=77777$short$WuQyW2YR.hBNpjjRhpYD/ifIw05xdfeEyQoMxIXbkvr0gge1a1x3yRULJ5CCaUeOxFmtlcGZelFl5CxtgfiAc0",
+		},
+		{
+			"$6$rounds=123456$asaltof16chars..", "a short string",
+			"$6$rounds=123456$asaltof16chars..$BtCwjqMJGx5hrJhZywWvt0RLE8uZ4oPwcelCjmw2kSYu.Ec6ycULevoBK25fs2xXgMNrCzIMVcgEJAstJeonj1",
+		},
+		{
+			"$6$rounds=10$roundstoolow", "the minimum number is still observed",
+			"$6$rounds=1000$roundstoolow$kUMsbe306n21p9R.FRkW3IGn.S9NPN0x50YhH1xhLsPuWGsUSklZt58jaTfF4ZEQpyUNGc0dqbpBYYBaHHrsX.",
+		},
+		// a plain salt, and a whole hash as the setting
+		{
+			"saltstring", "Hello world!",
+			"$6$saltstring$svn8UoSVapNtMuq1ukKS4tPQd8iKwSMHWjl/O817G3uBnIFNjnQJuesI68u4OTLiBFdcbYEdFCoEOfaS35inz1",
+		},
+		{
+			"toolongsaltstring", "This is just a test",
+			"$6$toolongsaltstrin$" + sha512Crypt([]byte("This is just a test"), []byte("toolongsaltstrin"), 5000),
+		},
+		{
+			"$6$rounds=77777$short$WuQyW2YR.hBNpjjRhpYD/ifIw05xdfeEyQoMxIXbkvr0gge1a1x3yRULJ5CCaUeOxFmtlcGZelFl5CxtgfiAc0",
+			"we have a short salt string but not a short password",
+			"$6$rounds=77777$short$WuQyW2YR.hBNpjjRhpYD/ifIw05xdfeEyQoMxIXbkvr0gge1a1x3yRULJ5CCaUeOxFmtlcGZelFl5CxtgfiAc0",
+		},
+	}
+	for _, tt := range tests {
+		got, err := hashPassword(tt.password, tt.setting)
+		if err != nil {
+			t.Errorf("hashPassword(%q, %q): %v", tt.password, tt.setting, err)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("hashPassword(%q, %q) = %s, want %s", tt.password, tt.setting, got, tt.want)
+		}
+	}
+}
+
+func TestCryptPasswordBadSetting(t *testing.T) {
+	for _, setting := range []string{"salt$string", "salt:string", "$6$rounds=lots$salt", "$6$rounds=5000"} {
+		if got, err := hashPassword("password", setting); err == nil {
+			t.Errorf("hashPassword with %q = %s, want an error", setting, got)
+		}
+	}
+	if _, err := hashPassword("password", "a", "b"); err == nil {
+		t.Error("hashPassword with two salts, want an error")
+	}
+}
+
+func TestHashPasswordRandomSalt(t *testing.T) {
+	first, err := hashPassword("correct horse battery staple")
+	if err != nil {
+		t.Fatal(err)
+	}
+	second, err := hashPassword("correct horse battery staple")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if first == second {
+		t.Errorf("two hashes without a salt are the same: %s", first)
+	}
+	for _, hash := range []string{first, second} {
+		salt := strings.Split(hash, "$")[2]
+		if len(salt) != 16 || strings.Trim(salt, cryptAlphabet) != "" {
+			t.Errorf("bad random salt %q", salt)
+		}
+		if !checkPassword("correct horse battery staple", hash) {
+			t.Errorf("%s doesn't check against its password", hash)
+		}
+		if checkPassword("Correct horse battery staple", hash) {
+			t.Errorf("%s checks against the wrong password", hash)
+		}
+	}
+}
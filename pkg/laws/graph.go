@@ -0,0 +1,308 @@
+// Copyright © 2026 Iggy <iggy@theiggy.com>
+// All rights reserved.
+//
+// Redistribution and use in source and binary forms, with or without
+// modification, are permitted provided that the following conditions are met:
+//
+// 1. Redistributions of source code must retain the above copyright notice,
+//    this list of conditions and the following disclaimer.
+//
+// 2. Redistributions in binary form must reproduce the above copyright notice,
+//    this list of conditions and the following disclaimer in the documentation
+//    and/or other materials provided with the distribution.
+//
+// 3. Neither the name of the copyright holder nor the names of its contributors
+//    may be used to endorse or promote products derived from this software
+//    without specific prior written permission.
+//
+// THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS "AS IS"
+// AND ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT LIMITED TO, THE
+// IMPLIED WARRANTIES OF MERCHANTABILITY AND FITNESS FOR A PARTICULAR PURPOSE
+// ARE DISCLAIMED. IN NO EVENT SHALL THE COPYRIGHT HOLDER OR CONTRIBUTORS BE
+// LIABLE FOR ANY DIRECT, INDIRECT, INCIDENTAL, SPECIAL, EXEMPLARY, OR
+// CONSEQUENTIAL DAMAGES (INCLUDING, BUT NOT LIMITED TO, PROCUREMENT OF
+// SUBSTITUTE GOODS OR SERVICES; LOSS OF USE, DATA, OR PROFITS; OR BUSINESS
+// INTERRUPTION) HOWEVER CAUSED AND ON ANY THEORY OF LIABILITY, WHETHER IN
+// CONTRACT, STRICT LIABILITY, OR TORT (INCLUDING NEGLIGENCE OR OTHERWISE)
+// ARISING IN ANY WAY OUT OF THE USE OF THIS SOFTWARE, EVEN IF ADVISED OF THE
+// POSSIBILITY OF SUCH DAMAGE.
+
+package laws
+
+import (
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/hmdsefi/gograph"
+)
+
+// GraphFormats - the formats WriteGraph understands
+var GraphFormats = []string{"text", "dot", "mermaid"}
+
+// LawGraph - the dependency graph from ParseFiles in a form that's easy to
+// walk and draw
+type LawGraph struct {
+	Nodes    []*LawNode // in the order they'll be ensured, root first
+	children map[*LawNode][]*LawNode
+	parents  map[*LawNode][]*LawNode
+
+	highlight     map[*LawNode]bool
+	highlightEdge map[[2]*LawNode]bool
+}
+
+// NewLawGraph - build a LawGraph from the sorted vertices from ParseFiles
+func NewLawGraph(sorted []*gograph.Vertex[*LawNode]) *LawGraph {
+	g := &LawGraph{
+		children:      map[*LawNode][]*LawNode{},
+		parents:       map[*LawNode][]*LawNode{},
+		highlight:     map[*LawNode]bool{},
+		highlightEdge: map[[2]*LawNode]bool{},
+	}
+	// Neighbors returns copies of the vertices, so use the labels
+	for _, v := range sorted {
+		g.Nodes = append(g.Nodes, v.Label())
+		for _, child := range v.Neighbors() {
+			g.children[v.Label()] = append(g.children[v.Label()], child.Label())
+			g.parents[child.Label()] = append(g.parents[child.Label()], v.Label())
+		}
+	}
+	return g
+}
+
+// Find - the law with a group::type::name id
+func (g *LawGraph) Find(id string) *LawNode {
+	id = strings.ToLower(id)
+	for _, n := range g.Nodes {
+		if n.ID() == id {
+			return n
+		}
+	}
+	return nil
+}
+
+// isRoot - the synthetic root vertex everything starts from
+func isRoot(n *LawNode) bool {
+	_, ok := n.Law.(*Root)
+	return ok
+}
+
+// Unattached - the law has no ordering relations at all: nothing it comes
+// after and nothing that comes after it, so it only hangs off of the root.
+// A law that others come after hangs off of the root too, but isn't
+// unattached.
+func (g *LawGraph) Unattached(n *LawNode) bool {
+	if isRoot(n) || len(g.children[n]) > 0 {
+		return false
+	}
+	for _, p := range g.parents[n] {
+		if !isRoot(p) {
+			return false
+		}
+	}
+	return true
+}
+
+// Highlight - mark the path between two laws, in whichever direction it
+// goes. Returns an error if there isn't one.
+func (g *LawGraph) Highlight(from, to string) error {
+	a, b := g.Find(from), g.Find(to)
+	if a == nil {
+		return fmt.Errorf("no law %s", from)
+	}
+	if b == nil {
+		return fmt.Errorf("no law %s", to)
+	}
+	path := g.path(a, b)
+	if path == nil {
+		path = g.path(b, a)
+	}
+	if path == nil {
+		return fmt.Errorf("no path between %s and %s", a.ID(), b.ID())
+	}
+	for i, n := range path {
+		g.highlight[n] = true
+		if i > 0 {
+			g.highlightEdge[[2]*LawNode{path[i-1], n}] = true
+		}
+	}
+	return nil
+}
+
+// path - shortest path from one law to another following the edges
+func (g *LawGraph) path(from, to *LawNode) []*LawNode {
+	prev := map[*LawNode]*LawNode{from: nil}
+	queue := []*LawNode{from}
+	for len(queue) > 0 {
+		n := queue[0]
+		queue = queue[1:]
+		if n == to {
+			var path []*LawNode
+			for ; n != nil; n = prev[n] {
+				path = append([]*LawNode{n}, path...)
+			}
+			return path
+		}
+		for _, c := range g.children[n] {
+			if _, seen := prev[c]; !seen {
+				prev[c] = n
+				queue = append(queue, c)
+			}
+		}
+	}
+	return nil
+}
+
+// Write - draw the graph in one of the GraphFormats
+func (g *LawGraph) Write(w io.Writer, format string) error {
+	switch format {
+	case "text":
+		return g.writeText(w)
+	case "dot":
+		return g.writeDOT(w)
+	case "mermaid":
+		return g.writeMermaid(w)
+	}
+	return fmt.Errorf("unknown graph format %q, expected one of %s", format, strings.Join(GraphFormats, ", "))
+}
+
+// writeText - a tree like the tree command, laws with more than one parent
+// are only expanded the first time they show up
+func (g *LawGraph) writeText(w io.Writer) error {
+	var b strings.Builder
+	seen := map[*LawNode]bool{}
+	var walk func(n *LawNode, prefix string, last bool, top bool)
+	walk = func(n *LawNode, prefix string, last bool, top bool) {
+		line, childPrefix := "", ""
+		if !top {
+			line, childPrefix = prefix+"├── ", prefix+"│   "
+			if last {
+				line, childPrefix = prefix+"└── ", prefix+"    "
+			}
+		}
+		line += n.ID()
+		if n.File != "" {
+			line += " (" + n.File + ")"
+		}
+		if g.Unattached(n) {
+			line += " [unattached]"
+		}
+		if g.highlight[n] {
+			line += " <=="
+		}
+		if seen[n] && len(g.children[n]) > 0 {
+			line += " ..."
+		}
+		b.WriteString(line + "\n")
+		if seen[n] {
+			return
+		}
+		seen[n] = true
+		for i, c := range g.children[n] {
+			walk(c, childPrefix, i == len(g.children[n])-1, false)
+		}
+	}
+	for _, n := range g.Nodes {
+		if isRoot(n) {
+			walk(n, "", true, true)
+		}
+	}
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+// dotQuote - quote a string for graphviz
+func dotQuote(s string) string {
+	return `"` + strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`).Replace(s) + `"`
+}
+
+func (g *LawGraph) writeDOT(w io.Writer) error {
+	var b strings.Builder
+	b.WriteString("digraph laws {\n")
+	b.WriteString("  rankdir=LR;\n")
+	b.WriteString("  node [shape=box];\n")
+	for _, n := range g.Nodes {
+		label := n.ID()
+		if n.File != "" {
+			label += "\n" + n.File
+		}
+		var attrs []string
+		attrs = append(attrs, "label="+dotQuote(label))
+		if isRoot(n) {
+			attrs = append(attrs, "shape=ellipse")
+		}
+		if g.Unattached(n) {
+			attrs = append(attrs, "style=dashed")
+		}
+		if g.highlight[n] {
+			attrs = append(attrs, "color=red", "penwidth=2")
+		}
+		fmt.Fprintf(&b, "  %s [%s];\n", dotQuote(n.ID()), strings.Join(attrs, ", "))
+	}
+	for _, n := range g.Nodes {
+		for _, c := range g.children[n] {
+			attrs := ""
+			if g.highlightEdge[[2]*LawNode{n, c}] {
+				attrs = " [color=red, penwidth=2]"
+			}
+			fmt.Fprintf(&b, "  %s -> %s%s;\n", dotQuote(n.ID()), dotQuote(c.ID()), attrs)
+		}
+	}
+	b.WriteString("}\n")
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+// mermaidQuote - mermaid labels can't have quotes in them
+func mermaidQuote(s string) string {
+	return `"` + strings.ReplaceAll(s, `"`, "#quot;") + `"`
+}
+
+func (g *LawGraph) writeMermaid(w io.Writer) error {
+	var b strings.Builder
+	b.WriteString("graph LR\n")
+	ids := map[*LawNode]string{}
+	var unattached, highlighted []string
+	for i, n := range g.Nodes {
+		ids[n] = fmt.Sprintf("n%d", i)
+		label := n.ID()
+		if n.File != "" {
+			label += "<br/>" + n.File
+		}
+		if isRoot(n) {
+			fmt.Fprintf(&b, "  %s([%s])\n", ids[n], mermaidQuote(label))
+		} else {
+			fmt.Fprintf(&b, "  %s[%s]\n", ids[n], mermaidQuote(label))
+		}
+		if g.Unattached(n) {
+			unattached = append(unattached, ids[n])
+		}
+		if g.highlight[n] {
+			highlighted = append(highlighted, ids[n])
+		}
+	}
+	edge := 0
+	var highlightedEdges []string
+	for _, n := range g.Nodes {
+		for _, c := range g.children[n] {
+			fmt.Fprintf(&b, "  %s --> %s\n", ids[n], ids[c])
+			if g.highlightEdge[[2]*LawNode{n, c}] {
+				highlightedEdges = append(highlightedEdges, fmt.Sprint(edge))
+			}
+			edge++
+		}
+	}
+	if len(unattached) > 0 {
+		b.WriteString("  classDef unattached stroke-dasharray: 5 5\n")
+		fmt.Fprintf(&b, "  class %s unattached\n", strings.Join(unattached, ","))
+	}
+	if len(highlighted) > 0 {
+		b.WriteString("  classDef highlight stroke:#f00,stroke-width:3px\n")
+		fmt.Fprintf(&b, "  class %s highlight\n", strings.Join(highlighted, ","))
+	}
+	if len(highlightedEdges) > 0 {
+		fmt.Fprintf(&b, "  linkStyle %s stroke:#f00,stroke-width:3px\n", strings.Join(highlightedEdges, ","))
+	}
+	_, err := io.WriteString(w, b.String())
+	return err
+}
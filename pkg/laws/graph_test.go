@@ -0,0 +1,71 @@
+// Copyright © 2026 Iggy <iggy@theiggy.com>
+// All rights reserved.
+//
+// Redistribution and use in source and binary forms, with or without
+// modification, are permitted provided that the following conditions are met:
+//
+// 1. Redistributions of source code must retain the above copyright notice,
+//    this list of conditions and the following disclaimer.
+//
+// 2. Redistributions in binary form must reproduce the above copyright notice,
+//    this list of conditions and the following disclaimer in the documentation
+//    and/or other materials provided with the distribution.
+//
+// 3. Neither the name of the copyright holder nor the names of its contributors
+//    may be used to endorse or promote products derived from this software
+//    without specific prior written permission.
+//
+// THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS "AS IS"
+// AND ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT LIMITED TO, THE
+// IMPLIED WARRANTIES OF MERCHANTABILITY AND FITNESS FOR A PARTICULAR PURPOSE
+// ARE DISCLAIMED. IN NO EVENT SHALL THE COPYRIGHT HOLDER OR CONTRIBUTORS BE
+// LIABLE FOR ANY DIRECT, INDIRECT, INCIDENTAL, SPECIAL, EXEMPLARY, OR
+// CONSEQUENTIAL DAMAGES (INCLUDING, BUT NOT LIMITED TO, PROCUREMENT OF
+// SUBSTITUTE GOODS OR SERVICES; LOSS OF USE, DATA, OR PROFITS; OR BUSINESS
+// INTERRUPTION) HOWEVER CAUSED AND ON ANY THEORY OF LIABILITY, WHETHER IN
+// CONTRACT, STRICT LIABILITY, OR TORT (INCLUDING NEGLIGENCE OR OTHERWISE)
+// ARISING IN ANY WAY OUT OF THE USE OF THIS SOFTWARE, EVEN IF ADVISED OF THE
+// POSSIBILITY OF SUCH DAMAGE.
+
+package laws
+
+import (
+	"bytes"
+	"context"
+	"strings"
+	"testing"
+)
+
+func TestLawGraphUnattached(t *testing.T) {
+	ok := func(context.Context, bool) *Result { return Unchanged("") }
+	alone := fakeNode("alone", &fakeLaw{ensure: ok})
+	first := fakeNode("first", &fakeLaw{ensure: ok}) // hangs off of the root, but second comes after it
+	second := fakeNode("second", &fakeLaw{ensure: ok})
+	g := NewLawGraph(testGraph(t, []*LawNode{alone, first, second}, [2]string{first.ID(), second.ID()}))
+
+	tests := []struct {
+		node       *LawNode
+		unattached bool
+	}{
+		{alone, true},
+		{first, false},
+		{second, false},
+		{g.Nodes[0], false}, // the root
+	}
+	for _, tt := range tests {
+		if got := g.Unattached(tt.node); got != tt.unattached {
+			t.Errorf("Unattached(%s) = %v, want %v", tt.node.ID(), got, tt.unattached)
+		}
+	}
+
+	var b bytes.Buffer
+	if err := g.Write(&b, "text"); err != nil {
+		t.Fatal(err)
+	}
+	for _, line := range strings.Split(b.String(), "\n") {
+		marked := strings.HasSuffix(line, "[unattached]")
+		if marked != strings.Contains(line, alone.ID()) {
+			t.Errorf("text graph line %q, only %s should be unattached", line, alone.ID())
+		}
+	}
+}
@@ -0,0 +1,178 @@
+// Copyright © 2026 Iggy <iggy@theiggy.com>
+// All rights reserved.
+//
+// Redistribution and use in source and binary forms, with or without
+// modification, are permitted provided that the following conditions are met:
+//
+// 1. Redistributions of source code must retain the above copyright notice,
+//    this list of conditions and the following disclaimer.
+//
+// 2. Redistributions in binary form must reproduce the above copyright notice,
+//    this list of conditions and the following disclaimer in the documentation
+//    and/or other materials provided with the distribution.
+//
+// 3. Neither the name of the copyright holder nor the names of its contributors
+//    may be used to endorse or promote products derived from this software
+//    without specific prior written permission.
+//
+// THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS "AS IS"
+// AND ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT LIMITED TO, THE
+// IMPLIED WARRANTIES OF MERCHANTABILITY AND FITNESS FOR A PARTICULAR PURPOSE
+// ARE DISCLAIMED. IN NO EVENT SHALL THE COPYRIGHT HOLDER OR CONTRIBUTORS BE
+// LIABLE FOR ANY DIRECT, INDIRECT, INCIDENTAL, SPECIAL, EXEMPLARY, OR
+// CONSEQUENTIAL DAMAGES (INCLUDING, BUT NOT LIMITED TO, PROCUREMENT OF
+// SUBSTITUTE GOODS OR SERVICES; LOSS OF USE, DATA, OR PROFITS; OR BUSINESS
+// INTERRUPTION) HOWEVER CAUSED AND ON ANY THEORY OF LIABILITY, WHETHER IN
+// CONTRACT, STRICT LIABILITY, OR TORT (INCLUDING NEGLIGENCE OR OTHERWISE)
+// ARISING IN ANY WAY OUT OF THE USE OF THIS SOFTWARE, EVEN IF ADVISED OF THE
+// POSSIBILITY OF SUCH DAMAGE.
+
+package laws
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+// DefaultJournalDir - where govern keeps track of what it's done to a host
+const DefaultJournalDir = "/var/lib/govern"
+
+// JournalDir - where runs are journaled, runs go in the journal directory
+// under it, one json file per run
+var JournalDir = DefaultJournalDir
+
+// JournalRun - a journaled run, the report with an id and the laws that
+// were applied
+type JournalRun struct {
+	ID   string `json:"id"`
+	Laws string `json:"laws"` // the laws file or directory
+	Report
+}
+
+// NewRunID - a new run id, they sort in the order the runs happened
+func NewRunID() string {
+	b := make([]byte, 3)
+	_, _ = rand.Read(b)
+	return time.Now().UTC().Format("20060102T150405Z") + "-" + hex.EncodeToString(b)
+}
+
+// journalPath - where the journal files go
+func journalPath() string {
+	return filepath.Join(JournalDir, "journal")
+}
+
+// WriteJournal - add a run to the journal. The report should come from
+// NewReport, so secrets are already redacted.
+func WriteJournal(run *JournalRun) error {
+	dir := journalPath()
+	err := os.MkdirAll(dir, 0o700)
+	if err != nil {
+		return err
+	}
+	content, err := json.MarshalIndent(run, "", "  ")
+	if err != nil {
+		return err
+	}
+	// write then rename so a half written run never shows up in history
+	tmp, err := os.CreateTemp(dir, ".run-*")
+	if err != nil {
+		return err
+	}
+	_, err = tmp.Write(append(content, '\n'))
+	if cerr := tmp.Close(); err == nil {
+		err = cerr
+	}
+	if err != nil {
+		os.Remove(tmp.Name())
+		return err
+	}
+	return os.Rename(tmp.Name(), filepath.Join(dir, run.ID+".json"))
+}
+
+// ReadJournal - a single run from the journal, by id or a unique prefix of
+// one, "last" is the most recent run
+func ReadJournal(id string) (*JournalRun, error) {
+	ids, err := journalIDs()
+	if err != nil {
+		return nil, err
+	}
+	var found []string
+	for _, i := range ids {
+		if i == id {
+			found = []string{i}
+			break
+		}
+		if strings.HasPrefix(i, id) {
+			found = append(found, i)
+		}
+	}
+	if id == "last" && len(ids) > 0 {
+		found = ids[len(ids)-1:]
+	}
+	switch len(found) {
+	case 0:
+		return nil, fmt.Errorf("no run %s in the journal", id)
+	case 1:
+		return readRun(found[0])
+	}
+	return nil, fmt.Errorf("run %s is ambiguous, it matches %s", id, strings.Join(found, ", "))
+}
+
+// ListJournal - every run in the journal, oldest first
+func ListJournal() ([]*JournalRun, error) {
+	ids, err := journalIDs()
+	if err != nil {
+		return nil, err
+	}
+	runs := make([]*JournalRun, 0, len(ids))
+	for _, id := range ids {
+		run, err := readRun(id)
+		if err != nil {
+			return nil, err
+		}
+		runs = append(runs, run)
+	}
+	return runs, nil
+}
+
+// journalIDs - the ids of the journaled runs, oldest first
+func journalIDs() ([]string, error) {
+	entries, err := os.ReadDir(journalPath())
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var ids []string
+	for _, e := range entries {
+		name := e.Name()
+		if e.IsDir() || strings.HasPrefix(name, ".") || filepath.Ext(name) != ".json" {
+			continue
+		}
+		ids = append(ids, strings.TrimSuffix(name, ".json"))
+	}
+	sort.Strings(ids)
+	return ids, nil
+}
+
+func readRun(id string) (*JournalRun, error) {
+	content, err := os.ReadFile(filepath.Join(journalPath(), id+".json"))
+	if err != nil {
+		return nil, err
+	}
+	var run JournalRun
+	err = json.Unmarshal(content, &run)
+	if err != nil {
+		return nil, fmt.Errorf("run %s: %w", id, err)
+	}
+	return &run, nil
+}
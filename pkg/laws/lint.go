@@ -0,0 +1,648 @@
+// Copyright © 2026 Iggy <iggy@theiggy.com>
+// All rights reserved.
+//
+// Redistribution and use in source and binary forms, with or without
+// modification, are permitted provided that the following conditions are met:
+//
+// 1. Redistributions of source code must retain the above copyright notice,
+//    this list of conditions and the following disclaimer.
+//
+// 2. Redistributions in binary form must reproduce the above copyright notice,
+//    this list of conditions and the following disclaimer in the documentation
+//    and/or other materials provided with the distribution.
+//
+// 3. Neither the name of the copyright holder nor the names of its contributors
+//    may be used to endorse or promote products derived from this software
+//    without specific prior written permission.
+//
+// THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS "AS IS"
+// AND ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT LIMITED TO, THE
+// IMPLIED WARRANTIES OF MERCHANTABILITY AND FITNESS FOR A PARTICULAR PURPOSE
+// ARE DISCLAIMED. IN NO EVENT SHALL THE COPYRIGHT HOLDER OR CONTRIBUTORS BE
+// LIABLE FOR ANY DIRECT, INDIRECT, INCIDENTAL, SPECIAL, EXEMPLARY, OR
+// CONSEQUENTIAL DAMAGES (INCLUDING, BUT NOT LIMITED TO, PROCUREMENT OF
+// SUBSTITUTE GOODS OR SERVICES; LOSS OF USE, DATA, OR PROFITS; OR BUSINESS
+// INTERRUPTION) HOWEVER CAUSED AND ON ANY THEORY OF LIABILITY, WHETHER IN
+// CONTRACT, STRICT LIABILITY, OR TORT (INCLUDING NEGLIGENCE OR OTHERWISE)
+// ARISING IN ANY WAY OUT OF THE USE OF THIS SOFTWARE, EVEN IF ADVISED OF THE
+// POSSIBILITY OF SUCH DAMAGE.
+
+package laws
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"reflect"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"text/template"
+	"time"
+
+	"github.com/rs/zerolog/log"
+	"gopkg.in/yaml.v3"
+)
+
+// Diagnostic - a problem found in a laws file
+type Diagnostic struct {
+	File    string `json:"file"`
+	Line    int    `json:"line"`
+	Column  int    `json:"column"`
+	Message string `json:"message"`
+}
+
+// String - file:line:column: message, like a compiler error
+func (d Diagnostic) String() string {
+	return fmt.Sprintf("%s:%d:%d: %s", d.File, d.Line, d.Column, d.Message)
+}
+
+// keyKind - what sort of value a law key takes
+type keyKind int
+
+const (
+	kindString keyKind = iota
+	kindBool
+	kindInt
+	kindMode // octal file mode, i.e. 0644
+	kindList // list of strings
+	kindMap
+	kindErrorPolicy // continue|abort
+	kindWhen        // when expression
+	kindRetry       // retry block
+	kindDuration    // i.e. 30s or 5m
+	kindAny         // not checked
+)
+
+// commonKeys - keys every law accepts
+var commonKeys, _ = structKeys(reflect.TypeOf(commonYAML{}))
+
+// retryKeys - keys in a retry block
+var retryKeys, _ = structKeys(reflect.TypeOf(RetryOpts{}))
+
+var retryType = reflect.TypeOf(RetryOpts{})
+
+// structKeys - the keys yaml.v3 decodes into a struct and the sort of value
+// each takes, the same walk as yamlKeys so lint and decoding agree. false
+// if an inlined map means any key goes.
+func structKeys(t reflect.Type) (map[string]keyKind, bool) {
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	keys := map[string]keyKind{}
+	if t.Kind() != reflect.Struct {
+		return keys, false
+	}
+	checkKeys := true
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		tag := f.Tag.Get("yaml")
+		name, opts, _ := strings.Cut(tag, ",")
+		switch {
+		case tag == "-":
+		case strings.Contains(opts, "inline") && f.Type.Kind() == reflect.Map:
+			checkKeys = false
+		case strings.Contains(opts, "inline"):
+			inlined, check := structKeys(f.Type)
+			for k, v := range inlined {
+				keys[k] = v
+			}
+			checkKeys = checkKeys && check
+		case !f.IsExported():
+		default:
+			if name == "" {
+				name = strings.ToLower(f.Name)
+			}
+			keys[name] = typeKeyKind(f.Type)
+		}
+	}
+	return keys, checkKeys
+}
+
+// typeKeyKind - the sort of value a field of a type takes
+func typeKeyKind(t reflect.Type) keyKind {
+	switch t {
+	case durationType:
+		return kindDuration
+	case modeType:
+		return kindMode
+	case errorPolicyType:
+		return kindErrorPolicy
+	case whenType:
+		return kindWhen
+	case retryType:
+		return kindRetry
+	}
+	switch t.Kind() {
+	case reflect.Pointer:
+		return typeKeyKind(t.Elem())
+	case reflect.Bool:
+		return kindBool
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return kindInt
+	case reflect.Slice:
+		if k := t.Elem().Kind(); k == reflect.String || k == reflect.Int {
+			return kindList
+		}
+		return kindAny
+	case reflect.Map, reflect.Struct:
+		return kindMap
+	case reflect.String:
+		return kindString
+	}
+	return kindAny
+}
+
+// lintLaw - a law found while linting, along with where it was found
+type lintLaw struct {
+	id     string
+	file   string
+	node   *yaml.Node
+	module *moduleInstance
+	deps   []lintRef // laws that have to come before this one
+	refs   []lintRef // laws that have to come after this one
+}
+
+// lintRef - a requisite and where it was written
+type lintRef struct {
+	id   string
+	node *yaml.Node
+}
+
+// linter - keeps track of everything while linting a set of files
+type linter struct {
+	diags     []Diagnostic
+	laws      []*lintLaw
+	ids       map[string]*lintLaw
+	instances []*moduleInstance
+}
+
+var (
+	templateErrRe = regexp.MustCompile(`^template: [^:]*:(\d+)(?::(\d+))?: (.*)$`)
+	yamlErrRe     = regexp.MustCompile(`^yaml: line (\d+): (.*)$`)
+)
+
+// Lint - check a directory of laws files for problems without applying
+// anything. An error is only returned if the files couldn't be found.
+func Lint(path string) ([]Diagnostic, error) {
+	queue, err := sourcesFor(path, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	l := &linter{ids: map[string]*lintLaw{}}
+
+	vars, err := LoadVars(path)
+	if err != nil {
+		l.diags = append(l.diags, Diagnostic{File: varsDir(path), Message: err.Error()})
+		vars = &Vars{Values: map[string]interface{}{}}
+	}
+	for _, src := range queue {
+		src.vars = vars
+		src.lint = true
+	}
+
+	// follow include and modules the same way ParseFiles does
+	seen := map[string]bool{}
+	for len(queue) > 0 {
+		src := queue[0]
+		queue = queue[1:]
+		if seen[src.key()] {
+			continue
+		}
+		seen[src.key()] = true
+		queue = append(queue, l.lintFile(src)...)
+	}
+	l.lintRefs()
+	l.lintCycles()
+
+	sort.SliceStable(l.diags, func(i, j int) bool {
+		if l.diags[i].File != l.diags[j].File {
+			return l.diags[i].File < l.diags[j].File
+		}
+		return l.diags[i].Line < l.diags[j].Line
+	})
+	return l.diags, nil
+}
+
+func (l *linter) report(file string, node *yaml.Node, format string, args ...interface{}) {
+	d := Diagnostic{File: file, Message: fmt.Sprintf(format, args...)}
+	if node != nil {
+		d.Line = node.Line
+		d.Column = node.Column
+	}
+	log.Debug().Str("diagnostic", d.String()).Msg("lint")
+	l.diags = append(l.diags, d)
+}
+
+// lintFile - check the structure of a single file, returns the files it
+// pulls in with include and modules
+func (l *linter) lintFile(src *lawsSource) []*lawsSource {
+	file := src.path
+	rendered, err := renderLaws(src)
+	if err != nil {
+		d := Diagnostic{File: file, Message: err.Error()}
+		if m := templateErrRe.FindStringSubmatch(err.Error()); m != nil {
+			d.Line, _ = strconv.Atoi(m[1])
+			d.Column, _ = strconv.Atoi(m[2])
+			d.Message = "template: " + m[3]
+		}
+		l.diags = append(l.diags, d)
+		return nil
+	}
+
+	var more []*lawsSource
+	dec := yaml.NewDecoder(bytes.NewReader(rendered))
+	for {
+		var doc yaml.Node
+		err = dec.Decode(&doc)
+		if errors.Is(err, io.EOF) {
+			return more
+		}
+		if err != nil {
+			d := Diagnostic{File: file, Message: err.Error()}
+			if m := yamlErrRe.FindStringSubmatch(err.Error()); m != nil {
+				d.Line, _ = strconv.Atoi(m[1])
+				d.Message = "yaml: " + m[2]
+			}
+			l.diags = append(l.diags, d)
+			return more
+		}
+		if len(doc.Content) == 0 || doc.Content[0].Tag == "!!null" {
+			continue // empty document
+		}
+		more = append(more, l.lintDocument(src, doc.Content[0])...)
+	}
+}
+
+// lintDocument - check the structure of a single yaml document in a file
+func (l *linter) lintDocument(src *lawsSource, groups *yaml.Node) []*lawsSource {
+	file := src.path
+	if groups.Kind != yaml.MappingNode {
+		l.report(file, groups, "expected a map of law groups")
+		return nil
+	}
+	var include, modules *yaml.Node
+	for i := 0; i+1 < len(groups.Content); i += 2 {
+		groupKey, types := groups.Content[i], groups.Content[i+1]
+		switch groupKey.Value {
+		case "include":
+			include = types
+			continue
+		case "modules":
+			modules = types
+			continue
+		}
+		if types.Kind != yaml.MappingNode {
+			l.report(file, types, "expected a map of law types for %s", groupKey.Value)
+			continue
+		}
+		for j := 0; j+1 < len(types.Content); j += 2 {
+			typeKey, seq := types.Content[j], types.Content[j+1]
+			path := groupKey.Value + "::" + typeKey.Value
+			kind := findKind(groupKey.Value, typeKey.Value)
+			if kind == nil {
+				l.report(file, typeKey, "unknown law type %s", path)
+				continue
+			}
+			// kinds that take any key (plugins) only get their common
+			// keys checked
+			keys, checkKeys := structKeys(reflect.TypeOf(kind.New()))
+			if seq.Kind != yaml.SequenceNode {
+				l.report(file, seq, "expected a list of laws for %s", path)
+				continue
+			}
+			for _, law := range seq.Content {
+				l.lintLaw(src, kind.group()+"::"+kind.typ(), keys, checkKeys, law)
+			}
+		}
+	}
+
+	more, err := src.expand(include, modules)
+	if err != nil {
+		var errs []error
+		if joined, ok := err.(interface{ Unwrap() []error }); ok {
+			errs = joined.Unwrap()
+		}
+		for _, err := range errs {
+			var serr *sourceError
+			if errors.As(err, &serr) {
+				l.report(file, serr.node, "%s", serr.msg)
+			} else {
+				l.report(file, groups, "%v", err)
+			}
+		}
+	}
+	for _, s := range more {
+		if s.module != nil && s.module.parent == src.module && !l.hasInstance(s.module) {
+			l.instances = append(l.instances, s.module)
+		}
+	}
+	return more
+}
+
+// hasInstance - whether the module instance has been seen already
+func (l *linter) hasInstance(m *moduleInstance) bool {
+	for _, i := range l.instances {
+		if i == m {
+			return true
+		}
+	}
+	return false
+}
+
+// lintLaw - check the keys and values of a single law
+func (l *linter) lintLaw(src *lawsSource, prefix string, keys map[string]keyKind, checkKeys bool, law *yaml.Node) {
+	file := src.path
+	if law.Kind != yaml.MappingNode {
+		l.report(file, law, "expected a law, got %s", law.ShortTag())
+		return
+	}
+	if src.module != nil {
+		prefix = src.module.prefix() + "::" + prefix
+	}
+
+	ll := &lintLaw{file: file, node: law, module: src.module}
+	for i := 0; i+1 < len(law.Content); i += 2 {
+		key, value := law.Content[i], law.Content[i+1]
+		kind, ok := commonKeys[key.Value]
+		if !ok {
+			kind, ok = keys[key.Value]
+		}
+		if !ok && !checkKeys {
+			continue
+		}
+		if !ok {
+			l.report(file, key, "unknown key %q for %s", key.Value, prefix)
+			continue
+		}
+		if !l.lintValue(file, key.Value, kind, value) {
+			continue
+		}
+
+		switch key.Value {
+		case "template":
+			if prefix == "files::templates" || strings.HasSuffix(prefix, "::files::templates") {
+				l.lintTemplate(src, value)
+			}
+		case "name":
+			ll.id = prefix + "::" + strings.ToLower(value.Value)
+		case "after", "watch":
+			for _, ref := range value.Content {
+				ll.deps = append(ll.deps, lintRef{ref.Value, ref})
+			}
+		case "before", "notify":
+			for _, ref := range value.Content {
+				ll.refs = append(ll.refs, lintRef{ref.Value, ref})
+			}
+		}
+	}
+
+	if ll.id == "" {
+		l.report(file, law, "law is missing a name")
+		return
+	}
+	if dup, ok := l.ids[ll.id]; ok {
+		l.report(file, law, "duplicate law %s, also defined at %s:%d", ll.id, dup.file, dup.node.Line)
+		return
+	}
+	l.ids[ll.id] = ll
+	l.laws = append(l.laws, ll)
+}
+
+// lintTemplate - check that a file template exists and parses, unknown
+// functions are caught here
+func (l *linter) lintTemplate(src *lawsSource, value *yaml.Node) {
+	ft := &FileTemplate{TemplatePath: value.Value}
+	path := ft.templatePath(src)
+	content, err := os.ReadFile(path)
+	if err != nil {
+		l.report(src.path, value, "template: %v", err)
+		return
+	}
+	_, err = template.New(filepath.Base(path)).Funcs(templateFuncs(src)).Parse(string(content))
+	if err != nil {
+		d := Diagnostic{File: path, Message: err.Error()}
+		if m := templateErrRe.FindStringSubmatch(err.Error()); m != nil {
+			d.Line, _ = strconv.Atoi(m[1])
+			d.Column, _ = strconv.Atoi(m[2])
+			d.Message = "template: " + m[3]
+		}
+		l.diags = append(l.diags, d)
+	}
+}
+
+// lintValue - check that a value is the right type for its key
+func (l *linter) lintValue(file, key string, kind keyKind, value *yaml.Node) bool {
+	switch kind {
+	case kindAny:
+		return true
+	case kindList:
+		if value.Kind != yaml.SequenceNode {
+			l.report(file, value, "%s should be a list", key)
+			return false
+		}
+		for _, item := range value.Content {
+			if item.Kind != yaml.ScalarNode {
+				l.report(file, item, "%s should be a list of strings", key)
+				return false
+			}
+		}
+		return true
+	case kindMap:
+		if value.Kind != yaml.MappingNode {
+			l.report(file, value, "%s should be a map", key)
+			return false
+		}
+		return true
+	case kindRetry:
+		if value.Kind != yaml.MappingNode {
+			l.report(file, value, "%s should be a map", key)
+			return false
+		}
+		ok := true
+		for i := 0; i+1 < len(value.Content); i += 2 {
+			k, v := value.Content[i], value.Content[i+1]
+			kind, known := retryKeys[k.Value]
+			if !known {
+				l.report(file, k, "unknown key %q for retry", k.Value)
+				ok = false
+				continue
+			}
+			ok = l.lintValue(file, "retry."+k.Value, kind, v) && ok
+		}
+		return ok
+	}
+
+	if value.Kind != yaml.ScalarNode {
+		l.report(file, value, "%s should be a single value", key)
+		return false
+	}
+	var err error
+	switch kind {
+	case kindBool:
+		_, err = strconv.ParseBool(value.Value)
+		if err != nil {
+			l.report(file, value, "%s should be true or false, got %q", key, value.Value)
+		}
+	case kindInt:
+		_, err = strconv.ParseInt(value.Value, 10, 64)
+		if err != nil {
+			l.report(file, value, "%s should be a number, got %q", key, value.Value)
+		}
+	case kindMode:
+		_, err = parseMode(value.Value)
+		if err != nil {
+			l.report(file, value, "%s should be an octal file mode (i.e. 0644), got %q", key, value.Value)
+		}
+	case kindDuration:
+		_, err = time.ParseDuration(value.Value)
+		if err != nil {
+			l.report(file, value, "%s should be a duration (i.e. 30s or 5m), got %q", key, value.Value)
+		}
+	case kindWhen:
+		_, err = ParseWhen(value.Value)
+		if err != nil {
+			l.report(file, value, "%s: %v", key, err)
+		}
+	case kindErrorPolicy:
+		_, err = ParseErrorPolicy(value.Value)
+		if err != nil {
+			l.report(file, value, "%s: %v", key, err)
+		}
+	}
+	return err == nil
+}
+
+// allIDs - the ids of every law found, for resolveRef
+func (l *linter) allIDs() []string {
+	ids := make([]string, 0, len(l.laws))
+	for _, ll := range l.laws {
+		ids = append(ids, ll.id)
+	}
+	return ids
+}
+
+// lintRefs - make sure requisites point at laws that exist
+func (l *linter) lintRefs() {
+	ids := l.allIDs()
+	check := func(file string, ref lintRef, m *moduleInstance) {
+		parts := strings.Split(ref.id, "::")
+		if isModuleRef(ref.id) {
+			if len(resolveRef(ref.id, m, ids)) == 0 {
+				l.report(file, ref.node, "requisite %q doesn't match any module instance", ref.id)
+			}
+			return
+		}
+		if len(parts) < 3 || parts[0] == "" || parts[1] == "" || parts[2] == "" {
+			l.report(file, ref.node, "requisite %q should be written as group::type::name or modules::instance", ref.id)
+			return
+		}
+		if len(resolveRef(ref.id, m, ids)) == 0 {
+			l.report(file, ref.node, "requisite %q doesn't match any law", ref.id)
+		}
+	}
+
+	for _, ll := range l.laws {
+		for _, refs := range [][]lintRef{ll.deps, ll.refs} {
+			for _, ref := range refs {
+				check(ll.file, ref, ll.module)
+			}
+		}
+	}
+	// the instances' own before/after are looked up from where they're used
+	for _, m := range l.instances {
+		for _, ref := range append(instanceRefs(m, "after"), instanceRefs(m, "before")...) {
+			check(m.file, ref, m.parent)
+		}
+	}
+}
+
+// instanceRefs - the before or after requisites of a module instance, and
+// where they were written
+func instanceRefs(m *moduleInstance, key string) []lintRef {
+	var refs []lintRef
+	for i := 0; i+1 < len(m.node.Content); i += 2 {
+		if m.node.Content[i].Value != key {
+			continue
+		}
+		for _, ref := range m.node.Content[i+1].Content {
+			refs = append(refs, lintRef{ref.Value, ref})
+		}
+	}
+	return refs
+}
+
+// lintCycles - find requisites that loop back on themselves, which would
+// make the graph impossible to sort
+func (l *linter) lintCycles() {
+	ids := l.allIDs()
+	// edges go from a law to the laws that come after it
+	edges := map[string][]string{}
+	for _, ll := range l.laws {
+		for _, ref := range ll.deps {
+			for _, dep := range resolveRef(ref.id, ll.module, ids) {
+				edges[dep] = append(edges[dep], ll.id)
+			}
+		}
+		for _, ref := range ll.refs {
+			for _, dep := range resolveRef(ref.id, ll.module, ids) {
+				edges[ll.id] = append(edges[ll.id], dep)
+			}
+		}
+		for m := ll.module; m != nil; m = m.parent {
+			for _, ref := range m.After {
+				for _, dep := range resolveRef(ref, m.parent, ids) {
+					edges[dep] = append(edges[dep], ll.id)
+				}
+			}
+			for _, ref := range m.Before {
+				for _, dep := range resolveRef(ref, m.parent, ids) {
+					edges[ll.id] = append(edges[ll.id], dep)
+				}
+			}
+		}
+	}
+
+	const (
+		unvisited = iota
+		visiting
+		visited
+	)
+	state := map[string]int{}
+	var stack []string
+	var visit func(id string)
+	visit = func(id string) {
+		state[id] = visiting
+		stack = append(stack, id)
+		for _, next := range edges[id] {
+			switch state[next] {
+			case unvisited:
+				visit(next)
+			case visiting:
+				// everything on the stack from next onwards is the cycle
+				start := 0
+				for i, s := range stack {
+					if s == next {
+						start = i
+					}
+				}
+				cycle := append(append([]string{}, stack[start:]...), next)
+				ll := l.ids[next]
+				l.report(ll.file, ll.node, "dependency cycle: %s", strings.Join(cycle, " -> "))
+			}
+		}
+		stack = stack[:len(stack)-1]
+		state[id] = visited
+	}
+	for _, ll := range l.laws {
+		if state[ll.id] == unvisited {
+			visit(ll.id)
+		}
+	}
+}
@@ -0,0 +1,160 @@
+// Copyright © 2026 Iggy <iggy@theiggy.com>
+// All rights reserved.
+//
+// Redistribution and use in source and binary forms, with or without
+// modification, are permitted provided that the following conditions are met:
+//
+// 1. Redistributions of source code must retain the above copyright notice,
+//    this list of conditions and the following disclaimer.
+//
+// 2. Redistributions in binary form must reproduce the above copyright notice,
+//    this list of conditions and the following disclaimer in the documentation
+//    and/or other materials provided with the distribution.
+//
+// 3. Neither the name of the copyright holder nor the names of its contributors
+//    may be used to endorse or promote products derived from this software
+//    without specific prior written permission.
+//
+// THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS "AS IS"
+// AND ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT LIMITED TO, THE
+// IMPLIED WARRANTIES OF MERCHANTABILITY AND FITNESS FOR A PARTICULAR PURPOSE
+// ARE DISCLAIMED. IN NO EVENT SHALL THE COPYRIGHT HOLDER OR CONTRIBUTORS BE
+// LIABLE FOR ANY DIRECT, INDIRECT, INCIDENTAL, SPECIAL, EXEMPLARY, OR
+// CONSEQUENTIAL DAMAGES (INCLUDING, BUT NOT LIMITED TO, PROCUREMENT OF
+// SUBSTITUTE GOODS OR SERVICES; LOSS OF USE, DATA, OR PROFITS; OR BUSINESS
+// INTERRUPTION) HOWEVER CAUSED AND ON ANY THEORY OF LIABILITY, WHETHER IN
+// CONTRACT, STRICT LIABILITY, OR TORT (INCLUDING NEGLIGENCE OR OTHERWISE)
+// ARISING IN ANY WAY OUT OF THE USE OF THIS SOFTWARE, EVEN IF ADVISED OF THE
+// POSSIBILITY OF SUCH DAMAGE.
+
+package laws
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestLint(t *testing.T) {
+	tests := []struct {
+		name string
+		yaml string
+		// line:column: message, FILE is replaced with the laws file
+		want []string
+	}{
+		{
+			name: "clean",
+			yaml: `files:
+  templates:
+    - name: /tmp/a
+      text: a
+      mode: 0o644
+      backup: true
+      timeout: 30s
+      on_error: abort
+      when: Distro.Family == "debian"
+      retry:
+        attempts: 3
+    - name: /tmp/b
+      text: b
+      mode: 0644
+      after: [files::templates::/tmp/a]
+`,
+		},
+		{
+			name: "bad keys and values",
+			yaml: `files:
+  templates:
+    - name: /tmp/c
+      txt: c
+      mode: 0o944
+      backup: maybe
+      timeout: soon
+      when: Distro.Family == 1
+      retry:
+        attempts: 3
+        tries: 2
+    - text: no name
+    - name: /tmp/c
+      text: again
+  nonsense:
+    - name: x
+`,
+			want: []string{
+				`4:7: unknown key "txt" for files::templates`,
+				`5:13: mode should be an octal file mode (i.e. 0644), got "0o944"`,
+				`6:15: backup should be true or false, got "maybe"`,
+				`7:16: timeout should be a duration (i.e. 30s or 5m), got "soon"`,
+				`8:13: when: can't compare a string with a number using ==`,
+				`11:9: unknown key "tries" for retry`,
+				`12:7: law is missing a name`,
+				`13:7: duplicate law files::templates::/tmp/c, also defined at FILE:3`,
+				`15:3: unknown law type files::nonsense`,
+			},
+		},
+		{
+			name: "requisites and cycles",
+			yaml: `files:
+  templates:
+    - name: /tmp/x
+      text: x
+      after: [files::templates::/tmp/y]
+    - name: /tmp/y
+      text: y
+      after: [files::templates::/tmp/x]
+      before: [files::templates::/nope, modules::nope, nope]
+`,
+			want: []string{
+				`3:7: dependency cycle: files::templates::/tmp/x -> files::templates::/tmp/y -> files::templates::/tmp/x`,
+				`9:16: requisite "files::templates::/nope" doesn't match any law`,
+				`9:41: requisite "modules::nope" doesn't match any module instance`,
+				`9:56: requisite "nope" should be written as group::type::name or modules::instance`,
+			},
+		},
+		{
+			name: "template",
+			yaml: "files:\n  templates:\n    - name: /tmp/x\n      text: \"{{ .nope \"\n",
+			want: []string{`4:0: template: unterminated quoted string`},
+		},
+		{
+			name: "yaml",
+			yaml: "files:\n  templates:\n    - name: /tmp/x\n     text: x\n",
+			want: []string{`2:0: yaml: did not find expected '-' indicator`},
+		},
+		{
+			name: "not a list",
+			yaml: "files:\n  templates:\n    name: /tmp/x\n",
+			want: []string{`3:5: expected a list of laws for files::templates`},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "laws.yaml")
+			if err := os.WriteFile(path, []byte(tt.yaml), 0o600); err != nil {
+				t.Fatal(err)
+			}
+			diags, err := Lint(path)
+			if err != nil {
+				t.Fatal(err)
+			}
+			var got []string
+			for _, d := range diags {
+				if d.File != path {
+					t.Errorf("diagnostic for %q, want %q", d.File, path)
+				}
+				got = append(got, fmt.Sprintf("%d:%d: %s", d.Line, d.Column, d.Message))
+			}
+			var want []string
+			for _, w := range tt.want {
+				want = append(want, strings.ReplaceAll(w, "FILE", path))
+			}
+			if !reflect.DeepEqual(got, want) {
+				t.Errorf("Lint() =\n%s\nwant\n%s", strings.Join(got, "\n"), strings.Join(want, "\n"))
+			}
+		})
+	}
+}
@@ -30,117 +30,52 @@
 // Package laws - Laws describe the state of the system
 package laws
 
+import (
+	"context"
+	"math/rand"
+	"time"
+)
+
 // RetryOpts - retry options
 type RetryOpts struct {
 	Attempts uint // how many times to try to apply the law
 	Until    bool // ??? copied from Salt, probably not necessary
-	Interval uint // how long to wait between tries
-	Splay    uint // how much variance to add to the interval, useful for thundering herd type scenarios
+	Interval uint // how long to wait between tries (seconds)
+	Splay    uint // how much variance to add to the interval, useful for thundering herd type scenarios (seconds)
 }
 
-// TODO embedded structs don't work with yaml
-// CommonFields - common fields for all objects
-// type CommonFields struct {
-// 	Name   string
-// 	Before []string
-// 	After  []string
-// 	RunAs  string // user to run as (not implemented)
-
-// 	// other stuff I may do some day
-// 	// AfterIf []string // requisites that may not exist due to templating
-// 	// Present bool // I think this is supposed to be whether some law is used or not
-// 	// Uses     []string // when a law uses some outcome of another law
-// 	// UsedBy   []string
-// 	// Needs    []string
-// 	// NeededBy []string
-// 	// Reload   bool     // reload laws/facts after applied, useful to update things like a fact that lists packages installed, services installed, etc
-// 	// Retry RetryOpts
-// }
-
-// This doesn't do anything, still have to unmarshal all of the common fields in each unmarshaler
-// func (c *CommonFields) UnmarshalYAML(value *yaml.Node) error {
-// 	// log.Trace().Interface("Node", value).Msg("UnmarshalYAML")
-// 	if value.Tag != "!!map" {
-// 		return fmt.Errorf("unable to unmarshal yaml: value not map (%s)", value.Tag)
-// 	}
-// 	c.RunAs = "root"
-// 	for i, node := range value.Content {
-// 		log.Trace().Interface("node1", node).Msg("commonfields unmarshal")
-// 		switch node.Value {
-// 		case "name":
-// 			c.Name = value.Content[i+1].Value
-// 		case "after":
-// 			for _, j := range value.Content[i+1].Content {
-// 				c.After = append(c.After, j.Value)
-// 			}
-// 		case "before":
-// 			for _, j := range value.Content[i+1].Content {
-// 				c.Before = append(c.Before, j.Value)
-// 			}
-// 		case "runas":
-// 			c.RunAs = value.Content[i+1].Value
-
-// 			// case "uid":
-// 			// 	u.UID, _ = strconv.ParseUint(value.Content[i+1].Value, 10, 64)
-// 		}
-// 	}
+// wait - the interval plus a random amount of splay
+func (r *RetryOpts) wait() time.Duration {
+	wait := time.Duration(r.Interval) * time.Second
+	if r.Splay > 0 {
+		wait += time.Duration(rand.Int63n(int64(time.Duration(r.Splay) * time.Second)))
+	}
+	return wait
+}
 
-// 	return nil
-// }
+// CommonFields - fields every law has. Laws embed this (with `yaml:"-"`),
+// it's decoded by ParseFiles before the rest of the law is handed to the
+// law's own decoder, so laws only have to deal with their own fields.
+type CommonFields struct {
+	Name   string   `yaml:"name"`
+	Before []string `yaml:"before"`
+	After  []string `yaml:"after"`
+	RunAs  string   `yaml:"run_as"` // uid:gid to run as, only scripts use it so far
+
+	// other stuff I may do some day
+	// AfterIf []string // requisites that may not exist due to templating
+	// Present bool // I think this is supposed to be whether some law is used or not
+	// Uses     []string // when a law uses some outcome of another law
+	// UsedBy   []string
+	// Needs    []string
+	// NeededBy []string
+	// Reload   bool     // reload laws/facts after applied, useful to update things like a fact that lists packages installed, services installed, etc
+}
 
-// Laws - describe the state of the system
-// TODO should really just turn this into a list of `Law`
-//
-//	type Laws struct {
-//		Users        []User
-//		Groups       []Group
-//		Packages     []Package
-//		PackageRepos []PackageRepo
-//		Containers   []Container
-//		Scripts      []Script
-//		Files        FileTemplate
-//		Mounts       []Mount
-//		Services     []Service
-//	}
-//
-// type Laws2 map[string]interface{}
-type Laws3 struct {
-	Users struct {
-		Present []*User
-	}
-	Groups struct {
-		Present []*Group
-	}
-	Packages struct {
-		Installed []*Package
-	}
-	PackageRepos struct {
-		Present []*PackageRepo
-		Absent  []*PackageRepo
-	} `yaml:"package_repos"`
-	Containers struct {
-		// FIXME revisit this naming
-		Running []*Container
-	}
-	Scripts struct {
-		Run []*Script
-	}
-	Files struct {
-		Templates []*FileTemplate
-		Inserts   []*FileInsert
-		Changes   []*FileChange
-		Links     []*FileLink
-	}
-	Mounts struct {
-		Exists []*Mount
-		Absent []*AbsentMount
-	}
-	Services struct {
-		Enabled []*Service
-	}
-	SSH struct {
-		AuthorizedKeys []*SSHKey `yaml:"authorized_keys"`
-	} `yaml:"ssh"`
+// Common - the common fields, this gets promoted to every law that embeds
+// CommonFields
+func (c *CommonFields) Common() *CommonFields {
+	return c
 }
 
 // type Laws2[T comparable] map[T]struct {
@@ -151,10 +86,13 @@ type Laws3 struct {
 // 	return make(Laws2[T])
 // }
 
+// Law - something that can be ensured on the system
 type Law interface {
 	// User | Group | Package | Container | Script | FileTemplate | FileInsert | FileChange | Mount | Service
 
-	Ensure(bool) error
+	// Ensure makes the system match the law (or just reports what it would
+	// do when pretending) and returns what happened
+	Ensure(context.Context, bool) *Result
 }
 
 // ProcessFile - process a yaml file
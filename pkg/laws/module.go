@@ -0,0 +1,299 @@
+// Copyright © 2026 Iggy <iggy@theiggy.com>
+// All rights reserved.
+//
+// Redistribution and use in source and binary forms, with or without
+// modification, are permitted provided that the following conditions are met:
+//
+// 1. Redistributions of source code must retain the above copyright notice,
+//    this list of conditions and the following disclaimer.
+//
+// 2. Redistributions in binary form must reproduce the above copyright notice,
+//    this list of conditions and the following disclaimer in the documentation
+//    and/or other materials provided with the distribution.
+//
+// 3. Neither the name of the copyright holder nor the names of its contributors
+//    may be used to endorse or promote products derived from this software
+//    without specific prior written permission.
+//
+// THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS "AS IS"
+// AND ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT LIMITED TO, THE
+// IMPLIED WARRANTIES OF MERCHANTABILITY AND FITNESS FOR A PARTICULAR PURPOSE
+// ARE DISCLAIMED. IN NO EVENT SHALL THE COPYRIGHT HOLDER OR CONTRIBUTORS BE
+// LIABLE FOR ANY DIRECT, INDIRECT, INCIDENTAL, SPECIAL, EXEMPLARY, OR
+// CONSEQUENTIAL DAMAGES (INCLUDING, BUT NOT LIMITED TO, PROCUREMENT OF
+// SUBSTITUTE GOODS OR SERVICES; LOSS OF USE, DATA, OR PROFITS; OR BUSINESS
+// INTERRUPTION) HOWEVER CAUSED AND ON ANY THEORY OF LIABILITY, WHETHER IN
+// CONTRACT, STRICT LIABILITY, OR TORT (INCLUDING NEGLIGENCE OR OTHERWISE)
+// ARISING IN ANY WAY OUT OF THE USE OF THIS SOFTWARE, EVEN IF ADVISED OF THE
+// POSSIBILITY OF SUCH DAMAGE.
+
+package laws
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/iggy/govern/pkg/facts"
+	"gopkg.in/yaml.v3"
+)
+
+// ModuleFile - the file in a module's directory that declares its inputs,
+// directories with one are only read through a modules: instance
+const ModuleFile = "module.yaml"
+
+// lawsSource - a laws file to read, and the module instance it's part of
+type lawsSource struct {
+	path   string
+	module *moduleInstance // nil for laws that aren't in a module
+	vars   *Vars
+	lint   bool // secrets aren't decrypted when linting
+}
+
+// key - the same file can be used by more than one module instance, but
+// otherwise is only read once
+func (s *lawsSource) key() string {
+	abs, err := filepath.Abs(s.path)
+	if err != nil || s.path == Stdin {
+		abs = s.path
+	}
+	return fmt.Sprintf("%p %s", s.module, abs)
+}
+
+// dir - what include and module paths in the file are relative to
+func (s *lawsSource) dir() string {
+	if s.path == Stdin {
+		return "."
+	}
+	return filepath.Dir(s.path)
+}
+
+// templateData - what the laws templates get to use
+func (s *lawsSource) templateData() map[string]interface{} {
+	data := map[string]interface{}{"facts": facts.Facts}
+	if s.vars != nil {
+		data["vars"] = s.vars.Values
+	}
+	if s.module != nil {
+		data["inputs"] = s.module.Inputs
+		data["module"] = s.module.Name
+	}
+	return data
+}
+
+// sourcesFor - the laws files at a path as sources in a module instance
+func sourcesFor(path string, m *moduleInstance) ([]*lawsSource, error) {
+	files, err := lawsFiles(path)
+	if err != nil {
+		return nil, err
+	}
+	sources := make([]*lawsSource, 0, len(files))
+	for _, f := range files {
+		sources = append(sources, &lawsSource{path: f, module: m})
+	}
+	return sources, nil
+}
+
+// moduleInput - an input a module declares in its module.yaml
+type moduleInput struct {
+	Description string      `yaml:"description"`
+	Required    bool        `yaml:"required"`
+	Default     interface{} `yaml:"default"`
+}
+
+// moduleSpec - the contents of a module.yaml
+type moduleSpec struct {
+	Description string                 `yaml:"description"`
+	Inputs      map[string]moduleInput `yaml:"inputs"`
+}
+
+// moduleInstance - one use of a module, i.e.
+//
+//	modules:
+//	  - name: blog
+//	    module: modules/webapp
+//	    inputs:
+//	      port: 8081
+//	    after: [packages::installed::nginx]
+//
+// the laws in it get ids under modules::blog:: and the instance's
+// before/after apply to every one of them
+type moduleInstance struct {
+	Name   string                 `yaml:"name"`
+	Module string                 `yaml:"module"` // the module's directory, relative to the file using it
+	Inputs map[string]interface{} `yaml:"inputs"`
+	Before []string               `yaml:"before"`
+	After  []string               `yaml:"after"`
+
+	parent *moduleInstance // the instance this one was used from
+	dir    string          // the module's directory
+	file   string          // where the instance was used
+	node   *yaml.Node
+}
+
+// prefix - the start of the ids of the laws in this instance
+func (m *moduleInstance) prefix() string {
+	prefix := "modules::" + strings.ToLower(m.Name)
+	if m.parent != nil {
+		return m.parent.prefix() + "::" + prefix
+	}
+	return prefix
+}
+
+// sourceError - a problem with an include or module, and where it was
+type sourceError struct {
+	node *yaml.Node
+	msg  string
+}
+
+func (e *sourceError) Error() string {
+	return fmt.Sprintf("line %d: %s", e.node.Line, e.msg)
+}
+
+// expand - the laws files pulled in by the include and modules keys of a
+// laws document
+func (s *lawsSource) expand(include, modules *yaml.Node) ([]*lawsSource, error) {
+	var sources []*lawsSource
+	var errs []error
+
+	if include != nil {
+		var paths []string
+		err := include.Decode(&paths)
+		if err != nil {
+			errs = append(errs, &sourceError{include, "include should be a list of files or directories"})
+		}
+		for i, p := range paths {
+			if !filepath.IsAbs(p) {
+				p = filepath.Join(s.dir(), p)
+			}
+			more, err := sourcesFor(p, s.module)
+			if err != nil {
+				errs = append(errs, &sourceError{include.Content[i], fmt.Sprintf("can't include %s: %v", p, err)})
+				continue
+			}
+			sources = append(sources, more...)
+		}
+	}
+
+	if modules != nil {
+		if modules.Kind != yaml.SequenceNode {
+			errs = append(errs, &sourceError{modules, "modules should be a list of module instances"})
+			modules = &yaml.Node{}
+		}
+		for _, node := range modules.Content {
+			more, err := s.instantiate(node)
+			if err != nil {
+				errs = append(errs, err)
+				continue
+			}
+			sources = append(sources, more...)
+		}
+	}
+
+	for _, src := range sources {
+		src.vars = s.vars
+		src.lint = s.lint
+	}
+	return sources, errors.Join(errs...)
+}
+
+// instantiate - the laws files for one module instance
+func (s *lawsSource) instantiate(node *yaml.Node) ([]*lawsSource, error) {
+	m := &moduleInstance{parent: s.module, file: s.path, node: node}
+	err := node.Decode(m)
+	if err != nil {
+		return nil, &sourceError{node, err.Error()}
+	}
+	if m.Name == "" || m.Module == "" {
+		return nil, &sourceError{node, "module instances need a name and a module"}
+	}
+	if strings.Contains(m.Name, "::") {
+		return nil, &sourceError{node, fmt.Sprintf("module instance name %q can't have :: in it", m.Name)}
+	}
+
+	dir := m.Module
+	if !filepath.IsAbs(dir) {
+		dir = filepath.Join(s.dir(), dir)
+	}
+	m.dir, _ = filepath.Abs(dir)
+	for p := m.parent; p != nil; p = p.parent {
+		if p.dir == m.dir {
+			return nil, &sourceError{node, fmt.Sprintf("module %s uses itself", m.Module)}
+		}
+	}
+	raw, err := os.ReadFile(filepath.Join(dir, ModuleFile))
+	if err != nil {
+		return nil, &sourceError{node, fmt.Sprintf("%s isn't a module: %v", dir, err)}
+	}
+	var spec moduleSpec
+	err = yaml.Unmarshal(raw, &spec)
+	if err != nil {
+		return nil, &sourceError{node, fmt.Sprintf("bad %s: %v", filepath.Join(dir, ModuleFile), err)}
+	}
+
+	// check the inputs against what the module takes, and fill in defaults
+	if m.Inputs == nil {
+		m.Inputs = map[string]interface{}{}
+	}
+	var problems []string
+	for name := range m.Inputs {
+		if _, ok := spec.Inputs[name]; !ok {
+			problems = append(problems, fmt.Sprintf("unknown input %q", name))
+		}
+	}
+	for name, input := range spec.Inputs {
+		if _, ok := m.Inputs[name]; ok {
+			continue
+		}
+		if input.Required {
+			problems = append(problems, fmt.Sprintf("missing required input %q", name))
+			continue
+		}
+		m.Inputs[name] = input.Default
+	}
+	if len(problems) > 0 {
+		sort.Strings(problems)
+		return nil, &sourceError{node, fmt.Sprintf("module %s: %s", m.Name, strings.Join(problems, ", "))}
+	}
+
+	sources, err := sourcesFor(dir, m)
+	if err != nil {
+		return nil, &sourceError{node, err.Error()}
+	}
+	return sources, nil
+}
+
+// isModuleRef - a requisite on every law in a module instance
+func isModuleRef(ref string) bool {
+	parts := strings.Split(ref, "::")
+	return len(parts)%2 == 0 && len(parts) >= 2 && parts[len(parts)-2] == "modules"
+}
+
+// resolveRef - the ids a requisite refers to, out of all of the law ids.
+// Inside a module instance laws in the same instance are looked for first,
+// then the instances it's used from, then everywhere. modules::<instance>
+// refers to every law in that instance.
+func resolveRef(ref string, m *moduleInstance, ids []string) []string {
+	ref = strings.ToLower(ref)
+	var candidates []string
+	for ctx := m; ctx != nil; ctx = ctx.parent {
+		candidates = append(candidates, ctx.prefix()+"::"+ref)
+	}
+	candidates = append(candidates, ref)
+
+	for _, candidate := range candidates {
+		var found []string
+		for _, id := range ids {
+			if id == candidate || (isModuleRef(ref) && strings.HasPrefix(id, candidate+"::")) {
+				found = append(found, id)
+			}
+		}
+		if len(found) > 0 {
+			return found
+		}
+	}
+	return nil
+}
@@ -2,9 +2,11 @@ package laws
 
 import (
 	"bytes"
+	"context"
 	"fmt"
 	"io"
 	"os"
+	"strings"
 
 	"github.com/rs/zerolog/log"
 	"gopkg.in/yaml.v3"
@@ -12,113 +14,47 @@ import (
 
 // Mount is a mount point
 type Mount struct {
-	// Name       string
-	Spec       string
+	CommonFields `yaml:"-"`
+
+	Spec       string `yaml:"spec"`
 	MountPoint string `yaml:"mount_point"`
-	Type       string
-	Options    string
-	Freq       int64
-	Pass       int64
-	Present    bool
-
-	// CommonFields
-	Name   string
-	Before []string
-	After  []string
+	Type       string `yaml:"type"`
+	Options    string `yaml:"options"`
+	Freq       int64  `yaml:"freq"`
+	Pass       int64  `yaml:"pass"`
+	Present    bool   `yaml:"present"`
 }
+
+// AbsentMount is a mount point that shouldn't be there
 type AbsentMount struct {
-	// Name       string
-	Spec       string
+	CommonFields `yaml:"-"`
+
+	Spec       string `yaml:"spec"`
 	MountPoint string `yaml:"mount_point"`
-	Type       string
-	Options    string
-	Freq       int64
-	Pass       int64
-
-	// CommonFields
-	Name   string
-	Before []string
-	After  []string
+	Type       string `yaml:"type"`
+	Options    string `yaml:"options"`
+	Freq       int64  `yaml:"freq"`
+	Pass       int64  `yaml:"pass"`
 }
 
 // UnmarshalYAML implements the Unmarshaler interface
 func (m *Mount) UnmarshalYAML(value *yaml.Node) error {
-	// log.Logger = log.With().Str("unmarshalyaml", "mount").Logger()
-
 	m.Freq = 0
 	m.Pass = 0
 	m.Options = "defaults"
 	m.Present = true
 
-	// var tmp interface{}
-	// var newM Mount
 	type rawMount Mount
-	// err := yaml.NewDecoder(strings.NewReader(value.Value)).Decode(&newM)
 	err := value.Decode((*rawMount)(m)) // this goes into an infinite loop
 	if err != nil && err != io.EOF {
 		log.Error().Err(err).Msg("failed to decode yaml")
+		return err
 	}
-
-	// log.Trace().
-	// 	Interface("Node", value).
-	// 	Interface("newM", m).
-	// 	Interface("content", value.Content).
-	// 	Str("value", value.Value).
-	// 	Str("shorttag", value.ShortTag()).
-	// 	Str("longtag", value.LongTag()).
-	// 	Str("anchor", value.Anchor).
-	// 	Msgf("%v", value.Value)
-	// if value.Tag != "!!map" {
-	// 	return fmt.Errorf("unable to unmarshal yaml: value not map (%s)", value.Tag)
-	// }
-
-	// for i, node := range value.Content {
-	// 	log.Trace().Interface("node1", node).Msg("")
-	// 	switch node.Value {
-	// 	case "name":
-	// 		m.Name = value.Content[i+1].Value
-	// 	case "spec":
-	// 		m.Spec = value.Content[i+1].Value
-	// 	case "mount-point":
-	// 		m.MountPoint = value.Content[i+1].Value
-	// 	case "type":
-	// 		m.Type = value.Content[i+1].Value
-	// 	case "options":
-	// 		m.Options = value.Content[i+1].Value
-	// 	case "freq":
-	// 		m.Freq, _ = strconv.ParseInt(value.Content[i+1].Value, 10, 64)
-	// 	case "pass":
-	// 		m.Pass, _ = strconv.ParseInt(value.Content[i+1].Value, 10, 64)
-	// 		// common fields
-	// 	case "after":
-	// 		for _, j := range value.Content[i+1].Content {
-	// 			m.After = append(m.After, j.Value)
-	// 		}
-	// 	case "before":
-	// 		for _, j := range value.Content[i+1].Content {
-	// 			m.Before = append(m.Before, j.Value)
-	// 		}
-
-	// 		// case "":
-	// 		// m. = value.Content[i+1].Value
-	// 		// case "":
-	// 		// m. = value.Content[i+1].Value
-	// 		// case "":
-	// 		// m. = value.Content[i+1].Value
-	// 		// case "":
-	// 		// m. = value.Content[i+1].Value
-	// 	}
-	// }
-	// log.Trace().Interface("m", m).Msg("what's in the box?!?!")
-
-	// os.Exit(0)
-
 	return nil
-
 }
-func (m *AbsentMount) UnmarshalYAML(value *yaml.Node) error {
-	// log.Logger = log.With().Str("unmarshalyaml", "mount").Logger()
 
+// UnmarshalYAML implements the Unmarshaler interface
+func (m *AbsentMount) UnmarshalYAML(value *yaml.Node) error {
 	m.Freq = 0
 	m.Pass = 0
 	m.Options = "defaults"
@@ -134,66 +70,90 @@ func (m *AbsentMount) UnmarshalYAML(value *yaml.Node) error {
 
 // Ensure - ensure mount is setup
 // TODO should probably mark fstab as managed by govern
-func (m *Mount) Ensure(pretend bool) error {
+func (m *Mount) Ensure(ctx context.Context, pretend bool) *Result {
 	exists, err := m.Exists()
 	if err != nil {
 		log.Debug().Err(err).Bool("mount", exists).Msg("")
 	}
+	fstabLine := fmt.Sprintf("%s\t%s\t%s\t%s\t%d %d\n", m.Spec, m.MountPoint, m.Type, m.Options, m.Freq, m.Pass)
 	if pretend {
 		if m.Present {
 			if exists {
 				log.Info().Msgf("mount already setup: %s (%s)", m.Spec, m.MountPoint)
-			} else {
-				log.Info().Msgf("would add mount: %s (%s)", m.Spec, m.MountPoint)
-			}
-		} else {
-			if exists {
-				log.Info().Str("mountpoint", m.MountPoint).Str("spec", m.Spec).Msg("mount exists, but shouldn't, removing")
+				return Unchanged("mount already setup")
 			}
+			log.Info().Msgf("would add mount: %s (%s)", m.Spec, m.MountPoint)
+			return Changed("mount would be added", "", strings.TrimSpace(fstabLine))
 		}
-	} else {
 		if exists {
-			log.Debug().Msgf("mount already setup: %s (%s)", m.Spec, m.MountPoint)
-		} else {
-			// TODO make the d
-			// this is the only spot we actually have to do anything other than log
-			log.Debug().Msgf("mount being setup: %s (%s)", m.Spec, m.MountPoint)
-			// vers, err := p.Install()
-			// if err != nil {
-			// log.Fatal().Err(err).Msgf("Failed to pkg.Install(): %#v", p)
-			// }
-			// log.Debug().Msgf("Package installed with version: %s", vers)
-			fstabLine := fmt.Sprintf("%s\t%s\t%s\t%s\t%d %d\n", m.Spec, m.MountPoint, m.Type, m.Options, m.Freq, m.Pass)
-			f, err := os.OpenFile("/etc/fstab", os.O_APPEND|os.O_WRONLY, 0644)
-			if err != nil {
-				log.Error().Err(err).Msg("failed to open fstab")
-
-			}
-			defer f.Close()
-			if _, err := f.WriteString(fstabLine); err != nil {
-				log.Error().Err(err).Msg("failed to write mountpoint to fstab")
-			}
+			log.Info().Str("mountpoint", m.MountPoint).Str("spec", m.Spec).Msg("mount exists, but shouldn't, removing")
+			return Changed("mount would be removed", m.Spec, "")
 		}
+		return Unchanged("mount absent")
 	}
 
-	return nil
+	if exists {
+		log.Debug().Msgf("mount already setup: %s (%s)", m.Spec, m.MountPoint)
+		return Unchanged("mount already setup")
+	}
+	// TODO make the d
+	// this is the only spot we actually have to do anything other than log
+	log.Debug().Msgf("mount being setup: %s (%s)", m.Spec, m.MountPoint)
+	// vers, err := p.Install()
+	// if err != nil {
+	// log.Fatal().Err(err).Msgf("Failed to pkg.Install(): %#v", p)
+	// }
+	// log.Debug().Msgf("Package installed with version: %s", vers)
+	f, err := os.OpenFile("/etc/fstab", os.O_APPEND|os.O_WRONLY, 0644)
+	if err != nil {
+		log.Error().Err(err).Msg("failed to open fstab")
+		return Failed("failed to open fstab", err)
+	}
+	defer f.Close()
+	if _, err := f.WriteString(fstabLine); err != nil {
+		log.Error().Err(err).Msg("failed to write mountpoint to fstab")
+		return Failed("failed to write mountpoint to fstab", err)
+	}
+
+	return Changed("mount added", "", strings.TrimSpace(fstabLine))
 }
-func (m *AbsentMount) Ensure(pretend bool) error {
+
+// Ensure - ensure mount isn't setup
+func (m *AbsentMount) Ensure(ctx context.Context, pretend bool) *Result {
 	exists, err := m.Exists()
 	if err != nil {
 		log.Debug().Err(err).Bool("mount", exists).Msg("")
 	}
+	if !exists {
+		return Unchanged("mount absent")
+	}
 	if pretend {
-		if exists {
-			log.Info().Str("mountpoint", m.MountPoint).Str("spec", m.Spec).Msg("mount exists, but shouldn't, removing")
-		}
-	} else {
-		if exists {
-			log.Debug().Str("spec", m.Spec).Msg("mount absent unimpl")
-		}
+		log.Info().Str("mountpoint", m.MountPoint).Str("spec", m.Spec).Msg("mount exists, but shouldn't, removing")
+		return Changed("mount would be removed", m.Spec, "")
 	}
+	log.Debug().Str("spec", m.Spec).Msg("mount absent unimpl")
 
-	return nil
+	return Skipped("removing mounts is not implemented yet")
+}
+
+// ManagedFiles - mounts are added to /etc/fstab
+func (m *Mount) ManagedFiles() []string {
+	return []string{"/etc/fstab"}
+}
+
+// ManagedFiles - mounts are removed from /etc/fstab
+func (m *AbsentMount) ManagedFiles() []string {
+	return []string{"/etc/fstab"}
+}
+
+// LockKey - mounts all share /etc/fstab
+func (m *Mount) LockKey() string {
+	return "/etc/fstab"
+}
+
+// LockKey - mounts all share /etc/fstab
+func (m *AbsentMount) LockKey() string {
+	return "/etc/fstab"
 }
 
 // Exists - check if mountpoint exists
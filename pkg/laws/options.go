@@ -0,0 +1,90 @@
+// Copyright © 2026 Iggy <iggy@theiggy.com>
+// All rights reserved.
+//
+// Redistribution and use in source and binary forms, with or without
+// modification, are permitted provided that the following conditions are met:
+//
+// 1. Redistributions of source code must retain the above copyright notice,
+//    this list of conditions and the following disclaimer.
+//
+// 2. Redistributions in binary form must reproduce the above copyright notice,
+//    this list of conditions and the following disclaimer in the documentation
+//    and/or other materials provided with the distribution.
+//
+// 3. Neither the name of the copyright holder nor the names of its contributors
+//    may be used to endorse or promote products derived from this software
+//    without specific prior written permission.
+//
+// THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS "AS IS"
+// AND ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT LIMITED TO, THE
+// IMPLIED WARRANTIES OF MERCHANTABILITY AND FITNESS FOR A PARTICULAR PURPOSE
+// ARE DISCLAIMED. IN NO EVENT SHALL THE COPYRIGHT HOLDER OR CONTRIBUTORS BE
+// LIABLE FOR ANY DIRECT, INDIRECT, INCIDENTAL, SPECIAL, EXEMPLARY, OR
+// CONSEQUENTIAL DAMAGES (INCLUDING, BUT NOT LIMITED TO, PROCUREMENT OF
+// SUBSTITUTE GOODS OR SERVICES; LOSS OF USE, DATA, OR PROFITS; OR BUSINESS
+// INTERRUPTION) HOWEVER CAUSED AND ON ANY THEORY OF LIABILITY, WHETHER IN
+// CONTRACT, STRICT LIABILITY, OR TORT (INCLUDING NEGLIGENCE OR OTHERWISE)
+// ARISING IN ANY WAY OUT OF THE USE OF THIS SOFTWARE, EVEN IF ADVISED OF THE
+// POSSIBILITY OF SUCH DAMAGE.
+
+package laws
+
+import (
+	"context"
+	"fmt"
+	"os/exec"
+	"time"
+)
+
+// Options - settings every law accepts that are handled when running the
+// law rather than by the law itself. They are decoded from the same yaml as
+// the law, so they don't have to be added to every law struct.
+type Options struct {
+	Watch  []string `yaml:"watch"`  // like after, but also react when a watched law changes
+	Notify []string `yaml:"notify"` // the reverse of watch, make other laws react to this one
+
+	OnError ErrorPolicy `yaml:"on_error"` // override the run's error policy for this law
+
+	OnlyIf string `yaml:"onlyif"` // shell command that has to succeed for the law to run
+	Unless string `yaml:"unless"` // shell command that has to fail for the law to run
+	When   *When  `yaml:"when"`   // expression on the facts that has to be true for the law to run
+
+	Retry *RetryOpts `yaml:"retry"` // try again if the law fails
+
+	Timeout time.Duration `yaml:"timeout"` // give up on each attempt after this long, i.e. 5m
+
+	Tags []string `yaml:"tags"` // for picking laws to run with --tags
+
+	File string `yaml:"-"` // the laws file this law came from
+}
+
+// guard - check onlyif/unless/when, returns why the law shouldn't run or
+// "" if it should. A when that can't be checked is an error, not a reason
+// to skip the law.
+func (o *Options) guard(ctx context.Context) (string, error) {
+	if o == nil {
+		return "", nil
+	}
+	if o.When != nil {
+		ok, err := o.When.Eval()
+		if err != nil {
+			return "", fmt.Errorf("when %q couldn't be checked: %w", o.When, err)
+		}
+		if !ok {
+			return fmt.Sprintf("when %q is false", o.When), nil
+		}
+	}
+	if o.OnlyIf != "" {
+		_, _, err := runCmd(ctx, exec.CommandContext(ctx, "/bin/sh", "-c", o.OnlyIf))
+		if err != nil {
+			return fmt.Sprintf("onlyif %q failed: %v", o.OnlyIf, err), nil
+		}
+	}
+	if o.Unless != "" {
+		_, _, err := runCmd(ctx, exec.CommandContext(ctx, "/bin/sh", "-c", o.Unless))
+		if err == nil {
+			return fmt.Sprintf("unless %q succeeded", o.Unless), nil
+		}
+	}
+	return "", nil
+}
@@ -2,17 +2,18 @@ package laws
 
 import (
 	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
 	"io/fs"
 	"os"
 	"path/filepath"
-	"reflect"
+	"sort"
 	"strings"
 	"text/template"
 
-	"dario.cat/mergo"
-	"github.com/Masterminds/sprig/v3"
 	"github.com/hmdsefi/gograph"
-	"github.com/iggy/govern/pkg/facts"
 	"github.com/rs/zerolog/log"
 	"gopkg.in/yaml.v3"
 )
@@ -24,6 +25,15 @@ type LawNode struct {
 	Group string
 	Type  string
 	Name  string
+
+	Before  []string
+	After   []string
+	Options *Options
+	File    string   // the laws file this came from
+	Module  string   // the module instance this came from, i.e. modules::blog
+	Watches []string // ids of the laws this one reacts to, from watch and notify
+
+	module *moduleInstance
 }
 
 type Root struct {
@@ -31,12 +41,246 @@ type Root struct {
 }
 
 // Ensure - just to fulfill the interface
-func (r *Root) Ensure(bool) error {
-	return nil
+func (r *Root) Ensure(context.Context, bool) *Result {
+	return Unchanged("")
 }
 
 // var graph gograph.Graph[*LawNode]
 
+// findVertices - find the vertices for a group::type::name requisite, or
+// all of the laws in a module instance for a modules::<instance> one
+func findVertices(byID map[string]*gograph.Vertex[*LawNode], ids []string, ref string, m *moduleInstance) []*gograph.Vertex[*LawNode] {
+	var found []*gograph.Vertex[*LawNode]
+	for _, id := range resolveRef(ref, m, ids) {
+		found = append(found, byID[id])
+	}
+	if len(found) == 0 {
+		log.Error().Str("requisite", strings.ToLower(ref)).Msg("couldn't find law for requisite")
+	}
+	return found
+}
+
+// addRequisite - make "to" come after "from", which means it doesn't need
+// to hang off of the root anymore
+func addRequisite(graph gograph.Graph[*LawNode], root, from, to *gograph.Vertex[*LawNode]) {
+	if from == nil || to == nil {
+		return
+	}
+	_, err := graph.AddEdge(from, to)
+	if err != nil && err != gograph.ErrEdgeAlreadyExists {
+		log.Error().Err(err).
+			Str("from", from.Label().ID()).
+			Str("to", to.Label().ID()).
+			Msg("failed to add edge")
+		return
+	}
+	graph.RemoveEdges(graph.GetAllEdges(root, to)...)
+}
+
+// Stdin - the laws file name that means read the laws from stdin
+const Stdin = "-"
+
+// lawsFiles - the laws files to parse, a single file (or - for stdin) or all
+// of the yaml files in a directory of laws, in lexical order. Module
+// directories under it are skipped, they're only read through modules:, and
+// so is the vars directory
+func lawsFiles(path string) ([]string, error) {
+	if path == Stdin {
+		return []string{Stdin}, nil
+	}
+	fi, err := os.Stat(path)
+	if err != nil {
+		return nil, err
+	}
+	if !fi.IsDir() {
+		return []string{path}, nil
+	}
+
+	var files []string
+	err = fs.WalkDir(os.DirFS(path),
+		".",
+		func(walkpath string, d fs.DirEntry, walkErr error) error {
+			if walkErr != nil {
+				return walkErr
+			}
+			log.Debug().Interface("d", d).Str("path", walkpath).Msg("processing")
+			if d.IsDir() {
+				if walkpath == VarsDir {
+					return fs.SkipDir
+				}
+				if _, err := os.Stat(filepath.Join(path, walkpath, ModuleFile)); err == nil && walkpath != "." {
+					return fs.SkipDir
+				}
+				return nil
+			}
+			if filepath.Ext(walkpath) != ".yaml" && filepath.Ext(walkpath) != ".yml" {
+				return nil
+			}
+			if d.Name() == ModuleFile {
+				return nil
+			}
+			files = append(files, filepath.Join(path, walkpath))
+			return nil
+		},
+	)
+	// WalkDir goes a directory at a time, so a/b.yaml would come before a.yaml
+	sort.Strings(files)
+	return files, err
+}
+
+// readLaws - the contents of a laws file, or stdin
+func readLaws(lawsFilePath string) ([]byte, error) {
+	if lawsFilePath == Stdin {
+		return io.ReadAll(os.Stdin)
+	}
+	return os.ReadFile(lawsFilePath)
+}
+
+// renderLaws - run a laws file through the templating
+func renderLaws(src *lawsSource) ([]byte, error) {
+	content, err := readLaws(src.path)
+	if err != nil {
+		return nil, err
+	}
+	return renderTemplate(src.path, content, src)
+}
+
+// renderTemplate - run a laws file or file template through the templating,
+// with the funcs and data from the laws file it's used by
+func renderTemplate(name string, content []byte, src *lawsSource) ([]byte, error) {
+	var lawsWr bytes.Buffer
+	// this is kind of weird, but you can't have / in the template name
+	tmpl, err := template.New(filepath.Base(name)).
+		Funcs(templateFuncs(src)).
+		Parse(string(content))
+	if err != nil {
+		return nil, err
+	}
+	log.Trace().Interface("tmpl", tmpl).Msg("what is tmpl?")
+	log.Trace().Interface("tmpls", tmpl.Templates()).Msg("what tmpls?")
+	err = tmpl.Execute(&lawsWr, src.templateData())
+	return lawsWr.Bytes(), err
+}
+
+// decodeLaws - decode the laws in a rendered laws file using the registered
+// kinds, appending them to found. Like the old mergo.WithAppendSlice merge,
+// laws from later documents and files are added to the end. Returns the
+// files pulled in with include and modules.
+func decodeLaws(rendered []byte, src *lawsSource, found map[*Kind][]*LawNode) ([]*lawsSource, error) {
+	var more []*lawsSource
+	// each document in the file is handled like a separate file, so their
+	// laws get appended together the same way
+	dec := yaml.NewDecoder(bytes.NewReader(rendered))
+	for {
+		var node yaml.Node
+		err := dec.Decode(&node)
+		if errors.Is(err, io.EOF) {
+			return more, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		doc, include, modules, err := splitDocument(&node)
+		if err != nil {
+			return nil, err
+		}
+		err = decodeDocument(doc, src, found)
+		if err != nil {
+			return nil, err
+		}
+		sources, err := src.expand(include, modules)
+		if err != nil {
+			return nil, err
+		}
+		more = append(more, sources...)
+	}
+}
+
+// splitDocument - split a laws document into the law groups and the
+// include and modules keys
+func splitDocument(node *yaml.Node) (doc map[string]map[string][]yaml.Node, include, modules *yaml.Node, err error) {
+	groups := map[string]yaml.Node{}
+	err = node.Decode(&groups)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	doc = map[string]map[string][]yaml.Node{}
+	for group, value := range groups {
+		value := value
+		switch group {
+		case "include":
+			include = &value
+			continue
+		case "modules":
+			modules = &value
+			continue
+		}
+		types := map[string][]yaml.Node{}
+		err = value.Decode(&types)
+		if err != nil {
+			return nil, nil, nil, err
+		}
+		doc[group] = types
+	}
+	return doc, include, modules, nil
+}
+
+// decodeDocument - decode the laws in a single yaml document
+func decodeDocument(doc map[string]map[string][]yaml.Node, src *lawsSource, found map[*Kind][]*LawNode) error {
+	lawsFilePath := src.path
+	// a typo would otherwise leave the laws silently unapplied
+	var unknown []string
+	for group, types := range doc {
+		for typ := range types {
+			if findKind(group, typ) == nil {
+				unknown = append(unknown, group+"."+typ)
+			}
+		}
+	}
+	if len(unknown) > 0 {
+		sort.Strings(unknown)
+		return fmt.Errorf("unknown law type %s", strings.Join(unknown, ", "))
+	}
+
+	// go through the kinds rather than the map so the order doesn't change
+	// from run to run
+	for _, kind := range registeredKinds() {
+		group, typ := kind.yamlKeys()
+		for i := range doc[group][typ] {
+			value := &doc[group][typ][i]
+			law, opts, err := decodeLaw(kind, value)
+			if err != nil {
+				return fmt.Errorf("%s: %w", kind.Path, err)
+			}
+			opts.File = lawsFilePath
+			if ft, ok := law.(*FileTemplate); ok && ft.TemplatePath != "" {
+				err = ft.render(src)
+				if err != nil {
+					return fmt.Errorf("%s: %w", kind.Path, err)
+				}
+			}
+
+			common := kind.common(law)
+			node := &LawNode{
+				Law:     law,
+				Group:   kind.group(),
+				Type:    kind.typ(),
+				Name:    strings.ToLower(common.Name),
+				Before:  common.Before,
+				After:   common.After,
+				Options: opts,
+				File:    lawsFilePath,
+				module:  src.module,
+			}
+			if src.module != nil {
+				node.Module = src.module.prefix()
+			}
+			found[kind] = append(found[kind], node)
+		}
+	}
+	return nil
+}
+
 // ParseFiles - parse a file or directory of yaml files to get the laws
 // This is a total pain... either I screw myself on the logic by making
 // everything a struct or I screw myself on the parsing by using maps and
@@ -44,25 +288,13 @@ func (r *Root) Ensure(bool) error {
 func ParseFiles(path string) ([]*gograph.Vertex[*LawNode], error) {
 	log.Trace().Str("path", path).Msg("parsing files")
 
-	laws := &Laws3{}
+	// every law found, by kind, in the order the files were read
+	found := map[*Kind][]*LawNode{}
 	// laws := NewLaws[string]()
-	// laws := &Laws3{
-	// 	struct{ Present []User }{
-	// 		Present: []User{
-	// 			{Name: "root"},
-	// 			{Name: "iggy"},
-	// 		},
-	// 	},
-	// }
 	// // laws := map[string]map[string][]interface{}{}
 
-	// yamlOut, _ := yaml.Marshal(laws)
-	// log.Debug().Bytes("yaml", yamlOut).Msg("yaml Laws3")
-
-	// return nil
-
 	graph := gograph.New[*LawNode](gograph.Acyclic())
-	rootVertex := gograph.NewVertex[*LawNode](&LawNode{&Root{Name: "root"}, "root", "root", "root"})
+	rootVertex := gograph.NewVertex[*LawNode](&LawNode{Law: &Root{Name: "root"}, Group: "root", Type: "root", Name: "root"})
 	log.Debug().Interface("rootv", rootVertex).Msg("I'm tired of having to constantly (un)comment this")
 	// v2 := gograph.NewVertex[*LawNode](&LawNode{Group{Name: "iggy"}, "group"})
 	// _, err := graph.AddEdge(v1, v2)
@@ -78,67 +310,45 @@ func ParseFiles(path string) ([]*gograph.Vertex[*LawNode], error) {
 	// 	files =
 	// }
 
-	// TODO handle single files
-	fileSystem := os.DirFS(path)
-
-	err := fs.WalkDir(fileSystem,
-		".",
-		func(walkpath string, d fs.DirEntry, walkErr error) error {
-			log.Debug().Interface("d", d).Str("path", walkpath).Msg("processing")
-			if d.IsDir() {
-				return nil
-			}
-			if filepath.Ext(walkpath) != ".yaml" && filepath.Ext(walkpath) != ".yml" {
-				return nil
-			}
-
-			loopLaws := &Laws3{}
-
-			fi, err := d.Info()
-			if err != nil {
-				log.Error().Err(err).Str("path", walkpath).Msg("failed to get info for direntry")
-			}
-			log.Debug().Interface("fileinfo", fi).Interface("sys", fi.Sys()).Msg("")
-			lawsFilePath := filepath.Join(path, walkpath)
-
-			// setup templating
-			var lawsWr bytes.Buffer
-			funcMap := sprig.GenericFuncMap()
-			// this is kind of weird, but you can't have / in the template name
-			tmpl := template.Must(
-				template.New(filepath.Base(walkpath)).
-					Funcs(funcMap).
-					ParseFiles(lawsFilePath),
-			)
-			log.Trace().Interface("tmpl", tmpl).Msg("what is tmpl?")
-			log.Trace().Interface("tmpls", tmpl.Templates()).Msg("what tmpls?")
-			err = tmpl.Execute(&lawsWr, map[string]interface{}{"facts": facts.Facts}) // TODO pass more stuff to templates
-			rendered := lawsWr.Bytes()
-			if err != nil {
-				log.Error().Err(err).Bytes("rendered", rendered).Msg("failed to execute tmpl")
-				return err
-			}
-			log.Trace().Bytes("rendered", rendered).Msg("")
-
-			err = yaml.Unmarshal(rendered, loopLaws)
-			if err != nil {
-				log.Warn().Err(err).Str("file", walkpath).Msg("Error loading YAML")
-				return err
-			}
-
-			log.Debug().Interface("loopLaws", loopLaws).Msg("")
-			err = mergo.Merge(laws, loopLaws, mergo.WithAppendSlice)
-			if err != nil {
-				log.Error().Err(err).Msg("failed to mergo")
-			}
+	queue, err := sourcesFor(path, nil)
+	if err != nil {
+		log.Error().Err(err).Str("path", path).Msg("failed to find laws files")
+		return nil, err
+	}
+	vars, err := LoadVars(path)
+	if err != nil {
+		log.Error().Err(err).Str("path", path).Msg("failed to load vars")
+		return nil, err
+	}
+	for _, src := range queue {
+		src.vars = vars
+	}
+	// included files and module instances get added to the end as they're
+	// found, a file is only read once (per module instance) so include loops
+	// don't go on forever
+	seen := map[string]bool{}
+	for len(queue) > 0 {
+		src := queue[0]
+		queue = queue[1:]
+		if seen[src.key()] {
+			continue
+		}
+		seen[src.key()] = true
+		lawsFilePath := src.path
 
-			log.Trace().Interface("laws", laws).Msg("")
+		rendered, err := renderLaws(src)
+		if err != nil {
+			log.Error().Err(err).Bytes("rendered", rendered).Msg("failed to execute tmpl")
+			return nil, err
+		}
+		log.Trace().Bytes("rendered", rendered).Msg("")
 
-			return nil
-		},
-	)
-	if err != nil {
-		log.Error().Msg("")
+		more, err := decodeLaws(rendered, src, found)
+		if err != nil {
+			log.Warn().Err(err).Str("file", lawsFilePath).Msg("Error loading YAML")
+			return nil, fmt.Errorf("%s: %w", lawsFilePath, err)
+		}
+		queue = append(queue, more...)
 	}
 
 	// for _, v := range laws.Users {
@@ -218,201 +428,92 @@ func ParseFiles(path string) ([]*gograph.Vertex[*LawNode], error) {
 	// 	graph.AddEdge(v1, vtx)
 	// }
 
-	l1Values := reflect.ValueOf(*laws)
-	l1Types := l1Values.Type()
-	log.Debug().
-		Interface("l1values", l1Values.Type().Name()).
-		Interface("l1Types", l1Types.Name()).
-		Msg("l1")
-
-		// add all the nodes to the graph first
-		// this loop is over users/groups/pkgs/etc structs
-	for i := 0; i < l1Values.NumField(); i++ {
-		lawsGroup := l1Types.Field(i).Name // users/groups/pkgs/etc
-		l2Values := reflect.ValueOf(l1Values.Field(i).Interface())
-		l2Types := l2Values.Type()
-		// log.Debug().
-		// 	Str("lg", lawGroup).
-		// 	Interface("value", l1Values.Field(i)).
-		// 	Interface("l2values", l2Values).
-		// 	Interface("l2Types", l2Types).
-		// 	Msgf("l1 kv: %v - %v", l1Values.Field(i).Interface(), l2Types)
-
-		// this loop is over present/installed/running/etc
-		for j := 0; j < l2Values.NumField(); j++ {
-			lawsType := l2Types.Field(j).Name
-			l3Values := reflect.ValueOf(l2Values.Field(j).Interface())
-			// l3Types := l3Values.Type()
-			// log.Debug().
-			// 	Str("name", l2Types.Field(j).Name).
-			// 	Str("lg", lawGroup).
-			// 	Interface("lgs", lawGroupSetting).
-			// 	Interface("value", l2Values.Field(j).Interface()).
-			// 	// Interface("l3Values", l3Values).
-			// 	// Interface("l3Types", l3Types).
-			// 	Msgf("l2 kv: v: %v - t: %v", l3Values, l3Types)
-			// log.Debug().Msgf("l3: %v", l3Values.Slice(0, l3Values.Len()))
-			// v := l2Values.Field(j).Interface()
-
-			// this loop is over the array of user/group/filetemplate/etc
-			for k := 0; k < l3Values.Len(); k++ {
-				// for _, k := range l3Values.Slice(0, l3Values.Len()) {
-				m := l3Values.Index(k)
-				// log.Info().Interface("m", m).Msgf("k loop: %v", m)
-				lawsName := m.Elem().FieldByName("Name").String()
-				// before := m.FieldByName("Before")
-				// after := m.FieldByName("After")
-				vGroup := strings.ToLower(lawsGroup)
-				vType := strings.ToLower(lawsType)
-				vName := strings.ToLower(lawsName)
-				log.Trace().
-					Str("vGroup", vGroup).
-					Str("vType", vType).
-					Str("vName", vName).
-					Msg("load graph loop")
-				// log.Debug().Msgf("l4a: %v - %v", m, m.Type())
-				// log.Debug().Msgf("l4b: %v - %v", after, before)
-
-				// vtx := gograph.NewVertex[*LawNode](&LawNode{m, lawGroup, lawGroupSetting})
-				vtx := gograph.NewVertex[*LawNode](
-					&LawNode{
-						Law:   m.Interface().(Law),
-						Group: vGroup,
-						Type:  vType,
-						Name:  vName,
-					},
-				)
-				log.Debug().
-					Str("type", vtx.Label().Type).
-					Str("name", vtx.Label().Name).
-					Msgf("l2 vtx: %v", vtx)
-				_, err := graph.AddEdge(rootVertex, vtx)
-				if err != nil {
-					log.Error().Err(err).
-						Str("law name", vName).
-						Str("law type", vType).
-						Str("law group", vGroup).
-						Msg("failed to add edge to root")
-				}
+	var vertices []*gograph.Vertex[*LawNode]
+
+	// add all the nodes to the graph first, kind by kind
+	for _, kind := range registeredKinds() {
+		for _, node := range found[kind] {
+			log.Trace().
+				Str("vGroup", node.Group).
+				Str("vType", node.Type).
+				Str("vName", node.Name).
+				Msg("load graph loop")
+
+			vtx := gograph.NewVertex[*LawNode](node)
+			log.Debug().
+				Str("type", vtx.Label().Type).
+				Str("name", vtx.Label().Name).
+				Msgf("l2 vtx: %v", vtx)
+			_, err := graph.AddEdge(rootVertex, vtx)
+			if err != nil {
+				log.Error().Err(err).
+					Str("law name", node.Name).
+					Str("law type", node.Type).
+					Str("law group", node.Group).
+					Msg("failed to add edge to root")
 			}
+			vertices = append(vertices, vtx)
+		}
+	}
+
+	byID := map[string]*gograph.Vertex[*LawNode]{}
+	var ids []string
+	for _, vtx := range vertices {
+		id := vtx.Label().ID()
+		if _, ok := byID[id]; ok {
+			log.Debug().Str("law", id).Msg("more than one law with the same id, requisites will use the first")
+			continue
 		}
+		byID[id] = vtx
+		ids = append(ids, id)
 	}
 
 	// now setup the deps properly
-	// this loop is over users/groups/pkgs/etc structs
-	for i := 0; i < l1Values.NumField(); i++ {
-		lawGroup := l1Types.Field(i).Name // users/groups/pkgs/etc
-		l2Values := reflect.ValueOf(l1Values.Field(i).Interface())
-		l2Types := l2Values.Type()
+	for _, vtx := range vertices {
+		law := vtx.Label()
 		log.Debug().
-			Str("lg", lawGroup).
-			Interface("value", l1Values.Field(i)).
-			Interface("l2values", l2Values).
-			Interface("l2Types", l2Types).
-			Msgf("l1 kv: %v - %v", l1Values.Field(i).Interface(), l2Types)
-		// this loop is over present/installed/running/etc
-		for j := 0; j < l2Values.NumField(); j++ {
-			lawsType := l2Types.Field(j).Name
-			lawGroupSetting := l2Types.Field(j).Name
-			l3Values := reflect.ValueOf(l2Values.Field(j).Interface())
-			l3Types := l3Values.Type()
-			log.Debug().
-				Str("name", l2Types.Field(j).Name).
-				Str("lg", lawGroup).
-				Interface("lgs", lawGroupSetting).
-				Interface("value", l2Values.Field(j).Interface()).
-				// Interface("l3Values", l3Values).
-				// Interface("l3Types", l3Types).
-				Msgf("l2 kv: v: %v - t: %v", l3Values, l3Types)
-			log.Debug().Msgf("l3: %v", l3Values.Slice(0, l3Values.Len()))
-			// v := l2Values.Field(j).Interface()
-
-			// this loop is over the array of user/group/filetemplate/etc
-			for k := 0; k < l3Values.Len(); k++ {
-				// for _, k := range l3Values.Slice(0, l3Values.Len()) {
-				m := l3Values.Index(k)
-				before := m.Elem().FieldByName("Before")
-				after := m.Elem().FieldByName("After")
-				vGroup := strings.ToLower(lawGroup)
-				vType := strings.ToLower(lawsType)
-				vName := strings.ToLower(m.Elem().FieldByName("Name").String())
-				log.Debug().Msgf("l4a: %v - %v", m, m.Type())
-				log.Debug().Msgf("l4b: %v - %v", after, before)
-
-				// vtx := gograph.NewVertex[*LawNode](&LawNode{m, lawGroup, lawGroupSetting})
-				// vtx := gograph.NewVertex[*LawNode](
-				// 	&LawNode{
-				// 		Law:  m,
-				// 		Type: vType,
-				// 		Name: vName,
-				// 	},
-				// )
-				// log.Debug().Str("type", vtx.Label().Type).Str("name", vtx.Label().Name).Msgf("l2 vtx: %v", vtx)
-				// graph.AddEdge(rootVertex, vtx)
-				// v2 := l3Values.Index(k).Interface().(User)
-				// v2 := l3Values.Index(k).Elem().Convert(l3Values.Index(k).Type())
-				// var v interface{}
-				// switch vt := l3Values.Index(k).Interface().(type) {
-				// case User:
-				// 	v = l3Values.Index(k).Interface().(User)
-				// 	log.Debug().Interface("switch type", vt).Interface("v", v).Msg("")
-				// }
-				// log.Debug().Interface("v", v).Msg("")
-				// v := l3Values.Index(k).Interface().(CommonFields)
-				// if v2.After != nil {
-				log.Debug().Msg("v2 after")
-				// for _, dep := range m.FieldByName("After").Slice(0, m.Len()) {
-				for n := 0; n < after.Len(); n++ {
-					dep := after.Index(n).String()
-					log.Trace().Str("dep", dep).Msg("found dep, removing old connections")
-					// log.Debug().Interface("v", v2).Str("dep", dep).Msg("stuff")
-					depSplit := strings.SplitN(dep, "::", 3)
-					depGroup := depSplit[0]
-					depType := depSplit[1]
-					depName := depSplit[2]
-					log.Trace().
-						Str("depGroup", depGroup).
-						Str("depType", depType).
-						Str("depName", depName).
-						Str("vGroup", vGroup).
-						Str("vType", vType).
-						Str("vName", vName).
-						Msg("after loop")
-					var aVertex, bVertex *gograph.Vertex[*LawNode]
-					for _, vtx := range graph.GetAllVertices() {
-						log.Debug().
-							Interface("vertex", vtx).
-							Str("group", vtx.Label().Group).
-							Str("type", vtx.Label().Type).
-							Str("name", vtx.Label().Name).
-							Msgf("vertex: %v", vtx)
-						if vtx.Label().Group == depGroup && vtx.Label().Type == depType && vtx.Label().Name == depName {
-							log.Debug().Interface("vertex", vtx.Label().Name).Msg("found bvertex")
-							bVertex = vtx
-						}
-						if vtx.Label().Group == vGroup && vtx.Label().Type == vType && vtx.Label().Name == vName {
-							aVertex = vtx
-						}
-					}
-					// aVertex := graph.GetVertexByID(&LawNode{v, "user", v.Name})
-					_, err := graph.AddEdge(bVertex, aVertex)
-					if err != nil {
-						log.Error().Err(err).
-							Str("law name", vName).
-							Str("law type", vType).
-							Str("law group", vGroup).
-							Msg("failed to add edge")
-
-					}
-					graph.RemoveEdges(graph.GetAllEdges(rootVertex, aVertex)...)
-					log.Debug().
-						Str("user", m.String()).
-						Interface("aVertex", aVertex).
-						Interface("bVertex", bVertex).
-						Msgf("%v | %v", aVertex, bVertex)
+			Strs("after", law.After).
+			Strs("before", law.Before).
+			Strs("watch", law.Options.Watch).
+			Strs("notify", law.Options.Notify).
+			Msgf("requisites: %s", law.ID())
+
+		// after/watch - this law comes after the dep
+		for _, dep := range append(law.After, law.Options.Watch...) {
+			for _, depVertex := range findVertices(byID, ids, dep, law.module) {
+				addRequisite(graph, rootVertex, depVertex, vtx)
+			}
+		}
+		// before/notify - the dep comes after this law
+		for _, dep := range append(law.Before, law.Options.Notify...) {
+			for _, depVertex := range findVertices(byID, ids, dep, law.module) {
+				addRequisite(graph, rootVertex, vtx, depVertex)
+			}
+		}
+		// the module instances this law is in can have requisites too, they
+		// apply to every law in the instance and are looked up from where
+		// the instance was used
+		for m := law.module; m != nil; m = m.parent {
+			for _, dep := range m.After {
+				for _, depVertex := range findVertices(byID, ids, dep, m.parent) {
+					addRequisite(graph, rootVertex, depVertex, vtx)
 				}
-
-				// }
+			}
+			for _, dep := range m.Before {
+				for _, depVertex := range findVertices(byID, ids, dep, m.parent) {
+					addRequisite(graph, rootVertex, vtx, depVertex)
+				}
+			}
+		}
+		// watch/notify - the watching law reacts if the watched law changes
+		for _, dep := range law.Options.Watch {
+			for _, depVertex := range findVertices(byID, ids, dep, law.module) {
+				law.Watches = append(law.Watches, depVertex.Label().ID())
+			}
+		}
+		for _, dep := range law.Options.Notify {
+			for _, depVertex := range findVertices(byID, ids, dep, law.module) {
+				depVertex.Label().Watches = append(depVertex.Label().Watches, law.ID())
 			}
 		}
 	}
@@ -0,0 +1,94 @@
+// Copyright © 2026 Iggy <iggy@theiggy.com>
+// All rights reserved.
+//
+// Redistribution and use in source and binary forms, with or without
+// modification, are permitted provided that the following conditions are met:
+//
+// 1. Redistributions of source code must retain the above copyright notice,
+//    this list of conditions and the following disclaimer.
+//
+// 2. Redistributions in binary form must reproduce the above copyright notice,
+//    this list of conditions and the following disclaimer in the documentation
+//    and/or other materials provided with the distribution.
+//
+// 3. Neither the name of the copyright holder nor the names of its contributors
+//    may be used to endorse or promote products derived from this software
+//    without specific prior written permission.
+//
+// THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS "AS IS"
+// AND ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT LIMITED TO, THE
+// IMPLIED WARRANTIES OF MERCHANTABILITY AND FITNESS FOR A PARTICULAR PURPOSE
+// ARE DISCLAIMED. IN NO EVENT SHALL THE COPYRIGHT HOLDER OR CONTRIBUTORS BE
+// LIABLE FOR ANY DIRECT, INDIRECT, INCIDENTAL, SPECIAL, EXEMPLARY, OR
+// CONSEQUENTIAL DAMAGES (INCLUDING, BUT NOT LIMITED TO, PROCUREMENT OF
+// SUBSTITUTE GOODS OR SERVICES; LOSS OF USE, DATA, OR PROFITS; OR BUSINESS
+// INTERRUPTION) HOWEVER CAUSED AND ON ANY THEORY OF LIABILITY, WHETHER IN
+// CONTRACT, STRICT LIABILITY, OR TORT (INCLUDING NEGLIGENCE OR OTHERWISE)
+// ARISING IN ANY WAY OUT OF THE USE OF THIS SOFTWARE, EVEN IF ADVISED OF THE
+// POSSIBILITY OF SUCH DAMAGE.
+
+package laws
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+)
+
+func TestParseFilesRequisites(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "laws.yaml")
+	err := os.WriteFile(path, []byte(`files:
+  templates:
+    - name: /tmp/config
+      text: config
+      before: [files::templates::/tmp/late]
+      notify: [files::templates::/tmp/notified]
+    - name: /tmp/late
+      text: late
+    - name: /tmp/notified
+      text: notified
+    - name: /tmp/watcher
+      text: watcher
+      watch: [files::templates::/tmp/late]
+`), 0o644)
+	if err != nil {
+		t.Fatal(err)
+	}
+	sorted, err := ParseFiles(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	g := NewLawGraph(sorted)
+	order := map[string]int{}
+	for i, n := range g.Nodes {
+		order[n.ID()] = i
+	}
+
+	tests := []struct {
+		first, then string
+		watches     bool // then reacts to first changing
+	}{
+		{"files::templates::/tmp/config", "files::templates::/tmp/late", false},
+		{"files::templates::/tmp/config", "files::templates::/tmp/notified", true},
+		{"files::templates::/tmp/late", "files::templates::/tmp/watcher", true},
+	}
+	for _, tt := range tests {
+		first, then := g.Find(tt.first), g.Find(tt.then)
+		if order[tt.first] > order[tt.then] {
+			t.Errorf("%s runs before %s", tt.then, tt.first)
+		}
+		if !slices.Contains(g.children[first], then) {
+			t.Errorf("no edge from %s to %s", tt.first, tt.then)
+		}
+		if slices.ContainsFunc(g.parents[then], isRoot) {
+			t.Errorf("%s still hangs off of the root", tt.then)
+		}
+		if got := slices.Contains(then.Watches, tt.first); got != tt.watches {
+			t.Errorf("%s watches %v, want watching %s %v", tt.then, then.Watches, tt.first, tt.watches)
+		}
+	}
+	if w := g.Find("files::templates::/tmp/late").Watches; len(w) != 0 {
+		t.Errorf("before made files::templates::/tmp/late watch %v", w)
+	}
+}
@@ -39,9 +39,9 @@ package laws
 
 import (
 	"bytes"
+	"context"
 	"fmt"
 	"os/exec"
-	"strconv"
 	"strings"
 
 	"github.com/iggy/govern/pkg/facts"
@@ -51,14 +51,10 @@ import (
 
 // Package - package info
 type Package struct {
-	// Name    string
-	Version   string `yaml:",omitempty"`
-	Installed bool   `yaml:",omitempty"` // whether the package should be installed or removed
-
-	// CommonFields
-	Name   string
-	Before []string
-	After  []string
+	CommonFields `yaml:"-"`
+
+	Version   string `yaml:"version"`
+	Installed bool   `yaml:"installed"` // whether the package should be installed or removed
 }
 
 // UnmarshalYAML - This fills in default values if they aren't specified
@@ -66,55 +62,21 @@ func (p *Package) UnmarshalYAML(value *yaml.Node) error {
 	// defaults
 	p.Installed = true
 	p.Version = ""
-	var err error // for use in the switch below
-
 	log.Trace().Interface("Node", value).Msg("UnmarshalYAML Package")
-	if value.Tag != "!!map" {
-		return fmt.Errorf("unable to unmarshal yaml: value not map (%s)", value.Tag)
-	}
-
-	for i, node := range value.Content {
-		log.Trace().Interface("node1", node).Msg("pkg unmarshal")
-		switch node.Value {
-		case "name":
-			p.Name = value.Content[i+1].Value
-			if p.Name == "" {
-				return nil
-			}
-		case "version":
-			p.Version = value.Content[i+1].Value
-		case "installed":
-			p.Installed, err = strconv.ParseBool(value.Content[i+1].Value)
-			if err != nil {
-				log.Error().Err(err).Msg("can't parse installed field")
-				return err
-			}
-		case "before":
-			for _, j := range value.Content[i+1].Content {
-				p.Before = append(p.Before, j.Value)
-			}
-		case "after":
-			for _, j := range value.Content[i+1].Content {
-				p.After = append(p.After, j.Value)
-			}
-		}
-	}
-
-	log.Trace().Interface("pkg", p).Msg("what's in the box?!?!")
-	// *p = *pkg
 
-	return nil
+	type rawPackage Package
+	return value.Decode((*rawPackage)(p))
 }
 
 // IsInstalled - check if a package is installed
 // true/false whether a package is installed
 // err = nil if we know what distro we are on
-func (p *Package) IsInstalled() (bool, error) {
+func (p *Package) IsInstalled(ctx context.Context) (bool, error) {
 	log.Trace().Interface("Package", p).Msg("pkgInstalled")
 	log.Trace().Interface("Facts", facts.Facts).Msg("what are the facts?")
 	switch facts.Facts.Distro.Family {
 	case "alpine":
-		cmd := exec.Command("apk", "info", "-e", p.Name)
+		cmd := exec.CommandContext(ctx, "apk", "info", "-e", p.Name)
 		var out bytes.Buffer
 		cmd.Stdout = &out
 		err := cmd.Run()
@@ -140,7 +102,7 @@ func (p *Package) IsInstalled() (bool, error) {
 		}
 		return false, nil
 	case "debian":
-		cmd := exec.Command("dpkg-query", "-W", "-f", "${Version}", p.Name)
+		cmd := exec.CommandContext(ctx, "dpkg-query", "-W", "-f", "${Version}", p.Name)
 		var out bytes.Buffer
 		cmd.Stdout = &out
 		err := cmd.Run()
@@ -158,8 +120,35 @@ func (p *Package) IsInstalled() (bool, error) {
 	}
 }
 
+// State - the installed version, "not installed" if it isn't. Plans check
+// it hasn't changed before they're applied.
+func (p *Package) State(ctx context.Context) (string, error) {
+	var out string
+	var err error
+	switch facts.Facts.Distro.Family {
+	case "alpine":
+		// prints name-version, nothing if it isn't installed
+		out, err = runCommand(ctx, "apk", "info", "-e", "-v", p.Name)
+		out = strings.TrimPrefix(strings.TrimSpace(out), p.Name+"-")
+		if err != nil {
+			return "not installed", nil
+		}
+	case "debian":
+		out, err = runCommand(ctx, "dpkg-query", "-W", "-f", "${Version}", p.Name)
+		if err != nil {
+			return "not installed", nil
+		}
+	default:
+		return "", fmt.Errorf("don't know how to check packages on distro: %s", facts.Facts.Distro.Family)
+	}
+	if out == "" {
+		return "not installed", nil
+	}
+	return strings.TrimSpace(out), nil
+}
+
 // Install - install a package
-func (p *Package) Install() (string, error) {
+func (p *Package) Install(ctx context.Context) (string, error) {
 	switch facts.Facts.Distro.Family {
 	case "alpine":
 		// setting versions on alpine is probably not something most people will be into
@@ -168,61 +157,64 @@ func (p *Package) Install() (string, error) {
 		// the name and version get smooshed together for the exec
 		// i.e. apk add micro~=2
 		nameVer := fmt.Sprintf("%s%s", p.Name, p.Version)
-		cmd := exec.Command("apk", "add", nameVer)
-		var out bytes.Buffer
-		cmd.Stdout = &out
-		err := cmd.Run()
+		_, err := runCommand(ctx, "apk", "add", nameVer)
 		if err != nil {
-			log.Fatal().Err(err).Str("pkg", p.Name).Msg("Failed to cmd.Run apk add")
+			log.Error().Err(err).Str("pkg", p.Name).Msg("Failed to cmd.Run apk add")
+			return "", err
 		}
-		log.Debug().Str("stdout", out.String())
 		// TODO set version for return
 	case "debian":
 		log.Debug().Msgf("Installing on debian/ubuntu: %s (%s)", p.Name, p.Version)
-		cmd := exec.Command("apt-get", "install", "-y", p.Name)
-		var out bytes.Buffer
-		var errOut bytes.Buffer
-		cmd.Stdout = &out
-		cmd.Stderr = &errOut
-		err := cmd.Run()
-		stdOut := out.String()
-		stdErr := errOut.String()
+		_, err := runCommand(ctx, "apt-get", "install", "-y", p.Name)
 		if err != nil {
-			log.Fatal().Err(err).Str("stdout", stdOut).Str("stderr", stdErr).Msg("Failed to cmd.Run apt-get install")
+			log.Error().Err(err).Str("pkg", p.Name).Msg("Failed to cmd.Run apt-get install")
+			return "", err
 		}
-		log.Debug().Str("stdout", stdOut).Msg("stdout")
 
 	default:
 		log.Info().Msgf("Don't know how to install packages on distro: %s", facts.Facts.Distro.Family)
+		return "", fmt.Errorf("don't know how to install packages on distro: %s", facts.Facts.Distro.Family)
 	}
 	return "", nil
 }
 
 // Ensure - ensure a package is installed
-func (p *Package) Ensure(pretend bool) error {
-	installed, err := p.IsInstalled()
+func (p *Package) Ensure(ctx context.Context, pretend bool) *Result {
+	want := "installed"
+	if p.Version != "" {
+		want = p.Version
+	}
+	installed, err := p.IsInstalled(ctx)
 	if err != nil {
 		log.Debug().Err(err).Bool("pkg", installed).Msg("")
+		return Failed("failed to check if package is installed", err)
 	}
-	if pretend {
-		if installed {
+	if installed {
+		if pretend {
 			log.Info().Msgf("Package already installed: %s (%s)", p.Name, p.Version)
 		} else {
-			log.Info().Msgf("Package would be installed: %s (%s)", p.Name, p.Version)
-		}
-	} else {
-		if installed {
 			log.Debug().Msgf("Package already installed: %s (%s)", p.Name, p.Version)
-		} else {
-			// this is the only spot we actually have to do anything other than log
-			log.Debug().Msgf("Package being installed: %s (%s)", p.Name, p.Version)
-			vers, err := p.Install()
-			if err != nil {
-				log.Fatal().Err(err).Msgf("Failed to pkg.Install(): %#v", p)
-			}
-			log.Debug().Msgf("Package installed with version: %s", vers)
 		}
+		return Unchanged("package already installed")
+	}
+	if pretend {
+		log.Info().Msgf("Package would be installed: %s (%s)", p.Name, p.Version)
+		return Changed("package would be installed", "not installed", want)
 	}
 
-	return nil
+	// this is the only spot we actually have to do anything other than log
+	log.Debug().Msgf("Package being installed: %s (%s)", p.Name, p.Version)
+	vers, err := p.Install(ctx)
+	if err != nil {
+		log.Error().Err(err).Msgf("Failed to pkg.Install(): %#v", p)
+		return Failed("failed to install package", err)
+	}
+	log.Debug().Msgf("Package installed with version: %s", vers)
+
+	return Changed("package installed", "not installed", want)
+}
+
+// LockKey - only one package manager command can run at a time
+func (p *Package) LockKey() string {
+	return "package-manager"
 }
@@ -32,6 +32,7 @@ package laws
 import (
 	"bufio"
 	"bytes"
+	"context"
 	"fmt"
 	"io"
 	"net/http"
@@ -41,113 +42,78 @@ import (
 
 	"github.com/iggy/govern/pkg/facts"
 	"github.com/rs/zerolog/log"
-	"gopkg.in/yaml.v3"
 )
 
 // PackageRepo describes a package repository
 type PackageRepo struct {
-	// Name     string
-	Key      string `yaml:"key"` // (gpg|etc) key to fetch and load into the system store
-	Contents string // the repo URL usually
-	// CommonFields
-	Name   string // unique identifier, not used in the actual repo
-	Before []string
-	After  []string
-}
-
-// UnmarshalYAML implements the Unmarshaler interface
-func (r *PackageRepo) UnmarshalYAML(value *yaml.Node) error {
-	// var err error // for use in the switch below
-
-	// repo := &PackageRepo{}
-	// repo.Present = true
-
-	log.Trace().Interface("Node", value).Msg("PackageRepo UnmarshalYAML")
-	if value.Tag != "!!map" {
-		return fmt.Errorf("unable to unmarshal yaml: value not map (%s)", value.Tag)
-	}
-
-	for i, node := range value.Content {
-		log.Trace().Interface("node1", node).Msg("")
-		switch node.Value {
-		case "name":
-			r.Name = value.Content[i+1].Value
-		case "key":
-			r.Key = value.Content[i+1].Value
-		case "contents":
-			r.Contents = value.Content[i+1].Value
-		case "before":
-			for _, j := range value.Content[i+1].Content {
-				r.Before = append(r.Before, j.Value)
-			}
-		case "after":
-			for _, j := range value.Content[i+1].Content {
-				r.After = append(r.After, j.Value)
-			}
-			// case "present":
-			// 	r.Present, err = strconv.ParseBool(value.Content[i+1].Value)
-			// 	if err != nil {
-			// 		log.Error().Err(err).Msg("can't parse installed field")
-			// 		return err
-			// 	}
-		}
-	}
+	CommonFields `yaml:"-"` // Name is a unique identifier, not used in the actual repo
 
-	// *r = *repo
-	return nil
+	Key      string `yaml:"key"`      // (gpg|etc) key to fetch and load into the system store
+	Contents string `yaml:"contents"` // the repo URL usually
 }
 
-func (r *PackageRepo) Ensure(pretend bool) error {
+// Ensure - ensure the package repo is configured
+func (r *PackageRepo) Ensure(ctx context.Context, pretend bool) *Result {
 	switch facts.Facts.Distro.Family {
 	case "alpine":
 		isitin, err := lineInFile(r.Contents, "/etc/apk/repositories")
 		if err != nil {
 			log.Error().Err(err).Msg("alpine package repo: couldn't check existing repo config")
 		}
-		if !isitin {
-			if pretend {
-				log.Info().Str("name", r.Name).Str("contents", r.Contents).Msg("adding package repo")
-			} else {
-				// first lets handle the key
-				// TODO should we check if it exists already?
-				if r.Key == "" {
-					log.Error().Interface("pkgrepo", r).Msg("key isn't set")
-					return fmt.Errorf("pkgrepo key isn't set: %s", r.Name)
-				}
-				c := &http.Client{}
-				resp, err := c.Get(r.Key)
-				if err != nil {
-					log.Error().Err(err).Str("key", r.Key).Msg("get: failed to get gpg key")
-				}
-				body, err := io.ReadAll(resp.Body)
-				if err != nil {
-					log.Error().Err(err).Str("key", r.Key).Msg("read: failed to get gpg key")
-				}
-				gpgSplit := strings.Split(r.Key, "/")
-				outfileName := gpgSplit[len(gpgSplit)-1]
-				outfilePath := path.Join("/etc/apk/keys", outfileName)
-				err = os.WriteFile(outfilePath, body, 0755)
-				if err != nil {
-					log.Error().Err(err).Str("key", r.Key).Msg("failed to write gpg key")
-				}
-
-				// now add the repo url to /etc/apk/repositories
-				ear, err := os.OpenFile("/etc/apk/repositories", os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
-				if err != nil {
-					log.Error().Err(err).Str("contents", r.Contents).Msg("failed to open /e/a/r")
+		if isitin {
+			return Unchanged("package repo already configured")
+		}
+		if pretend {
+			log.Info().Str("name", r.Name).Str("contents", r.Contents).Msg("adding package repo")
+			return Changed("package repo would be added", "", r.Contents)
+		}
+		// first lets handle the key
+		// TODO should we check if it exists already?
+		if r.Key == "" {
+			log.Error().Interface("pkgrepo", r).Msg("key isn't set")
+			return Failed("pkgrepo key isn't set", fmt.Errorf("pkgrepo key isn't set: %s", r.Name))
+		}
+		c := &http.Client{}
+		resp, err := c.Get(r.Key)
+		if err != nil {
+			log.Error().Err(err).Str("key", r.Key).Msg("get: failed to get gpg key")
+			return Failed("failed to get gpg key", err)
+		}
+		body, err := io.ReadAll(resp.Body)
+		if err != nil {
+			log.Error().Err(err).Str("key", r.Key).Msg("read: failed to get gpg key")
+		}
+		gpgSplit := strings.Split(r.Key, "/")
+		outfileName := gpgSplit[len(gpgSplit)-1]
+		outfilePath := path.Join("/etc/apk/keys", outfileName)
+		err = os.WriteFile(outfilePath, body, 0755)
+		if err != nil {
+			log.Error().Err(err).Str("key", r.Key).Msg("failed to write gpg key")
+		}
 
-				}
-				_, err = ear.Write(bytes.NewBufferString(r.Contents + "\n").Bytes())
-				if err != nil {
-					log.Error().Err(err).Str("contents", r.Contents).Msg("failed to write to /e/a/r")
-				}
-				// TODO run update after adding repo
-			}
+		// now add the repo url to /etc/apk/repositories
+		ear, err := os.OpenFile("/etc/apk/repositories", os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+		if err != nil {
+			log.Error().Err(err).Str("contents", r.Contents).Msg("failed to open /e/a/r")
+			return Failed("failed to open /etc/apk/repositories", err)
+		}
+		defer ear.Close()
+		_, err = ear.Write(bytes.NewBufferString(r.Contents + "\n").Bytes())
+		if err != nil {
+			log.Error().Err(err).Str("contents", r.Contents).Msg("failed to write to /e/a/r")
+			return Failed("failed to write to /etc/apk/repositories", err)
 		}
+		// TODO run update after adding repo
+		return Changed("package repo added", "", r.Contents)
 	case "debian":
 		// should we try add-apt-repo first and then fallback to the manual way?
 	}
-	return nil
+	return Skipped(fmt.Sprintf("package repos aren't supported on distro: %s", facts.Facts.Distro.Family))
+}
+
+// LockKey - repo changes share the package manager lock
+func (r *PackageRepo) LockKey() string {
+	return "package-manager"
 }
 
 func lineInFile(line, file string) (bool, error) {
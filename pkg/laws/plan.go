@@ -0,0 +1,248 @@
+// Copyright © 2026 Iggy <iggy@theiggy.com>
+// All rights reserved.
+//
+// Redistribution and use in source and binary forms, with or without
+// modification, are permitted provided that the following conditions are met:
+//
+// 1. Redistributions of source code must retain the above copyright notice,
+//    this list of conditions and the following disclaimer.
+//
+// 2. Redistributions in binary form must reproduce the above copyright notice,
+//    this list of conditions and the following disclaimer in the documentation
+//    and/or other materials provided with the distribution.
+//
+// 3. Neither the name of the copyright holder nor the names of its contributors
+//    may be used to endorse or promote products derived from this software
+//    without specific prior written permission.
+//
+// THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS "AS IS"
+// AND ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT LIMITED TO, THE
+// IMPLIED WARRANTIES OF MERCHANTABILITY AND FITNESS FOR A PARTICULAR PURPOSE
+// ARE DISCLAIMED. IN NO EVENT SHALL THE COPYRIGHT HOLDER OR CONTRIBUTORS BE
+// LIABLE FOR ANY DIRECT, INDIRECT, INCIDENTAL, SPECIAL, EXEMPLARY, OR
+// CONSEQUENTIAL DAMAGES (INCLUDING, BUT NOT LIMITED TO, PROCUREMENT OF
+// SUBSTITUTE GOODS OR SERVICES; LOSS OF USE, DATA, OR PROFITS; OR BUSINESS
+// INTERRUPTION) HOWEVER CAUSED AND ON ANY THEORY OF LIABILITY, WHETHER IN
+// CONTRACT, STRICT LIABILITY, OR TORT (INCLUDING NEGLIGENCE OR OTHERWISE)
+// ARISING IN ANY WAY OUT OF THE USE OF THIS SOFTWARE, EVEN IF ADVISED OF THE
+// POSSIBILITY OF SUCH DAMAGE.
+
+package laws
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/hmdsefi/gograph"
+	"github.com/iggy/govern/pkg/facts"
+)
+
+// PlanVersion - bumped if plans change in a way old ones can't be applied
+const PlanVersion = 1
+
+// Plan - a pretend run saved to apply later, exactly as it was reviewed
+type Plan struct {
+	Version int        `json:"version"`
+	Created time.Time  `json:"created"`
+	Laws    string     `json:"laws"` // the laws file or directory
+	Host    ReportHost `json:"host"`
+	Summary Summary    `json:"summary"`
+	Steps   []PlanStep `json:"steps"` // every law, in the order they run
+}
+
+// PlanStep - what a law intends to do
+type PlanStep struct {
+	ID      string `json:"id"`
+	Status  Status `json:"status"`
+	Message string `json:"message,omitempty"`
+	Before  string `json:"before,omitempty"`
+	After   string `json:"after,omitempty"`
+	Error   string `json:"error,omitempty"`
+	Diff    string `json:"diff,omitempty"`
+	// what the change depends on, file:path for file hashes and the law's
+	// id for its own state, i.e. a package's installed version
+	State map[string]string `json:"state,omitempty"`
+}
+
+// Stater is implemented by laws that depend on state that isn't a file,
+// i.e. a package's installed version
+type Stater interface {
+	State(ctx context.Context) (string, error)
+}
+
+// NewPlan - pretend the laws and record what each would change, along with
+// the state the change depends on
+func NewPlan(ctx context.Context, lawsPath string, sorted []*gograph.Vertex[*LawNode], workers int) (*Plan, error) {
+	started := time.Now()
+	results := NewExecutor(workers, true).Run(ctx, sorted)
+	report := NewReport(results, true, started)
+	plan := &Plan{
+		Version: PlanVersion,
+		Created: started,
+		Laws:    lawsPath,
+		Host:    report.Host,
+		Summary: report.Summary,
+	}
+	byID := nodesByID(sorted)
+	for _, r := range report.Results {
+		step := PlanStep{
+			ID:      r.ID,
+			Status:  r.Status,
+			Message: r.Message,
+			Before:  r.Before,
+			After:   r.After,
+			Error:   r.Error,
+			Diff:    r.Diff,
+		}
+		if r.Status == StatusChanged {
+			state, err := lawState(ctx, byID[r.ID])
+			if err != nil {
+				return nil, fmt.Errorf("%s: %w", r.ID, err)
+			}
+			step.State = state
+		}
+		plan.Steps = append(plan.Steps, step)
+	}
+	return plan, nil
+}
+
+// ReadPlan - load a plan saved with Plan.Write
+func ReadPlan(path string) (*Plan, error) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var plan Plan
+	err = json.Unmarshal(content, &plan)
+	if err != nil {
+		return nil, fmt.Errorf("plan %s: %w", path, err)
+	}
+	if plan.Version != PlanVersion {
+		return nil, fmt.Errorf("plan %s is version %d, this govern only understands version %d", path, plan.Version, PlanVersion)
+	}
+	return &plan, nil
+}
+
+// Write - save the plan as json
+func (p *Plan) Write(path string) error {
+	content, err := json.MarshalIndent(p, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, append(content, '\n'), 0o600)
+}
+
+// Changes - the ids of the laws the plan changes
+func (p *Plan) Changes() []string {
+	var ids []string
+	for _, s := range p.Steps {
+		if s.Status == StatusChanged {
+			ids = append(ids, s.ID)
+		}
+	}
+	return ids
+}
+
+// Check - make sure the plan still holds for the laws and the host: every
+// change it plans is still in the laws, pretends the same, and the state it
+// depends on hasn't changed. Returns just the laws to apply.
+func (p *Plan) Check(ctx context.Context, sorted []*gograph.Vertex[*LawNode], workers int) ([]*gograph.Vertex[*LawNode], error) {
+	if p.Host.Hostname != "" && p.Host.Hostname != facts.Facts.Hostname {
+		return nil, fmt.Errorf("plan was made on %s, not %s", p.Host.Hostname, facts.Facts.Hostname)
+	}
+	ids := p.Changes()
+	if len(ids) == 0 {
+		return nil, nil
+	}
+	byID := nodesByID(sorted)
+	var drift []string
+	for _, id := range ids {
+		if byID[id] == nil {
+			drift = append(drift, fmt.Sprintf("%s isn't in the laws anymore", id))
+		}
+	}
+	if len(drift) > 0 {
+		return nil, &PlanDriftError{Drift: drift}
+	}
+
+	planned, err := Selector{Only: ids, NoDeps: true}.Filter(sorted)
+	if err != nil {
+		return nil, err
+	}
+	// the state first, pretending could depend on it
+	steps := map[string]PlanStep{}
+	for _, s := range p.Steps {
+		steps[s.ID] = s
+	}
+	for _, id := range ids {
+		state, err := lawState(ctx, byID[id])
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", id, err)
+		}
+		for k, want := range steps[id].State {
+			if state[k] != want {
+				drift = append(drift, fmt.Sprintf("%s: %s was %s, now %s", id, k, want, state[k]))
+			}
+		}
+	}
+	if len(drift) == 0 {
+		for _, r := range NewReport(NewExecutor(workers, true).Run(ctx, planned), true, time.Now()).Results {
+			s, ok := steps[r.ID]
+			if !ok {
+				continue // the root
+			}
+			if r.Status != s.Status || r.Before != s.Before || r.After != s.After {
+				drift = append(drift, fmt.Sprintf("%s: planned %s %q -> %q, now %s %q -> %q",
+					r.ID, s.Status, s.Before, s.After, r.Status, r.Before, r.After))
+			}
+		}
+	}
+	if len(drift) > 0 {
+		return nil, &PlanDriftError{Drift: drift}
+	}
+	return planned, nil
+}
+
+// PlanDriftError - the host or the laws changed since the plan was made
+type PlanDriftError struct {
+	Drift []string
+}
+
+func (e *PlanDriftError) Error() string {
+	return "the system has changed since the plan was made:\n  " + strings.Join(e.Drift, "\n  ")
+}
+
+// lawState - the state a law's change depends on
+func lawState(ctx context.Context, n *LawNode) (map[string]string, error) {
+	state := map[string]string{}
+	if fm, ok := n.Law.(FileManager); ok {
+		files := fm.ManagedFiles()
+		for i, hash := range hashFiles(files) {
+			state["file:"+files[i]] = hash
+		}
+	}
+	if s, ok := n.Law.(Stater); ok {
+		v, err := s.State(ctx)
+		if err != nil {
+			return nil, err
+		}
+		state[n.ID()] = v
+	}
+	if len(state) == 0 {
+		return nil, nil
+	}
+	return state, nil
+}
+
+// nodesByID - the laws in sorted by their ids
+func nodesByID(sorted []*gograph.Vertex[*LawNode]) map[string]*LawNode {
+	byID := map[string]*LawNode{}
+	for _, v := range sorted {
+		byID[v.Label().ID()] = v.Label()
+	}
+	return byID
+}
@@ -0,0 +1,293 @@
+// Copyright © 2026 Iggy <iggy@theiggy.com>
+// All rights reserved.
+//
+// Redistribution and use in source and binary forms, with or without
+// modification, are permitted provided that the following conditions are met:
+//
+// 1. Redistributions of source code must retain the above copyright notice,
+//    this list of conditions and the following disclaimer.
+//
+// 2. Redistributions in binary form must reproduce the above copyright notice,
+//    this list of conditions and the following disclaimer in the documentation
+//    and/or other materials provided with the distribution.
+//
+// 3. Neither the name of the copyright holder nor the names of its contributors
+//    may be used to endorse or promote products derived from this software
+//    without specific prior written permission.
+//
+// THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS "AS IS"
+// AND ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT LIMITED TO, THE
+// IMPLIED WARRANTIES OF MERCHANTABILITY AND FITNESS FOR A PARTICULAR PURPOSE
+// ARE DISCLAIMED. IN NO EVENT SHALL THE COPYRIGHT HOLDER OR CONTRIBUTORS BE
+// LIABLE FOR ANY DIRECT, INDIRECT, INCIDENTAL, SPECIAL, EXEMPLARY, OR
+// CONSEQUENTIAL DAMAGES (INCLUDING, BUT NOT LIMITED TO, PROCUREMENT OF
+// SUBSTITUTE GOODS OR SERVICES; LOSS OF USE, DATA, OR PROFITS; OR BUSINESS
+// INTERRUPTION) HOWEVER CAUSED AND ON ANY THEORY OF LIABILITY, WHETHER IN
+// CONTRACT, STRICT LIABILITY, OR TORT (INCLUDING NEGLIGENCE OR OTHERWISE)
+// ARISING IN ANY WAY OUT OF THE USE OF THIS SOFTWARE, EVEN IF ADVISED OF THE
+// POSSIBILITY OF SUCH DAMAGE.
+
+package laws
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/hmdsefi/gograph"
+	"github.com/iggy/govern/pkg/facts"
+)
+
+// fakeStater - a fake law whose change depends on some state
+type fakeStater struct {
+	fakeLaw
+	state string
+}
+
+func (f *fakeStater) State(context.Context) (string, error) {
+	return f.state, nil
+}
+
+// fakeFiler - a fake law that manages a file
+type fakeFiler struct {
+	fakeLaw
+	path string
+}
+
+func (f *fakeFiler) ManagedFiles() []string {
+	return []string{f.path}
+}
+
+func TestPlanApplyIgnoresPretendTriggers(t *testing.T) {
+	// the plan and its check pretend the file changes, but applying it
+	// turns out to change nothing, so the service mustn't restart
+	file := fakeNode("file", &fakeLaw{ensure: func(_ context.Context, pretend bool) *Result {
+		if pretend {
+			return Changed("would change", "", "")
+		}
+		return Unchanged("already right")
+	}})
+	service := &fakeReactor{fakeLaw: fakeLaw{ensure: func(context.Context, bool) *Result {
+		return Unchanged("running")
+	}}}
+	serviceNode := fakeNode("service", service)
+	serviceNode.Watches = []string{file.ID()}
+	sorted := testGraph(t, []*LawNode{file, serviceNode}, [2]string{file.ID(), serviceNode.ID()})
+
+	plan, err := NewPlan(context.Background(), "laws.yaml", sorted, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	planned, err := plan.Check(context.Background(), sorted, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if byID := nodesByID(planned); byID[file.ID()] == nil || byID[serviceNode.ID()] == nil {
+		t.Fatalf("check kept %v, want the file and the service", byID)
+	}
+	applied := resultsByID(NewExecutor(1, false).Run(context.Background(), planned))
+	if r := applied[serviceNode.ID()]; r.Status != StatusUnchanged {
+		t.Errorf("apply: service %s %s, want it unchanged", r.Status, r.Message)
+	}
+	if len(service.reacted) != 2 || !service.reacted[0] || !service.reacted[1] {
+		t.Errorf("service reacted %v, want only when planning and checking", service.reacted)
+	}
+}
+
+func TestPlanCheck(t *testing.T) {
+	host := facts.Facts.Hostname
+	t.Cleanup(func() { facts.Facts.Hostname = host })
+	facts.Facts.Hostname = "web1"
+
+	// what the laws see, changed by the tests between planning and checking
+	type env struct {
+		pkg    *fakeStater
+		cmd    string // what the command law would change to
+		path   string
+		sorted []*gograph.Vertex[*LawNode]
+		plan   *Plan
+	}
+	tests := []struct {
+		name   string
+		change func(t *testing.T, e *env)
+		want   []string // ids kept to apply
+		drift  []string // or the drift, "PATH" is the file's path
+		err    string   // or some other error
+	}{
+		{
+			name: "nothing changed",
+			want: []string{"fake::law::cmd", "fake::law::file", "fake::law::pkg"},
+		},
+		{
+			name: "law removed",
+			change: func(t *testing.T, e *env) {
+				e.sorted = slices.DeleteFunc(e.sorted, func(v *gograph.Vertex[*LawNode]) bool {
+					return v.Label().Name == "cmd"
+				})
+			},
+			drift: []string{"fake::law::cmd isn't in the laws anymore"},
+		},
+		{
+			name:   "pretends differently",
+			change: func(t *testing.T, e *env) { e.cmd = "b" },
+			drift:  []string{`fake::law::cmd: planned changed "" -> "a", now changed "" -> "b"`},
+		},
+		{
+			name:   "state changed",
+			change: func(t *testing.T, e *env) { e.pkg.state = "1.1" },
+			drift:  []string{"fake::law::pkg: fake::law::pkg was 1.0, now 1.1"},
+		},
+		{
+			name: "file changed",
+			change: func(t *testing.T, e *env) {
+				if err := os.WriteFile(e.path, []byte("edited\n"), 0o600); err != nil {
+					t.Fatal(err)
+				}
+			},
+			drift: []string{"fake::law::file: file:PATH was " + hashContent([]byte("old\n")) + ", now " + hashContent([]byte("edited\n"))},
+		},
+		{
+			name: "file removed",
+			change: func(t *testing.T, e *env) {
+				if err := os.Remove(e.path); err != nil {
+					t.Fatal(err)
+				}
+			},
+			drift: []string{"fake::law::file: file:PATH was " + hashContent([]byte("old\n")) + ", now " + absent},
+		},
+		{
+			name:   "another host",
+			change: func(t *testing.T, e *env) { e.plan.Host.Hostname = "web2" },
+			err:    "plan was made on web2, not web1",
+		},
+		{
+			name: "nothing to change",
+			change: func(t *testing.T, e *env) {
+				for i := range e.plan.Steps {
+					e.plan.Steps[i].Status = StatusUnchanged
+				}
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			e := &env{cmd: "a", path: filepath.Join(t.TempDir(), "motd")}
+			if err := os.WriteFile(e.path, []byte("old\n"), 0o600); err != nil {
+				t.Fatal(err)
+			}
+			e.pkg = &fakeStater{state: "1.0"}
+			e.pkg.ensure = func(context.Context, bool) *Result {
+				return Changed("upgrade", e.pkg.state, "2.0")
+			}
+			file := &fakeFiler{path: e.path}
+			file.ensure = func(context.Context, bool) *Result {
+				return Changed("write", "", "")
+			}
+			cmd := &fakeLaw{ensure: func(context.Context, bool) *Result {
+				return Changed("run", "", e.cmd)
+			}}
+			ok := &fakeLaw{ensure: func(context.Context, bool) *Result {
+				return Unchanged("fine")
+			}}
+			e.sorted = testGraph(t, []*LawNode{
+				fakeNode("pkg", e.pkg), fakeNode("file", file), fakeNode("cmd", cmd), fakeNode("ok", ok),
+			})
+
+			var err error
+			e.plan, err = NewPlan(context.Background(), "laws.yaml", e.sorted, 2)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := plannedState(e.plan); !reflect.DeepEqual(got, map[string]map[string]string{
+				"fake::law::pkg":  {"fake::law::pkg": "1.0"},
+				"fake::law::file": {"file:" + e.path: hashContent([]byte("old\n"))},
+			}) {
+				t.Fatalf("plan state = %v", got)
+			}
+			if tt.change != nil {
+				tt.change(t, e)
+			}
+
+			planned, err := e.plan.Check(context.Background(), e.sorted, 2)
+			var drift *PlanDriftError
+			switch {
+			case tt.err != "":
+				if err == nil || err.Error() != tt.err {
+					t.Fatalf("Check() error = %v, want %q", err, tt.err)
+				}
+				return
+			case tt.drift != nil:
+				if !errors.As(err, &drift) {
+					t.Fatalf("Check() error = %v, want drift", err)
+				}
+				var want []string
+				for _, d := range tt.drift {
+					want = append(want, strings.ReplaceAll(d, "PATH", e.path))
+				}
+				if !reflect.DeepEqual(drift.Drift, want) {
+					t.Fatalf("Check() drift = %q, want %q", drift.Drift, want)
+				}
+				return
+			case err != nil:
+				t.Fatal(err)
+			}
+
+			var got []string
+			for _, v := range planned {
+				if !isRoot(v.Label()) {
+					got = append(got, v.Label().ID())
+				}
+			}
+			slices.Sort(got)
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Check() kept %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+// plannedState - the state each change in the plan depends on
+func plannedState(p *Plan) map[string]map[string]string {
+	state := map[string]map[string]string{}
+	for _, s := range p.Steps {
+		if s.State != nil {
+			state[s.ID] = s.State
+		}
+	}
+	return state
+}
+
+func TestPlanWriteRead(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "plan.json")
+	plan := &Plan{Version: PlanVersion, Laws: "laws.yaml", Steps: []PlanStep{
+		{ID: "fake::law::pkg", Status: StatusChanged, State: map[string]string{"fake::law::pkg": "1.0"}},
+		{ID: "fake::law::ok", Status: StatusUnchanged},
+	}}
+	if err := plan.Write(path); err != nil {
+		t.Fatal(err)
+	}
+	read, err := ReadPlan(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(read, plan) {
+		t.Errorf("ReadPlan() = %+v, want %+v", read, plan)
+	}
+	if got := read.Changes(); !reflect.DeepEqual(got, []string{"fake::law::pkg"}) {
+		t.Errorf("Changes() = %v", got)
+	}
+
+	plan.Version = PlanVersion + 1
+	if err := plan.Write(path); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := ReadPlan(path); err == nil || !strings.Contains(err.Error(), "this govern only understands version") {
+		t.Errorf("ReadPlan() error = %v, want a version error", err)
+	}
+}
@@ -0,0 +1,236 @@
+// Copyright © 2026 Iggy <iggy@theiggy.com>
+// All rights reserved.
+//
+// Redistribution and use in source and binary forms, with or without
+// modification, are permitted provided that the following conditions are met:
+//
+// 1. Redistributions of source code must retain the above copyright notice,
+//    this list of conditions and the following disclaimer.
+//
+// 2. Redistributions in binary form must reproduce the above copyright notice,
+//    this list of conditions and the following disclaimer in the documentation
+//    and/or other materials provided with the distribution.
+//
+// 3. Neither the name of the copyright holder nor the names of its contributors
+//    may be used to endorse or promote products derived from this software
+//    without specific prior written permission.
+//
+// THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS "AS IS"
+// AND ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT LIMITED TO, THE
+// IMPLIED WARRANTIES OF MERCHANTABILITY AND FITNESS FOR A PARTICULAR PURPOSE
+// ARE DISCLAIMED. IN NO EVENT SHALL THE COPYRIGHT HOLDER OR CONTRIBUTORS BE
+// LIABLE FOR ANY DIRECT, INDIRECT, INCIDENTAL, SPECIAL, EXEMPLARY, OR
+// CONSEQUENTIAL DAMAGES (INCLUDING, BUT NOT LIMITED TO, PROCUREMENT OF
+// SUBSTITUTE GOODS OR SERVICES; LOSS OF USE, DATA, OR PROFITS; OR BUSINESS
+// INTERRUPTION) HOWEVER CAUSED AND ON ANY THEORY OF LIABILITY, WHETHER IN
+// CONTRACT, STRICT LIABILITY, OR TORT (INCLUDING NEGLIGENCE OR OTHERWISE)
+// ARISING IN ANY WAY OUT OF THE USE OF THIS SOFTWARE, EVEN IF ADVISED OF THE
+// POSSIBILITY OF SUCH DAMAGE.
+
+package laws
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/rs/zerolog/log"
+)
+
+// DefaultPluginDir - where LoadPlugins looks for plugins unless told otherwise
+const DefaultPluginDir = "/etc/govern/plugins"
+
+// pluginDescribeTimeout - how long a plugin gets to say what it handles
+const pluginDescribeTimeout = 10 * time.Second
+
+// External plugins are executables that handle their own group of laws. They
+// get a JSON request on stdin and write a JSON response to stdout.
+//
+// When loading, each plugin gets {"action":"describe"} and answers with the
+// yaml group and the types in it that it handles
+//
+//	{"group":"dns","types":["records"]}
+//
+// so its laws go under dns.records in the laws files. Each law then gets
+//
+//	{"action":"check","type":"records","law":{"name":"www",...}}
+//
+// when pretending, or "apply" instead of "check" otherwise, and answers with
+//
+//	{"status":"changed","message":"...","before":"...","after":"..."}
+//
+// where status is unchanged, changed (or would change when checking), skipped
+// or failed (with "error" saying why).
+
+// PluginRequest - what gets sent to a plugin on stdin
+type PluginRequest struct {
+	Action string                 `json:"action"` // describe|check|apply
+	Type   string                 `json:"type,omitempty"`
+	Law    map[string]interface{} `json:"law,omitempty"`
+}
+
+// PluginResponse - what a plugin writes to stdout for check and apply
+type PluginResponse struct {
+	Status  Status `json:"status"`
+	Message string `json:"message,omitempty"`
+	Before  string `json:"before,omitempty"`
+	After   string `json:"after,omitempty"`
+	Error   string `json:"error,omitempty"`
+}
+
+// PluginDescription - what a plugin writes to stdout for describe
+type PluginDescription struct {
+	Group string   `json:"group"`
+	Types []string `json:"types"`
+}
+
+// plugin - an external plugin executable
+type plugin struct {
+	path string
+	PluginDescription
+}
+
+var (
+	pluginsMu     sync.Mutex
+	loadedPlugins = map[string]*plugin{}
+)
+
+// LoadPlugins - find the plugin executables in dir and register a kind of
+// law for every type they handle. A missing dir just means no plugins.
+// Plugins that were already loaded are skipped, so this is safe to call more
+// than once.
+func LoadPlugins(dir string) error {
+	entries, err := os.ReadDir(dir)
+	if errors.Is(err, os.ErrNotExist) {
+		log.Debug().Str("dir", dir).Msg("no plugin dir")
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	sort.Slice(entries, func(i, j int) bool { return entries[i].Name() < entries[j].Name() })
+
+	pluginsMu.Lock()
+	defer pluginsMu.Unlock()
+
+	var errs []error
+	for _, entry := range entries {
+		path := filepath.Join(dir, entry.Name())
+		info, err := os.Stat(path)
+		if err != nil || !info.Mode().IsRegular() || info.Mode().Perm()&0111 == 0 {
+			continue
+		}
+		if _, ok := loadedPlugins[path]; ok {
+			continue
+		}
+
+		p, err := describePlugin(path)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("plugin %s: %w", path, err))
+			continue
+		}
+		for _, typ := range p.Types {
+			if k := findKind(p.Group, typ); k != nil {
+				errs = append(errs, fmt.Errorf("plugin %s: %s.%s is already handled", path, p.Group, typ))
+				continue
+			}
+			typ := typ
+			Register(Kind{
+				Path: p.Group + "." + typ,
+				New:  func() Law { return &PluginLaw{plugin: p, typ: typ} },
+			})
+		}
+		loadedPlugins[path] = p
+		log.Debug().Str("plugin", path).Str("group", p.Group).Strs("types", p.Types).Msg("loaded plugin")
+	}
+	return errors.Join(errs...)
+}
+
+// describePlugin - ask a plugin what it handles
+func describePlugin(path string) (*plugin, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), pluginDescribeTimeout)
+	defer cancel()
+
+	p := &plugin{path: path}
+	out, err := p.call(ctx, PluginRequest{Action: "describe"})
+	if err != nil {
+		return nil, err
+	}
+	err = json.Unmarshal(out, &p.PluginDescription)
+	if err != nil {
+		return nil, fmt.Errorf("bad describe response: %w", err)
+	}
+	if p.Group == "" || len(p.Types) == 0 || strings.Contains(p.Group, ".") {
+		return nil, fmt.Errorf("describe should give a group and its types, got %s", bytes.TrimSpace(out))
+	}
+	return p, nil
+}
+
+// call - run the plugin with a request and return what it wrote to stdout
+func (p *plugin) call(ctx context.Context, req PluginRequest) ([]byte, error) {
+	in, err := json.Marshal(req)
+	if err != nil {
+		return nil, err
+	}
+	cmd := exec.CommandContext(ctx, p.path)
+	cmd.Stdin = bytes.NewReader(in)
+	stdOut, _, err := runCmd(ctx, cmd)
+	return []byte(stdOut), err
+}
+
+// PluginLaw - a law handled by an external plugin
+type PluginLaw struct {
+	CommonFields `yaml:"-"`
+
+	plugin *plugin
+	typ    string
+	// Fields - everything from the yaml except the keys govern handles
+	// itself (requisites, guards, etc), this is what the plugin gets
+	Fields map[string]interface{} `yaml:",inline"`
+}
+
+// Ensure - have the plugin check (when pretending) or apply the law
+func (pl *PluginLaw) Ensure(ctx context.Context, pretend bool) *Result {
+	law := map[string]interface{}{"name": pl.Name}
+	for k, v := range pl.Fields {
+		law[k] = v
+	}
+	req := PluginRequest{Action: "apply", Type: pl.typ, Law: law}
+	if pretend {
+		req.Action = "check"
+	}
+	log.Debug().Str("plugin", pl.plugin.path).Str("action", req.Action).Str("name", pl.Name).Msg("calling plugin")
+
+	out, err := pl.plugin.call(ctx, req)
+	var resp PluginResponse
+	if jerr := json.Unmarshal(out, &resp); jerr != nil {
+		if err == nil {
+			err = fmt.Errorf("bad response: %w", jerr)
+		}
+		return Failed(fmt.Sprintf("plugin %s failed", filepath.Base(pl.plugin.path)), err)
+	}
+
+	switch resp.Status {
+	case StatusUnchanged:
+		return Unchanged(resp.Message)
+	case StatusChanged:
+		return Changed(resp.Message, resp.Before, resp.After)
+	case StatusSkipped:
+		return Skipped(resp.Message)
+	case StatusFailed:
+		if resp.Error != "" {
+			err = errors.New(resp.Error)
+		}
+		return Failed(resp.Message, err)
+	}
+	return Failed(fmt.Sprintf("plugin %s gave an unknown status %q", filepath.Base(pl.plugin.path), resp.Status), err)
+}
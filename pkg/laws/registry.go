@@ -0,0 +1,156 @@
+// Copyright © 2026 Iggy <iggy@theiggy.com>
+// All rights reserved.
+//
+// Redistribution and use in source and binary forms, with or without
+// modification, are permitted provided that the following conditions are met:
+//
+// 1. Redistributions of source code must retain the above copyright notice,
+//    this list of conditions and the following disclaimer.
+//
+// 2. Redistributions in binary form must reproduce the above copyright notice,
+//    this list of conditions and the following disclaimer in the documentation
+//    and/or other materials provided with the distribution.
+//
+// 3. Neither the name of the copyright holder nor the names of its contributors
+//    may be used to endorse or promote products derived from this software
+//    without specific prior written permission.
+//
+// THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS "AS IS"
+// AND ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT LIMITED TO, THE
+// IMPLIED WARRANTIES OF MERCHANTABILITY AND FITNESS FOR A PARTICULAR PURPOSE
+// ARE DISCLAIMED. IN NO EVENT SHALL THE COPYRIGHT HOLDER OR CONTRIBUTORS BE
+// LIABLE FOR ANY DIRECT, INDIRECT, INCIDENTAL, SPECIAL, EXEMPLARY, OR
+// CONSEQUENTIAL DAMAGES (INCLUDING, BUT NOT LIMITED TO, PROCUREMENT OF
+// SUBSTITUTE GOODS OR SERVICES; LOSS OF USE, DATA, OR PROFITS; OR BUSINESS
+// INTERRUPTION) HOWEVER CAUSED AND ON ANY THEORY OF LIABILITY, WHETHER IN
+// CONTRACT, STRICT LIABILITY, OR TORT (INCLUDING NEGLIGENCE OR OTHERWISE)
+// ARISING IN ANY WAY OUT OF THE USE OF THIS SOFTWARE, EVEN IF ADVISED OF THE
+// POSSIBILITY OF SUCH DAMAGE.
+
+package laws
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+)
+
+// Kind - a kind of law, i.e. files.templates. Kinds have to be registered
+// with Register (usually from an init func) before ParseFiles will pick them
+// up out of the laws files.
+type Kind struct {
+	// Path - where the list of these laws lives in the yaml, i.e.
+	// files.templates or package_repos.present
+	Path string
+	// New - an empty law for the yaml to be decoded into, this should
+	// return a pointer so any UnmarshalYAML func gets used
+	New func() Law
+	// Common - get at the common fields of a law, this can be left out if
+	// the law embeds CommonFields
+	Common func(Law) *CommonFields
+}
+
+// common - the common fields of a law of this kind
+func (k *Kind) common(l Law) *CommonFields {
+	if k.Common != nil {
+		return k.Common(l)
+	}
+	return l.(interface{ Common() *CommonFields }).Common()
+}
+
+// group - the group part of the law ids, ids don't have underscores so
+// package_repos.present laws are packagerepos::present::name
+func (k *Kind) group() string {
+	group, _, _ := strings.Cut(k.Path, ".")
+	return strings.ReplaceAll(strings.ToLower(group), "_", "")
+}
+
+// typ - the type part of the law ids
+func (k *Kind) typ() string {
+	_, typ, _ := strings.Cut(k.Path, ".")
+	return strings.ReplaceAll(strings.ToLower(typ), "_", "")
+}
+
+// yamlKeys - the group and type keys in the yaml
+func (k *Kind) yamlKeys() (string, string) {
+	group, typ, _ := strings.Cut(k.Path, ".")
+	return group, typ
+}
+
+var (
+	kindsMu sync.RWMutex
+	kinds   []*Kind
+)
+
+// Register - add a kind of law. It panics if the kind is missing anything
+// or if another kind already uses the same path, like database/sql.Register
+// does for drivers.
+func Register(k Kind) {
+	kindsMu.Lock()
+	defer kindsMu.Unlock()
+
+	group, typ, ok := strings.Cut(k.Path, ".")
+	if !ok || group == "" || typ == "" || strings.Contains(typ, ".") {
+		panic(fmt.Sprintf("laws: kind path should be group.type, got %q", k.Path))
+	}
+	if k.New == nil {
+		panic(fmt.Sprintf("laws: kind %s needs New", k.Path))
+	}
+	if _, ok := k.New().(interface{ Common() *CommonFields }); !ok && k.Common == nil {
+		panic(fmt.Sprintf("laws: kind %s needs Common or a law that embeds CommonFields", k.Path))
+	}
+	for _, other := range kinds {
+		if other.Path == k.Path || (other.group() == k.group() && other.typ() == k.typ()) {
+			panic(fmt.Sprintf("laws: kind %s registered twice", k.Path))
+		}
+	}
+	kinds = append(kinds, &k)
+}
+
+// Kinds - every registered kind of law, in the order they were registered
+func Kinds() []Kind {
+	kindsMu.RLock()
+	defer kindsMu.RUnlock()
+	out := make([]Kind, 0, len(kinds))
+	for _, k := range kinds {
+		out = append(out, *k)
+	}
+	return out
+}
+
+// registeredKinds - the registered kinds for use inside the package
+func registeredKinds() []*Kind {
+	kindsMu.RLock()
+	defer kindsMu.RUnlock()
+	return append([]*Kind(nil), kinds...)
+}
+
+// findKind - the kind for a yaml group and type, or nil
+func findKind(group, typ string) *Kind {
+	for _, k := range registeredKinds() {
+		if g, t := k.yamlKeys(); g == group && t == typ {
+			return k
+		}
+	}
+	return nil
+}
+
+// the built in laws, the order they're registered in is the order they come
+// out of ParseFiles in when nothing else orders them
+func init() {
+	Register(Kind{Path: "users.present", New: func() Law { return &User{} }})
+	Register(Kind{Path: "groups.present", New: func() Law { return &Group{} }})
+	Register(Kind{Path: "packages.installed", New: func() Law { return &Package{} }})
+	Register(Kind{Path: "package_repos.present", New: func() Law { return &PackageRepo{} }})
+	Register(Kind{Path: "package_repos.absent", New: func() Law { return &PackageRepo{} }})
+	Register(Kind{Path: "containers.running", New: func() Law { return &Container{} }})
+	Register(Kind{Path: "scripts.run", New: func() Law { return &Script{} }})
+	Register(Kind{Path: "files.templates", New: func() Law { return &FileTemplate{} }})
+	Register(Kind{Path: "files.inserts", New: func() Law { return &FileInsert{} }})
+	Register(Kind{Path: "files.changes", New: func() Law { return &FileChange{} }})
+	Register(Kind{Path: "files.links", New: func() Law { return &FileLink{} }})
+	Register(Kind{Path: "mounts.exists", New: func() Law { return &Mount{} }})
+	Register(Kind{Path: "mounts.absent", New: func() Law { return &AbsentMount{} }})
+	Register(Kind{Path: "services.enabled", New: func() Law { return &Service{} }})
+	Register(Kind{Path: "ssh.authorized_keys", New: func() Law { return &SSHKey{} }})
+}
@@ -0,0 +1,202 @@
+// Copyright © 2026 Iggy <iggy@theiggy.com>
+// All rights reserved.
+//
+// Redistribution and use in source and binary forms, with or without
+// modification, are permitted provided that the following conditions are met:
+//
+// 1. Redistributions of source code must retain the above copyright notice,
+//    this list of conditions and the following disclaimer.
+//
+// 2. Redistributions in binary form must reproduce the above copyright notice,
+//    this list of conditions and the following disclaimer in the documentation
+//    and/or other materials provided with the distribution.
+//
+// 3. Neither the name of the copyright holder nor the names of its contributors
+//    may be used to endorse or promote products derived from this software
+//    without specific prior written permission.
+//
+// THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS "AS IS"
+// AND ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT LIMITED TO, THE
+// IMPLIED WARRANTIES OF MERCHANTABILITY AND FITNESS FOR A PARTICULAR PURPOSE
+// ARE DISCLAIMED. IN NO EVENT SHALL THE COPYRIGHT HOLDER OR CONTRIBUTORS BE
+// LIABLE FOR ANY DIRECT, INDIRECT, INCIDENTAL, SPECIAL, EXEMPLARY, OR
+// CONSEQUENTIAL DAMAGES (INCLUDING, BUT NOT LIMITED TO, PROCUREMENT OF
+// SUBSTITUTE GOODS OR SERVICES; LOSS OF USE, DATA, OR PROFITS; OR BUSINESS
+// INTERRUPTION) HOWEVER CAUSED AND ON ANY THEORY OF LIABILITY, WHETHER IN
+// CONTRACT, STRICT LIABILITY, OR TORT (INCLUDING NEGLIGENCE OR OTHERWISE)
+// ARISING IN ANY WAY OUT OF THE USE OF THIS SOFTWARE, EVEN IF ADVISED OF THE
+// POSSIBILITY OF SUCH DAMAGE.
+
+package laws
+
+import (
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/iggy/govern/pkg/facts"
+)
+
+// Report - everything that happened in a run, for CI and dashboards
+type Report struct {
+	Host     ReportHost    `json:"host"`
+	Pretend  bool          `json:"pretend"`
+	Started  time.Time     `json:"started"`
+	Duration time.Duration `json:"duration"`
+	Summary  Summary       `json:"summary"`
+	Results  []*Result     `json:"results"`
+	Error    string        `json:"error,omitempty"` // set if the laws couldn't be run at all
+}
+
+// ReportHost - the facts that identify the host a report came from
+type ReportHost struct {
+	Hostname   string            `json:"hostname"`
+	SystemUUID string            `json:"system_uuid,omitempty"`
+	Distro     facts.DistroFacts `json:"distro"`
+}
+
+// NewReport - build a report from the results of Executor.Run, with any
+// decrypted secrets redacted
+func NewReport(results []*Result, pretend bool, started time.Time) *Report {
+	redactedResults := make([]*Result, 0, len(results))
+	for _, r := range results {
+		redactedResults = append(redactedResults, redactResult(r))
+	}
+	return &Report{
+		Host: ReportHost{
+			Hostname:   facts.Facts.Hostname,
+			SystemUUID: facts.Facts.SystemUUID,
+			Distro:     facts.Facts.Distro,
+		},
+		Pretend:  pretend,
+		Started:  started,
+		Duration: time.Since(started),
+		Summary:  Summarize(results),
+		Results:  redactedResults,
+	}
+}
+
+// ReportFormats - the formats Write understands
+var ReportFormats = []string{"json", "junit"}
+
+// Write - write the report in one of the ReportFormats
+func (r *Report) Write(w io.Writer, format string) error {
+	switch format {
+	case "json":
+		return r.WriteJSON(w)
+	case "junit":
+		return r.WriteJUnit(w)
+	}
+	return fmt.Errorf("unknown report format %q, expected one of %s", format, strings.Join(ReportFormats, ", "))
+}
+
+// WriteJSON - write the report as indented json
+func (r *Report) WriteJSON(w io.Writer) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(r)
+}
+
+// junit xml, as understood by most CI systems. Each law is a test case
+// with the group::type as the class name.
+type junitSuites struct {
+	XMLName xml.Name     `xml:"testsuites"`
+	Suites  []junitSuite `xml:"testsuite"`
+}
+
+type junitSuite struct {
+	Name       string          `xml:"name,attr"`
+	Hostname   string          `xml:"hostname,attr"`
+	Timestamp  string          `xml:"timestamp,attr"`
+	Tests      int             `xml:"tests,attr"`
+	Failures   int             `xml:"failures,attr"`
+	Skipped    int             `xml:"skipped,attr"`
+	Time       float64         `xml:"time,attr"`
+	Properties []junitProperty `xml:"properties>property"`
+	Cases      []junitCase     `xml:"testcase"`
+}
+
+type junitProperty struct {
+	Name  string `xml:"name,attr"`
+	Value string `xml:"value,attr"`
+}
+
+type junitCase struct {
+	ClassName string        `xml:"classname,attr"`
+	Name      string        `xml:"name,attr"`
+	Time      float64       `xml:"time,attr"`
+	Failure   *junitMessage `xml:"failure,omitempty"`
+	Skipped   *junitMessage `xml:"skipped,omitempty"`
+	SystemOut string        `xml:"system-out,omitempty"`
+	SystemErr string        `xml:"system-err,omitempty"`
+}
+
+type junitMessage struct {
+	Message string `xml:"message,attr"`
+	Text    string `xml:",chardata"`
+}
+
+// WriteJUnit - write the report as junit xml
+func (r *Report) WriteJUnit(w io.Writer) error {
+	name := "govern apply"
+	if r.Pretend {
+		name = "govern pretend"
+	}
+	suite := junitSuite{
+		Name:      name,
+		Hostname:  r.Host.Hostname,
+		Timestamp: r.Started.Format(time.RFC3339),
+		Tests:     len(r.Results),
+		Failures:  r.Summary.Failed,
+		Skipped:   r.Summary.Skipped + r.Summary.Cancelled,
+		Time:      r.Duration.Seconds(),
+		Properties: []junitProperty{
+			{"distro", r.Host.Distro.Slug},
+			{"distro.family", r.Host.Distro.Family},
+			{"distro.version", r.Host.Distro.Version},
+		},
+	}
+	for _, res := range r.Results {
+		group, name, _ := strings.Cut(res.ID, "::")
+		typ, name, _ := strings.Cut(name, "::")
+		tc := junitCase{
+			ClassName: group + "::" + typ,
+			Name:      name,
+			Time:      res.Duration.Seconds(),
+		}
+		switch res.Status {
+		case StatusFailed:
+			tc.Failure = &junitMessage{Message: res.Message, Text: res.Error}
+			if res.Command != nil {
+				tc.SystemOut = res.Command.Stdout
+				tc.SystemErr = res.Command.Stderr
+			}
+		case StatusSkipped, StatusCancelled:
+			tc.Skipped = &junitMessage{Message: res.Message}
+		case StatusChanged:
+			tc.SystemOut = res.Message
+			if res.Before != "" || res.After != "" {
+				tc.SystemOut = fmt.Sprintf("%s: %q -> %q", res.Message, res.Before, res.After)
+			}
+		default:
+			tc.SystemOut = res.Message
+		}
+		suite.Cases = append(suite.Cases, tc)
+	}
+
+	_, err := io.WriteString(w, xml.Header)
+	if err != nil {
+		return err
+	}
+	enc := xml.NewEncoder(w)
+	enc.Indent("", "  ")
+	err = enc.Encode(junitSuites{Suites: []junitSuite{suite}})
+	if err != nil {
+		return err
+	}
+	_, err = io.WriteString(w, "\n")
+	return err
+}
@@ -0,0 +1,325 @@
+// Copyright © 2026 Iggy <iggy@theiggy.com>
+// All rights reserved.
+//
+// Redistribution and use in source and binary forms, with or without
+// modification, are permitted provided that the following conditions are met:
+//
+// 1. Redistributions of source code must retain the above copyright notice,
+//    this list of conditions and the following disclaimer.
+//
+// 2. Redistributions in binary form must reproduce the above copyright notice,
+//    this list of conditions and the following disclaimer in the documentation
+//    and/or other materials provided with the distribution.
+//
+// 3. Neither the name of the copyright holder nor the names of its contributors
+//    may be used to endorse or promote products derived from this software
+//    without specific prior written permission.
+//
+// THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS "AS IS"
+// AND ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT LIMITED TO, THE
+// IMPLIED WARRANTIES OF MERCHANTABILITY AND FITNESS FOR A PARTICULAR PURPOSE
+// ARE DISCLAIMED. IN NO EVENT SHALL THE COPYRIGHT HOLDER OR CONTRIBUTORS BE
+// LIABLE FOR ANY DIRECT, INDIRECT, INCIDENTAL, SPECIAL, EXEMPLARY, OR
+// CONSEQUENTIAL DAMAGES (INCLUDING, BUT NOT LIMITED TO, PROCUREMENT OF
+// SUBSTITUTE GOODS OR SERVICES; LOSS OF USE, DATA, OR PROFITS; OR BUSINESS
+// INTERRUPTION) HOWEVER CAUSED AND ON ANY THEORY OF LIABILITY, WHETHER IN
+// CONTRACT, STRICT LIABILITY, OR TORT (INCLUDING NEGLIGENCE OR OTHERWISE)
+// ARISING IN ANY WAY OUT OF THE USE OF THIS SOFTWARE, EVEN IF ADVISED OF THE
+// POSSIBILITY OF SUCH DAMAGE.
+
+package laws
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/rs/zerolog/log"
+)
+
+// Status - the outcome of ensuring a law
+type Status string
+
+const (
+	StatusUnchanged Status = "unchanged" // the system already matched the law
+	StatusChanged   Status = "changed"   // the system was changed (or would be when pretending)
+	StatusFailed    Status = "failed"    // the law couldn't be ensured
+	StatusSkipped   Status = "skipped"   // the law wasn't run
+	StatusCancelled Status = "cancelled" // the run was cancelled before the law ran
+)
+
+// Result - what happened when a law was ensured
+type Result struct {
+	ID       string        `json:"id"`             // group::type::name
+	File     string        `json:"file,omitempty"` // the laws file the law came from
+	Status   Status        `json:"status"`
+	Message  string        `json:"message,omitempty"`
+	Before   string        `json:"before,omitempty"` // the value found on the system
+	After    string        `json:"after,omitempty"`  // the value the law wants
+	Duration time.Duration `json:"duration"`
+	Attempts int           `json:"attempts,omitempty"` // only set if the law has a retry block
+	Error    string        `json:"error,omitempty"`
+	Command  *CommandError `json:"command,omitempty"`  // set if the law failed running a command
+	Commands []CommandRun  `json:"commands,omitempty"` // every command the law ran
+	Files    []FileHash    `json:"files,omitempty"`    // the files the law manages, when applying
+	Diff     string        `json:"diff,omitempty"`     // unified diff of a file a pretend would change
+	Err      error         `json:"-"`
+}
+
+// FileHash - the hash of a file before and after a law was ensured, with
+// the mode and owner it had before so it can be rolled back
+type FileHash struct {
+	Path   string `json:"path"`
+	Before string `json:"before"`
+	After  string `json:"after"`
+	Mode   string `json:"mode,omitempty"`
+	Owner  string `json:"owner,omitempty"` // uid:gid
+}
+
+// Unchanged - the system already matches the law
+func Unchanged(msg string) *Result {
+	return &Result{Status: StatusUnchanged, Message: msg}
+}
+
+// Changed - the system was (or would be) changed from before to after
+func Changed(msg, before, after string) *Result {
+	return &Result{Status: StatusChanged, Message: msg, Before: before, After: after}
+}
+
+// Failed - the law couldn't be ensured
+func Failed(msg string, err error) *Result {
+	if err == nil {
+		err = fmt.Errorf("%s", msg)
+	}
+	return &Result{
+		Status:  StatusFailed,
+		Message: msg,
+		Error:   err.Error(),
+		Command: commandError(err),
+		Err:     err,
+	}
+}
+
+// Skipped - the law wasn't run
+func Skipped(msg string) *Result {
+	return &Result{Status: StatusSkipped, Message: msg}
+}
+
+// Cancelled - the run was cancelled before the law ran
+func Cancelled(err error) *Result {
+	return &Result{Status: StatusCancelled, Message: fmt.Sprintf("never ran: %v", err)}
+}
+
+// Summary - counts of results by status
+type Summary struct {
+	OK        int `json:"ok"`
+	Changed   int `json:"changed"`
+	Failed    int `json:"failed"`
+	Skipped   int `json:"skipped"`
+	Cancelled int `json:"cancelled"`
+}
+
+// Summarize - count up the results of a run
+func Summarize(results []*Result) Summary {
+	var s Summary
+	for _, r := range results {
+		switch r.Status {
+		case StatusUnchanged:
+			s.OK++
+		case StatusChanged:
+			s.Changed++
+		case StatusFailed:
+			s.Failed++
+		case StatusSkipped:
+			s.Skipped++
+		case StatusCancelled:
+			s.Cancelled++
+		}
+	}
+	return s
+}
+
+// String - i.e. "12 ok, 3 changed, 1 failed"
+func (s Summary) String() string {
+	parts := []string{
+		fmt.Sprintf("%d ok", s.OK),
+		fmt.Sprintf("%d changed", s.Changed),
+		fmt.Sprintf("%d failed", s.Failed),
+	}
+	if s.Skipped > 0 {
+		parts = append(parts, fmt.Sprintf("%d skipped", s.Skipped))
+	}
+	if s.Cancelled > 0 {
+		parts = append(parts, fmt.Sprintf("%d cancelled", s.Cancelled))
+	}
+	return strings.Join(parts, ", ")
+}
+
+// ID - the group::type::name used to refer to the law in requisites, with
+// modules::<instance>:: in front for laws in a module instance
+func (n *LawNode) ID() string {
+	if n.Module != "" {
+		return fmt.Sprintf("%s::%s::%s::%s", n.Module, n.Group, n.Type, n.Name)
+	}
+	return fmt.Sprintf("%s::%s::%s", n.Group, n.Type, n.Name)
+}
+
+// Ensure - ensure the law and time how long it took, retrying failures
+// if the law has a retry block
+func (n *LawNode) Ensure(ctx context.Context, pretend bool) *Result {
+	start := time.Now()
+	ctx, commands := withCommandLog(ctx)
+	var files []string
+	if fm, ok := n.Law.(FileManager); ok && !pretend {
+		files = fm.ManagedFiles()
+	}
+	snaps := snapshotFiles(files)
+
+	var r *Result
+	guard, err := n.Options.guard(ctx)
+	switch {
+	case err != nil:
+		r = Failed("guard couldn't be checked", err)
+	case guard != "":
+		r = Skipped(guard)
+	default:
+		r = n.ensureWithRetry(ctx, pretend)
+	}
+	r.ID = n.ID()
+	r.Duration = time.Since(start)
+	r.Commands = commands.runs
+	bak, _ := n.Law.(backupper)
+	for i, after := range hashFiles(files) {
+		r.Files = append(r.Files, snaps[i].record(after))
+		if after != snaps[i].hash {
+			snaps[i].backup(bak != nil && bak.keepBackup())
+		}
+	}
+
+	rl := log.With().Str("law", r.ID).Str("status", string(r.Status)).Logger()
+	if r.Status == StatusFailed {
+		rl.Error().Err(r.Err).Msg(r.Message)
+	} else {
+		rl.Debug().Dur("duration", r.Duration).Msg(r.Message)
+	}
+	return r
+}
+
+// ensureWithRetry - keep ensuring the law until it doesn't fail or we run
+// out of attempts. Pretending doesn't retry, there's nothing to wait for.
+func (n *LawNode) ensureWithRetry(ctx context.Context, pretend bool) *Result {
+	var retry *RetryOpts
+	if n.Options != nil {
+		retry = n.Options.Retry
+	}
+	if retry == nil || retry.Attempts < 2 || pretend {
+		return n.ensureOnce(ctx, pretend)
+	}
+
+	var r *Result
+	for attempt := uint(1); attempt <= retry.Attempts; attempt++ {
+		r = n.ensureOnce(ctx, pretend)
+		r.Attempts = int(attempt)
+		if r.Status != StatusFailed {
+			break
+		}
+		al := log.With().
+			Str("law", n.ID()).
+			Uint("attempt", attempt).
+			Uint("attempts", retry.Attempts).
+			Logger()
+		if attempt == retry.Attempts {
+			al.Error().Err(r.Err).Msg("out of attempts")
+			break
+		}
+		wait := retry.wait()
+		al.Warn().Err(r.Err).Dur("wait", wait).Msg("attempt failed, retrying")
+		select {
+		case <-time.After(wait):
+		case <-ctx.Done():
+			al.Warn().Err(ctx.Err()).Msg("cancelled while waiting to retry")
+		}
+		if ctx.Err() != nil {
+			break
+		}
+	}
+	if r.Attempts > 1 {
+		r.Message = fmt.Sprintf("%s (after %d attempts)", r.Message, r.Attempts)
+	}
+	return r
+}
+
+// ensureOnce - ensure (or react) without any of the extras except the
+// timeout
+func (n *LawNode) ensureOnce(ctx context.Context, pretend bool) *Result {
+	parent := ctx
+	var timeout time.Duration
+	if n.Options != nil && n.Options.Timeout > 0 {
+		timeout = n.Options.Timeout
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, timeout)
+		defer cancel()
+	}
+
+	var r *Result
+	reactor, isReactor := n.Law.(Reactor)
+	switch {
+	case len(n.Watches) > 0 && !triggered(ctx) && !isReactor:
+		// only runs when something it watches changed
+		r = Skipped("nothing watched changed")
+	case triggered(ctx) && isReactor:
+		r = n.Law.Ensure(ctx, pretend)
+		if r != nil && r.Status == StatusUnchanged {
+			r = reactor.React(ctx, pretend)
+		}
+	default:
+		r = n.Law.Ensure(ctx, pretend)
+	}
+	if r == nil {
+		r = Unchanged("")
+	}
+	// only the law's own timeout, not a deadline on the whole run
+	if r.Status == StatusFailed && timeout > 0 && parent.Err() == nil && errors.Is(ctx.Err(), context.DeadlineExceeded) {
+		r.Message = fmt.Sprintf("%s (timed out after %s)", r.Message, timeout)
+	}
+	return r
+}
+
+// Reactor is implemented by laws that do something extra when a law they
+// watch changes, i.e. a Service restarts. Laws that aren't Reactors just
+// don't run unless something they watch changed.
+type Reactor interface {
+	React(ctx context.Context, pretend bool) *Result
+}
+
+// FileManager is implemented by laws that change files, they get hashed
+// before and after the law is applied for the journal, and backed up if
+// the law changed them
+type FileManager interface {
+	ManagedFiles() []string
+}
+
+// hashFiles - the hashes of some files, absent for files that don't exist
+func hashFiles(files []string) []string {
+	hashes := make([]string, 0, len(files))
+	for _, f := range files {
+		content, err := os.ReadFile(f)
+		if err != nil {
+			hashes = append(hashes, absent)
+			continue
+		}
+		hashes = append(hashes, hashContent(content))
+	}
+	return hashes
+}
+
+// hashContent - sha256 of some content, used for before/after values of files
+func hashContent(content []byte) string {
+	sum := sha256.Sum256(content)
+	return "sha256:" + hex.EncodeToString(sum[:])
+}
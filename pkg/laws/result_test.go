@@ -0,0 +1,98 @@
+// Copyright © 2026 Iggy <iggy@theiggy.com>
+// All rights reserved.
+//
+// Redistribution and use in source and binary forms, with or without
+// modification, are permitted provided that the following conditions are met:
+//
+// 1. Redistributions of source code must retain the above copyright notice,
+//    this list of conditions and the following disclaimer.
+//
+// 2. Redistributions in binary form must reproduce the above copyright notice,
+//    this list of conditions and the following disclaimer in the documentation
+//    and/or other materials provided with the distribution.
+//
+// 3. Neither the name of the copyright holder nor the names of its contributors
+//    may be used to endorse or promote products derived from this software
+//    without specific prior written permission.
+//
+// THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS "AS IS"
+// AND ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT LIMITED TO, THE
+// IMPLIED WARRANTIES OF MERCHANTABILITY AND FITNESS FOR A PARTICULAR PURPOSE
+// ARE DISCLAIMED. IN NO EVENT SHALL THE COPYRIGHT HOLDER OR CONTRIBUTORS BE
+// LIABLE FOR ANY DIRECT, INDIRECT, INCIDENTAL, SPECIAL, EXEMPLARY, OR
+// CONSEQUENTIAL DAMAGES (INCLUDING, BUT NOT LIMITED TO, PROCUREMENT OF
+// SUBSTITUTE GOODS OR SERVICES; LOSS OF USE, DATA, OR PROFITS; OR BUSINESS
+// INTERRUPTION) HOWEVER CAUSED AND ON ANY THEORY OF LIABILITY, WHETHER IN
+// CONTRACT, STRICT LIABILITY, OR TORT (INCLUDING NEGLIGENCE OR OTHERWISE)
+// ARISING IN ANY WAY OUT OF THE USE OF THIS SOFTWARE, EVEN IF ADVISED OF THE
+// POSSIBILITY OF SUCH DAMAGE.
+
+package laws
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestRetry(t *testing.T) {
+	tests := []struct {
+		name     string
+		retry    *RetryOpts
+		fails    int // how many times the law fails before it works
+		pretend  bool
+		cancel   bool
+		want     Status
+		attempts int // Result.Attempts, 0 if it isn't retried
+		ensures  int
+		message  string
+	}{
+		{name: "no retry", fails: 1, want: StatusFailed, ensures: 1, message: "broken"},
+		{name: "one attempt", retry: &RetryOpts{Attempts: 1}, fails: 1, want: StatusFailed, ensures: 1, message: "broken"},
+		{name: "works first time", retry: &RetryOpts{Attempts: 3}, want: StatusChanged, attempts: 1, ensures: 1, message: "fixed"},
+		{name: "works on the last attempt", retry: &RetryOpts{Attempts: 3}, fails: 2, want: StatusChanged, attempts: 3, ensures: 3, message: "fixed (after 3 attempts)"},
+		{name: "out of attempts", retry: &RetryOpts{Attempts: 3}, fails: 5, want: StatusFailed, attempts: 3, ensures: 3, message: "broken (after 3 attempts)"},
+		{name: "pretend doesn't retry", retry: &RetryOpts{Attempts: 3}, fails: 5, pretend: true, want: StatusFailed, ensures: 1, message: "broken"},
+		{name: "cancelled while waiting", retry: &RetryOpts{Attempts: 3, Interval: 60}, fails: 5, cancel: true, want: StatusFailed, attempts: 1, ensures: 1, message: "broken"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx, cancel := context.WithCancel(context.Background())
+			defer cancel()
+			ensures := 0
+			n := fakeNode("retry", &fakeLaw{ensure: func(ctx context.Context, pretend bool) *Result {
+				ensures++
+				if tt.cancel {
+					cancel()
+				}
+				if ensures <= tt.fails {
+					return Failed("broken", errors.New("still broken"))
+				}
+				return Changed("fixed", "", "")
+			}})
+			n.Options = &Options{Retry: tt.retry}
+
+			r := n.Ensure(ctx, tt.pretend)
+			if r.Status != tt.want || r.Attempts != tt.attempts || r.Message != tt.message {
+				t.Errorf("Ensure() = %s %d %q, want %s %d %q", r.Status, r.Attempts, r.Message, tt.want, tt.attempts, tt.message)
+			}
+			if ensures != tt.ensures {
+				t.Errorf("law ensured %d times, want %d", ensures, tt.ensures)
+			}
+		})
+	}
+}
+
+func TestRetryWait(t *testing.T) {
+	r := &RetryOpts{Interval: 1, Splay: 2}
+	for i := 0; i < 100; i++ {
+		if w := r.wait(); w < time.Second || w >= 3*time.Second {
+			t.Fatalf("wait() = %s, want between 1s and 3s", w)
+		}
+	}
+	if w := (&RetryOpts{Interval: 5}).wait(); w != 5*time.Second {
+		t.Errorf("wait() without splay = %s, want 5s", w)
+	}
+}
@@ -0,0 +1,229 @@
+// Copyright © 2026 Iggy <iggy@theiggy.com>
+// All rights reserved.
+//
+// Redistribution and use in source and binary forms, with or without
+// modification, are permitted provided that the following conditions are met:
+//
+// 1. Redistributions of source code must retain the above copyright notice,
+//    this list of conditions and the following disclaimer.
+//
+// 2. Redistributions in binary form must reproduce the above copyright notice,
+//    this list of conditions and the following disclaimer in the documentation
+//    and/or other materials provided with the distribution.
+//
+// 3. Neither the name of the copyright holder nor the names of its contributors
+//    may be used to endorse or promote products derived from this software
+//    without specific prior written permission.
+//
+// THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS "AS IS"
+// AND ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT LIMITED TO, THE
+// IMPLIED WARRANTIES OF MERCHANTABILITY AND FITNESS FOR A PARTICULAR PURPOSE
+// ARE DISCLAIMED. IN NO EVENT SHALL THE COPYRIGHT HOLDER OR CONTRIBUTORS BE
+// LIABLE FOR ANY DIRECT, INDIRECT, INCIDENTAL, SPECIAL, EXEMPLARY, OR
+// CONSEQUENTIAL DAMAGES (INCLUDING, BUT NOT LIMITED TO, PROCUREMENT OF
+// SUBSTITUTE GOODS OR SERVICES; LOSS OF USE, DATA, OR PROFITS; OR BUSINESS
+// INTERRUPTION) HOWEVER CAUSED AND ON ANY THEORY OF LIABILITY, WHETHER IN
+// CONTRACT, STRICT LIABILITY, OR TORT (INCLUDING NEGLIGENCE OR OTHERWISE)
+// ARISING IN ANY WAY OUT OF THE USE OF THIS SOFTWARE, EVEN IF ADVISED OF THE
+// POSSIBILITY OF SUCH DAMAGE.
+
+package laws
+
+import (
+	"encoding/json"
+	"io"
+	"reflect"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// JSONSchema - the parts of JSON Schema (draft 2020-12) that are needed to
+// describe laws files
+type JSONSchema struct {
+	Schema               string                 `json:"$schema,omitempty"`
+	ID                   string                 `json:"$id,omitempty"`
+	Ref                  string                 `json:"$ref,omitempty"`
+	Title                string                 `json:"title,omitempty"`
+	Description          string                 `json:"description,omitempty"`
+	Type                 interface{}            `json:"type,omitempty"` // a type or a list of them
+	Properties           map[string]*JSONSchema `json:"properties,omitempty"`
+	AdditionalProperties interface{}            `json:"additionalProperties,omitempty"` // false or a schema
+	Required             []string               `json:"required,omitempty"`
+	Items                *JSONSchema            `json:"items,omitempty"`
+	Enum                 []interface{}          `json:"enum,omitempty"`
+	Pattern              string                 `json:"pattern,omitempty"`
+	Default              interface{}            `json:"default,omitempty"`
+	Defs                 map[string]*JSONSchema `json:"$defs,omitempty"`
+}
+
+// Schema - a JSON Schema for laws files, built from the registered kinds of
+// laws so it matches what ParseFiles will accept. Laws are described in
+// $defs by their path, i.e. #/$defs/files.templates
+func Schema() *JSONSchema {
+	root := &JSONSchema{
+		Schema:               "https://json-schema.org/draft/2020-12/schema",
+		Title:                "govern laws",
+		Type:                 "object",
+		Properties:           map[string]*JSONSchema{},
+		AdditionalProperties: false,
+		Defs:                 map[string]*JSONSchema{},
+	}
+
+	for _, kind := range registeredKinds() {
+		group, typ := kind.yamlKeys()
+		if root.Properties[group] == nil {
+			root.Properties[group] = &JSONSchema{
+				Type:                 []string{"object", "null"},
+				Properties:           map[string]*JSONSchema{},
+				AdditionalProperties: false,
+			}
+		}
+		root.Properties[group].Properties[typ] = &JSONSchema{
+			Type:  []string{"array", "null"},
+			Items: &JSONSchema{Ref: "#/$defs/" + kind.Path},
+		}
+		root.Defs[kind.Path] = lawSchema(kind)
+	}
+
+	// the keys that pull in more laws files, see lawsSource.expand
+	root.Properties["include"] = &JSONSchema{
+		Type:        []string{"array", "null"},
+		Description: "laws files or directories to read too, relative to this file",
+		Items:       &JSONSchema{Type: "string"},
+	}
+	instance := structSchema(reflect.TypeOf(moduleInstance{}), reflect.Value{})
+	instance.Required = []string{"name", "module"}
+	root.Properties["modules"] = &JSONSchema{
+		Type:        []string{"array", "null"},
+		Description: "module instances, their laws get ids under modules::<name>",
+		Items:       instance,
+	}
+	return root
+}
+
+// WriteSchema - write the laws JSON Schema
+func WriteSchema(w io.Writer) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	enc.SetEscapeHTML(false)
+	return enc.Encode(Schema())
+}
+
+// lawSchema - the schema for a single law, the common fields plus the law's
+// own with the defaults its decoder fills in
+func lawSchema(kind *Kind) *JSONSchema {
+	s := structSchema(reflect.TypeOf(commonYAML{}), reflect.Value{})
+
+	// decoding nothing leaves just the defaults
+	law := kind.New()
+	err := (&yaml.Node{Kind: yaml.MappingNode, Tag: "!!map"}).Decode(law)
+	defaults := reflect.ValueOf(law)
+	if err != nil {
+		defaults = reflect.Value{}
+	}
+	own := structSchema(reflect.TypeOf(law), defaults)
+	for k, v := range own.Properties {
+		s.Properties[k] = v
+	}
+	s.AdditionalProperties = own.AdditionalProperties
+	s.Required = []string{"name"}
+	return s
+}
+
+var (
+	durationType    = reflect.TypeOf(time.Duration(0))
+	modeType        = reflect.TypeOf(Mode(0))
+	errorPolicyType = reflect.TypeOf(ErrorPolicy(""))
+	whenType        = reflect.TypeOf(When{})
+)
+
+// structSchema - the schema for a struct the way yaml.v3 decodes it, using
+// any non-zero fields of defaults as the default values
+func structSchema(t reflect.Type, defaults reflect.Value) *JSONSchema {
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+		if defaults.IsValid() {
+			defaults = defaults.Elem()
+		}
+	}
+	s := &JSONSchema{
+		Type:                 "object",
+		Properties:           map[string]*JSONSchema{},
+		AdditionalProperties: false,
+	}
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		tag := f.Tag.Get("yaml")
+		name, opts, _ := strings.Cut(tag, ",")
+		var fieldDefault reflect.Value
+		if defaults.IsValid() {
+			fieldDefault = defaults.Field(i)
+		}
+		switch {
+		case tag == "-":
+		case strings.Contains(opts, "inline") && f.Type.Kind() == reflect.Map:
+			s.AdditionalProperties = nil // anything goes
+		case strings.Contains(opts, "inline"):
+			inlined := structSchema(f.Type, fieldDefault)
+			for k, v := range inlined.Properties {
+				s.Properties[k] = v
+			}
+		case !f.IsExported():
+		default:
+			if name == "" {
+				name = strings.ToLower(f.Name)
+			}
+			fs := typeSchema(f.Type)
+			if enum := f.Tag.Get("enum"); enum != "" {
+				for _, e := range strings.Split(enum, ",") {
+					fs.Enum = append(fs.Enum, e)
+				}
+			}
+			if pattern := f.Tag.Get("pattern"); pattern != "" {
+				fs.Pattern = pattern
+			}
+			if fieldDefault.IsValid() && !fieldDefault.IsZero() {
+				fs.Default = fieldDefault.Interface()
+			}
+			s.Properties[name] = fs
+		}
+	}
+	return s
+}
+
+// typeSchema - the schema for a single field's type
+func typeSchema(t reflect.Type) *JSONSchema {
+	switch t {
+	case durationType:
+		return &JSONSchema{Type: "string", Pattern: `^([0-9.]+(ns|us|µs|ms|s|m|h))+$`, Description: "duration, i.e. 30s or 5m"}
+	case modeType:
+		return &JSONSchema{Type: []string{"string", "integer"}, Pattern: `^(0o)?[0-7]{3,4}$`, Description: "octal file mode, i.e. 0644"}
+	case errorPolicyType:
+		return &JSONSchema{Type: "string", Enum: []interface{}{string(OnErrorContinue), string(OnErrorAbort)}}
+	case whenType:
+		return &JSONSchema{Type: "string", Description: `expression on the facts, i.e. Distro.Family == "debian"`}
+	}
+
+	switch t.Kind() {
+	case reflect.Pointer:
+		return typeSchema(t.Elem())
+	case reflect.String:
+		return &JSONSchema{Type: "string"}
+	case reflect.Bool:
+		return &JSONSchema{Type: "boolean"}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return &JSONSchema{Type: "integer"}
+	case reflect.Float32, reflect.Float64:
+		return &JSONSchema{Type: "number"}
+	case reflect.Slice:
+		return &JSONSchema{Type: "array", Items: typeSchema(t.Elem())}
+	case reflect.Map:
+		return &JSONSchema{Type: "object", AdditionalProperties: typeSchema(t.Elem())}
+	case reflect.Struct:
+		return structSchema(t, reflect.Value{})
+	}
+	return &JSONSchema{}
+}
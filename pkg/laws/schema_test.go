@@ -0,0 +1,205 @@
+// Copyright © 2026 Iggy <iggy@theiggy.com>
+// All rights reserved.
+//
+// Redistribution and use in source and binary forms, with or without
+// modification, are permitted provided that the following conditions are met:
+//
+// 1. Redistributions of source code must retain the above copyright notice,
+//    this list of conditions and the following disclaimer.
+//
+// 2. Redistributions in binary form must reproduce the above copyright notice,
+//    this list of conditions and the following disclaimer in the documentation
+//    and/or other materials provided with the distribution.
+//
+// 3. Neither the name of the copyright holder nor the names of its contributors
+//    may be used to endorse or promote products derived from this software
+//    without specific prior written permission.
+//
+// THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS "AS IS"
+// AND ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT LIMITED TO, THE
+// IMPLIED WARRANTIES OF MERCHANTABILITY AND FITNESS FOR A PARTICULAR PURPOSE
+// ARE DISCLAIMED. IN NO EVENT SHALL THE COPYRIGHT HOLDER OR CONTRIBUTORS BE
+// LIABLE FOR ANY DIRECT, INDIRECT, INCIDENTAL, SPECIAL, EXEMPLARY, OR
+// CONSEQUENTIAL DAMAGES (INCLUDING, BUT NOT LIMITED TO, PROCUREMENT OF
+// SUBSTITUTE GOODS OR SERVICES; LOSS OF USE, DATA, OR PROFITS; OR BUSINESS
+// INTERRUPTION) HOWEVER CAUSED AND ON ANY THEORY OF LIABILITY, WHETHER IN
+// CONTRACT, STRICT LIABILITY, OR TORT (INCLUDING NEGLIGENCE OR OTHERWISE)
+// ARISING IN ANY WAY OUT OF THE USE OF THIS SOFTWARE, EVEN IF ADVISED OF THE
+// POSSIBILITY OF SUCH DAMAGE.
+
+package laws
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strings"
+	"testing"
+
+	"gopkg.in/yaml.v3"
+)
+
+// validate - check a decoded yaml value against the parts of JSON Schema
+// that Schema uses, the problems found
+func validate(root, s *JSONSchema, v interface{}, at string) []string {
+	if s.Ref != "" {
+		return validate(root, root.Defs[strings.TrimPrefix(s.Ref, "#/$defs/")], v, at)
+	}
+	var problems []string
+	if s.Type != nil {
+		types, ok := s.Type.([]string)
+		if !ok {
+			types = []string{s.Type.(string)}
+		}
+		if !slices.Contains(types, jsonType(v)) && !(jsonType(v) == "integer" && slices.Contains(types, "number")) {
+			return []string{fmt.Sprintf("%s: %s isn't %v", at, jsonType(v), s.Type)}
+		}
+	}
+	if s.Enum != nil && !slices.Contains(s.Enum, v) {
+		problems = append(problems, fmt.Sprintf("%s: %v isn't one of %v", at, v, s.Enum))
+	}
+	if str, ok := v.(string); ok && s.Pattern != "" && !regexp.MustCompile(s.Pattern).MatchString(str) {
+		problems = append(problems, fmt.Sprintf("%s: %q doesn't match %s", at, str, s.Pattern))
+	}
+	switch v := v.(type) {
+	case map[string]interface{}:
+		for _, req := range s.Required {
+			if _, ok := v[req]; !ok {
+				problems = append(problems, fmt.Sprintf("%s: missing %s", at, req))
+			}
+		}
+		for k, val := range v {
+			switch prop, ok := s.Properties[k]; {
+			case ok:
+				problems = append(problems, validate(root, prop, val, at+"."+k)...)
+			case s.AdditionalProperties == false:
+				problems = append(problems, fmt.Sprintf("%s: unknown key %s", at, k))
+			case s.AdditionalProperties != nil:
+				problems = append(problems, validate(root, s.AdditionalProperties.(*JSONSchema), val, at+"."+k)...)
+			}
+		}
+	case []interface{}:
+		for i, item := range v {
+			if s.Items != nil {
+				problems = append(problems, validate(root, s.Items, item, fmt.Sprintf("%s[%d]", at, i))...)
+			}
+		}
+	}
+	return problems
+}
+
+// jsonType - the JSON Schema type of a decoded yaml value
+func jsonType(v interface{}) string {
+	switch v.(type) {
+	case nil:
+		return "null"
+	case map[string]interface{}:
+		return "object"
+	case []interface{}:
+		return "array"
+	case string:
+		return "string"
+	case bool:
+		return "boolean"
+	case int, int64, uint64:
+		return "integer"
+	case float64:
+		return "number"
+	}
+	return fmt.Sprintf("%T", v)
+}
+
+func TestSchemaIncludeAndModules(t *testing.T) {
+	dir := t.TempDir()
+	files := map[string]string{
+		"main.yaml": `include:
+  - more.yaml
+modules:
+  - name: blog
+    module: modules/webapp
+    inputs:
+      port: 8081
+    after: [files::templates::/tmp/more]
+`,
+		"more.yaml": `files:
+  templates:
+    - name: /tmp/more
+      text: more
+`,
+		"modules/webapp/module.yaml": `inputs:
+  port:
+    default: 80
+`,
+		"modules/webapp/laws.yaml": `files:
+  templates:
+    - name: /tmp/webapp
+      text: "port {{ .inputs.port }}"
+      mode: 0o644
+`,
+	}
+	for name, content := range files {
+		path := filepath.Join(dir, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	schema := Schema()
+	for name, content := range files {
+		if name == "modules/webapp/module.yaml" {
+			continue
+		}
+		var doc interface{}
+		if err := yaml.Unmarshal([]byte(content), &doc); err != nil {
+			t.Fatal(err)
+		}
+		for _, problem := range validate(schema, schema, doc, name) {
+			t.Error(problem)
+		}
+	}
+
+	// and the tree is one that ParseFiles takes
+	sorted, err := ParseFiles(filepath.Join(dir, "main.yaml"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var ids []string
+	for _, v := range sorted {
+		ids = append(ids, v.Label().ID())
+	}
+	for _, id := range []string{"files::templates::/tmp/more", "modules::blog::files::templates::/tmp/webapp"} {
+		if !slices.Contains(ids, id) {
+			t.Errorf("parsed laws %v, missing %s", ids, id)
+		}
+	}
+}
+
+func TestSchemaRejects(t *testing.T) {
+	tests := []struct {
+		name, doc string
+	}{
+		{"unknown top level key", "includes: [more.yaml]\n"},
+		{"include isn't a list of files", "include: [{path: more.yaml}]\n"},
+		{"module instance without a module", "modules:\n  - name: blog\n"},
+		{"unknown module instance key", "modules:\n  - name: blog\n    module: webapp\n    input: {}\n"},
+		{"unknown law key", "files:\n  templates:\n    - name: /tmp/x\n      txt: x\n"},
+		{"bad mode", "files:\n  templates:\n    - name: /tmp/x\n      mode: u=rw\n"},
+	}
+	schema := Schema()
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var doc interface{}
+			if err := yaml.Unmarshal([]byte(tt.doc), &doc); err != nil {
+				t.Fatal(err)
+			}
+			if problems := validate(schema, schema, doc, "doc"); len(problems) == 0 {
+				t.Errorf("%q passed the schema", tt.doc)
+			}
+		})
+	}
+}
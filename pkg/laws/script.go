@@ -30,7 +30,7 @@
 package laws
 
 import (
-	"bytes"
+	"context"
 	"fmt"
 	"io"
 	"net/http"
@@ -49,18 +49,14 @@ import (
 
 // Script is a script to run
 type Script struct {
-	// Name       string
-	Shell      string
-	Script     string
-	Env        []string
-	Args       []string
-	WorkingDir string
-	Creates    []string
-	RunAs      string
-	// CommonFields
-	Name   string
-	Before []string
-	After  []string
+	CommonFields `yaml:"-"` // RunAs is uid:gid to run the script as
+
+	Shell      string   `yaml:"shell"`
+	Script     string   `yaml:"script"`
+	Env        []string `yaml:"env"`
+	Args       []string `yaml:"args"`
+	WorkingDir string   `yaml:"working_dir"`
+	Creates    []string `yaml:"creates"`
 }
 
 // UnmarshalYAML implements the Unmarshaler interface.
@@ -71,124 +67,118 @@ func (s *Script) UnmarshalYAML(value *yaml.Node) error {
 	//
 
 	log.Trace().Interface("Node", value).Msg("UnmarshalYAML Script")
-	if value.Tag != "!!map" {
-		return fmt.Errorf("unable to unmarshal yaml: value not map (%s)", value.Tag)
-	}
 
-	for i, node := range value.Content {
-		log.Trace().Interface("node1", node).Msg("")
-		switch node.Value {
-		case "name":
-			s.Name = value.Content[i+1].Value
-		case "shell":
-			s.Shell = value.Content[i+1].Value
-		case "script":
-			s.Script = value.Content[i+1].Value
-		case "creates":
-			log.Trace().Interface("node2", value.Content[i+1].Content).Msg("")
-			for _, v := range value.Content[i+1].Content {
-				s.Creates = append(s.Creates, v.Value)
-			}
-		case "env":
-			log.Trace().Interface("node2", value.Content[i+1].Content).Msg("")
-		case "args":
-			log.Trace().Interface("node2", value.Content[i+1].Content).Msg("")
-		case "working_dir":
-			s.WorkingDir = value.Content[i+1].Value
-		case "run_as":
-			s.RunAs = value.Content[i+1].Value
-		case "before":
-			for _, j := range value.Content[i+1].Content {
-				s.Before = append(s.Before, j.Value)
-			}
-		case "after":
-			for _, j := range value.Content[i+1].Content {
-				s.After = append(s.After, j.Value)
-			}
+	type rawScript Script
+	return value.Decode((*rawScript)(s))
+}
+
+// Ensure - run the script unless all the files it creates already exist
+func (s *Script) Ensure(ctx context.Context, pretend bool) *Result {
+	return s.Run(ctx, pretend)
+}
+
+// created - whether the script has creates and they all exist
+func (s *Script) created() bool {
+	if len(s.Creates) == 0 {
+		return false
+	}
+	for _, crts := range s.Creates {
+		stat, err := os.Stat(crts)
+		if err != nil {
+			return false
 		}
+		log.Debug().Str("creates", crts).Interface("stat", stat).Msg("creates file already exists")
 	}
+	return true
+}
 
-	return nil
+// LockKey - scripts can do anything (including running the package
+// manager), so only one runs at a time
+func (s *Script) LockKey() string {
+	return "script"
 }
 
 // Run - run the script
-func (s *Script) Run(pretend bool) error {
+func (s *Script) Run(ctx context.Context, pretend bool) *Result {
 	log.Trace().Interface("script", s).Msg("script run")
 
+	if s.created() {
+		return Unchanged(fmt.Sprintf("creates files already exist: %s", strings.Join(s.Creates, ", ")))
+	}
+
 	if pretend {
 		log.Info().Str("script", s.Script).Str("shell", s.Shell).Interface("s", s).Msg("Would run script")
-	} else {
-		log.Debug().Str("script", s.Script).Str("shell", s.Shell).Interface("s", s).Msg("Running script")
-
-		for _, crts := range s.Creates {
-			stat, err := os.Stat(crts)
-			if err == nil {
-				log.Debug().Str("creates", crts).Interface("stat", stat).Err(err).Msg("creates file already exists")
-				return nil
-			}
-		}
+		return Changed("script would run", "", "")
+	}
 
-		// check if the script is a URL and download if so
-		_, err := url.ParseRequestURI(s.Script)
-		if err == nil {
-			log.Debug().Str("script", s.Script).Msg("script is a URL")
-			resp, err := http.Get(s.Script)
-			if err != nil {
-				log.Warn().Err(err).Msg("could not download script")
-				return err
-			}
-			defer resp.Body.Close()
-			outfile, err := os.Create("tmp.sh")
-			if err != nil {
-				log.Warn().Err(err).Msg("could not create tmp.sh")
-				return err
-			}
-			size, err := io.Copy(outfile, resp.Body)
-			if err != nil {
-				log.Warn().Err(err).Msg("could not download script")
-				return err
-			}
-			log.Debug().Int64("size", size).Msg("downloaded script")
-			if resp.StatusCode > 299 {
-				log.Warn().Err(err).Msg("could not download script")
-				return err
-			}
-			s.Script = "tmp.sh"
-		}
+	log.Debug().Str("script", s.Script).Str("shell", s.Shell).Interface("s", s).Msg("Running script")
 
-		var stdOut, stdErr bytes.Buffer
-
-		cmd := exec.Command(s.Shell, s.Script)
-		cmd.Stdout = &stdOut
-		cmd.Stderr = &stdErr
-
-		if s.RunAs != "" {
-			ids := strings.Split(s.RunAs, ":")
-			uid, err := strconv.ParseUint(ids[0], 10, 32)
-			if err != nil {
-				log.Warn().Err(err).Msg("could not convert uid")
-				return err
-			}
-			gid, err := strconv.ParseUint(ids[1], 10, 32)
-			if err != nil {
-				log.Warn().Err(err).Msg("could not convert gid")
-				return err
-			}
-			cmd.SysProcAttr = &unix.SysProcAttr{
-				Credential: &syscall.Credential{
-					Uid: uint32(uid),
-					Gid: uint32(gid),
-				},
-			}
+	// check if the script is a URL and download if so
+	_, err := url.ParseRequestURI(s.Script)
+	if err == nil {
+		log.Debug().Str("script", s.Script).Msg("script is a URL")
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, s.Script, nil)
+		if err != nil {
+			return Failed("could not download script", err)
+		}
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			log.Warn().Err(err).Msg("could not download script")
+			return Failed("could not download script", err)
+		}
+		defer resp.Body.Close()
+		outfile, err := os.Create("tmp.sh")
+		if err != nil {
+			log.Warn().Err(err).Msg("could not create tmp.sh")
+			return Failed("could not create tmp.sh", err)
+		}
+		size, err := io.Copy(outfile, resp.Body)
+		if err != nil {
+			log.Warn().Err(err).Msg("could not download script")
+			return Failed("could not download script", err)
+		}
+		log.Debug().Int64("size", size).Msg("downloaded script")
+		if resp.StatusCode > 299 {
+			log.Warn().Err(err).Msg("could not download script")
+			return Failed("could not download script", fmt.Errorf("HTTP %d", resp.StatusCode))
 		}
+		s.Script = "tmp.sh"
+	}
+
+	cmd := exec.CommandContext(ctx, s.Shell, s.Script)
 
-		err = cmd.Run()
+	if s.RunAs != "" {
+		ids := strings.Split(s.RunAs, ":")
+		if len(ids) != 2 {
+			return Failed(fmt.Sprintf("run_as should be uid:gid, got %q", s.RunAs), nil)
+		}
+		uid, err := strconv.ParseUint(ids[0], 10, 32)
+		if err != nil {
+			log.Warn().Err(err).Msg("could not convert uid")
+			return Failed("could not convert uid", err)
+		}
+		gid, err := strconv.ParseUint(ids[1], 10, 32)
 		if err != nil {
-			log.Error().Err(err).Interface("script", s).Msg("failed to run script")
+			log.Warn().Err(err).Msg("could not convert gid")
+			return Failed("could not convert gid", err)
+		}
+		cmd.SysProcAttr = &unix.SysProcAttr{
+			Credential: &syscall.Credential{
+				Uid: uint32(uid),
+				Gid: uint32(gid),
+			},
 		}
-		log.Info().Str("stdErr", stdErr.String()).Interface("script", s).Msg("script stdErr")
-		log.Debug().Str("stdOut", stdOut.String()).Interface("script", s).Msg("script stdOut")
 	}
 
-	return nil
+	stdOut, stdErr, err := runCmd(ctx, cmd)
+	if err != nil {
+		log.Error().Err(err).Interface("script", s).Msg("failed to run script")
+	}
+	log.Info().Str("stdErr", stdErr).Interface("script", s).Msg("script stdErr")
+	log.Debug().Str("stdOut", stdOut).Interface("script", s).Msg("script stdOut")
+	if err != nil {
+		return Failed("failed to run script", err)
+	}
+
+	return Changed("script ran", "", "")
 }
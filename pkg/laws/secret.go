@@ -0,0 +1,481 @@
+// Copyright © 2026 Iggy <iggy@theiggy.com>
+// All rights reserved.
+//
+// Redistribution and use in source and binary forms, with or without
+// modification, are permitted provided that the following conditions are met:
+//
+// 1. Redistributions of source code must retain the above copyright notice,
+//    this list of conditions and the following disclaimer.
+//
+// 2. Redistributions in binary form must reproduce the above copyright notice,
+//    this list of conditions and the following disclaimer in the documentation
+//    and/or other materials provided with the distribution.
+//
+// 3. Neither the name of the copyright holder nor the names of its contributors
+//    may be used to endorse or promote products derived from this software
+//    without specific prior written permission.
+//
+// THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS "AS IS"
+// AND ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT LIMITED TO, THE
+// IMPLIED WARRANTIES OF MERCHANTABILITY AND FITNESS FOR A PARTICULAR PURPOSE
+// ARE DISCLAIMED. IN NO EVENT SHALL THE COPYRIGHT HOLDER OR CONTRIBUTORS BE
+// LIABLE FOR ANY DIRECT, INDIRECT, INCIDENTAL, SPECIAL, EXEMPLARY, OR
+// CONSEQUENTIAL DAMAGES (INCLUDING, BUT NOT LIMITED TO, PROCUREMENT OF
+// SUBSTITUTE GOODS OR SERVICES; LOSS OF USE, DATA, OR PROFITS; OR BUSINESS
+// INTERRUPTION) HOWEVER CAUSED AND ON ANY THEORY OF LIABILITY, WHETHER IN
+// CONTRACT, STRICT LIABILITY, OR TORT (INCLUDING NEGLIGENCE OR OTHERWISE)
+// ARISING IN ANY WAY OUT OF THE USE OF THIS SOFTWARE, EVEN IF ADVISED OF THE
+// POSSIBILITY OF SUCH DAMAGE.
+
+package laws
+
+import (
+	"bufio"
+	"crypto/hkdf"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+
+	"golang.org/x/crypto/chacha20poly1305"
+	"golang.org/x/crypto/curve25519"
+)
+
+// Secrets are encrypted to one or more recipients (X25519 public keys), like
+// age. A random file key encrypts the value and gets wrapped for each
+// recipient, so any of their identities (private keys) can decrypt it.
+//
+//	GOVERN-SECRET:base64(magic | count | count * (ephemeral key | wrapped file key) | nonce | ciphertext)
+
+const (
+	// SecretPrefix - what an encrypted value starts with
+	SecretPrefix = "GOVERN-SECRET:"
+	// RecipientPrefix - what a public key starts with
+	RecipientPrefix = "govern1"
+	// IdentityPrefix - what a private key starts with
+	IdentityPrefix = "GOVERN-SECRET-KEY-"
+	// DefaultIdentityFile - the host key used to decrypt secrets in laws
+	DefaultIdentityFile = "/etc/govern/secret.key"
+
+	secretMagic   = "govern-secret/v1"
+	wrappedLen    = chacha20poly1305.KeySize + chacha20poly1305.Overhead
+	stanzaLen     = curve25519.PointSize + wrappedLen
+	payloadNonceN = 16
+)
+
+var (
+	// IdentityFile - where the secret template function gets its key from
+	IdentityFile = DefaultIdentityFile
+
+	secretRe = regexp.MustCompile(regexp.QuoteMeta(SecretPrefix) + `[A-Za-z0-9+/=]+`)
+)
+
+// Recipient - a public key secrets can be encrypted to
+type Recipient struct {
+	pub []byte
+}
+
+func (r *Recipient) String() string {
+	return RecipientPrefix + base64.RawURLEncoding.EncodeToString(r.pub)
+}
+
+// ParseRecipient - parse a govern1... public key
+func ParseRecipient(s string) (*Recipient, error) {
+	s = strings.TrimSpace(s)
+	if !strings.HasPrefix(s, RecipientPrefix) {
+		return nil, fmt.Errorf("recipient %q should start with %s", s, RecipientPrefix)
+	}
+	pub, err := base64.RawURLEncoding.DecodeString(strings.TrimPrefix(s, RecipientPrefix))
+	if err != nil || len(pub) != curve25519.PointSize {
+		return nil, fmt.Errorf("recipient %q isn't a valid public key", s)
+	}
+	return &Recipient{pub: pub}, nil
+}
+
+// LoadRecipients - read public keys from a file, one per line, blank lines
+// and # comments are ignored
+func LoadRecipients(path string) ([]*Recipient, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	var recipients []*Recipient
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		r, err := ParseRecipient(line)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", path, err)
+		}
+		recipients = append(recipients, r)
+	}
+	return recipients, scanner.Err()
+}
+
+// Identity - a private key secrets can be decrypted with
+type Identity struct {
+	priv []byte
+}
+
+// GenerateIdentity - make a new random identity
+func GenerateIdentity() (*Identity, error) {
+	priv := make([]byte, curve25519.ScalarSize)
+	_, err := rand.Read(priv)
+	if err != nil {
+		return nil, err
+	}
+	return &Identity{priv: priv}, nil
+}
+
+func (i *Identity) String() string {
+	return IdentityPrefix + base64.RawURLEncoding.EncodeToString(i.priv)
+}
+
+// Recipient - the public key that goes with the identity
+func (i *Identity) Recipient() *Recipient {
+	pub, _ := curve25519.X25519(i.priv, curve25519.Basepoint)
+	return &Recipient{pub: pub}
+}
+
+// ParseIdentity - parse a GOVERN-SECRET-KEY-... private key
+func ParseIdentity(s string) (*Identity, error) {
+	s = strings.TrimSpace(s)
+	if !strings.HasPrefix(s, IdentityPrefix) {
+		return nil, fmt.Errorf("identity should start with %s", IdentityPrefix)
+	}
+	priv, err := base64.RawURLEncoding.DecodeString(strings.TrimPrefix(s, IdentityPrefix))
+	if err != nil || len(priv) != curve25519.ScalarSize {
+		return nil, errors.New("identity isn't a valid private key")
+	}
+	return &Identity{priv: priv}, nil
+}
+
+// LoadIdentity - read a private key from a file, the first line that isn't
+// blank or a # comment
+func LoadIdentity(path string) (*Identity, error) {
+	raw, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	for _, line := range strings.Split(string(raw), "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		id, err := ParseIdentity(line)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", path, err)
+		}
+		return id, nil
+	}
+	return nil, fmt.Errorf("%s: no identity found", path)
+}
+
+// secretKey - derive a chacha20poly1305 key
+func secretKey(secret, salt []byte, info string) ([]byte, error) {
+	return hkdf.Key(sha256.New, secret, salt, info, chacha20poly1305.KeySize)
+}
+
+// EncryptSecret - encrypt a value so any of the recipients can decrypt it
+func EncryptSecret(plain []byte, recipients []*Recipient) (string, error) {
+	if len(recipients) == 0 {
+		return "", errors.New("no recipients to encrypt to")
+	}
+	if len(recipients) > 255 {
+		return "", errors.New("too many recipients")
+	}
+	fileKey := make([]byte, chacha20poly1305.KeySize)
+	_, err := rand.Read(fileKey)
+	if err != nil {
+		return "", err
+	}
+
+	buf := append([]byte(secretMagic), byte(len(recipients)))
+	for _, r := range recipients {
+		eph := make([]byte, curve25519.ScalarSize)
+		_, err = rand.Read(eph)
+		if err != nil {
+			return "", err
+		}
+		ephPub, err := curve25519.X25519(eph, curve25519.Basepoint)
+		if err != nil {
+			return "", err
+		}
+		shared, err := curve25519.X25519(eph, r.pub)
+		if err != nil {
+			return "", err
+		}
+		wrapKey, err := secretKey(shared, append(append([]byte{}, ephPub...), r.pub...), secretMagic+" x25519")
+		if err != nil {
+			return "", err
+		}
+		aead, err := chacha20poly1305.New(wrapKey)
+		if err != nil {
+			return "", err
+		}
+		buf = append(buf, ephPub...)
+		buf = aead.Seal(buf, make([]byte, aead.NonceSize()), fileKey, nil)
+	}
+
+	nonce := make([]byte, payloadNonceN)
+	_, err = rand.Read(nonce)
+	if err != nil {
+		return "", err
+	}
+	payloadKey, err := secretKey(fileKey, nonce, secretMagic+" payload")
+	if err != nil {
+		return "", err
+	}
+	aead, err := chacha20poly1305.New(payloadKey)
+	if err != nil {
+		return "", err
+	}
+	// the header is authenticated along with the value
+	header := buf
+	buf = append(append([]byte{}, header...), nonce...)
+	buf = aead.Seal(buf, make([]byte, aead.NonceSize()), plain, header)
+
+	return SecretPrefix + base64.StdEncoding.EncodeToString(buf), nil
+}
+
+// DecryptSecret - decrypt a value encrypted with EncryptSecret
+func DecryptSecret(value string, id *Identity) ([]byte, error) {
+	value = strings.TrimSpace(value)
+	if !strings.HasPrefix(value, SecretPrefix) {
+		return nil, fmt.Errorf("not an encrypted secret, should start with %s", SecretPrefix)
+	}
+	buf, err := base64.StdEncoding.DecodeString(strings.TrimPrefix(value, SecretPrefix))
+	if err != nil {
+		return nil, fmt.Errorf("bad secret: %w", err)
+	}
+	if len(buf) < len(secretMagic)+1 || string(buf[:len(secretMagic)]) != secretMagic {
+		return nil, errors.New("bad secret: unknown format")
+	}
+	count := int(buf[len(secretMagic)])
+	headerLen := len(secretMagic) + 1 + count*stanzaLen
+	if len(buf) < headerLen+payloadNonceN+chacha20poly1305.Overhead {
+		return nil, errors.New("bad secret: too short")
+	}
+
+	pub := id.Recipient().pub
+	var fileKey []byte
+	for i := 0; i < count && fileKey == nil; i++ {
+		stanza := buf[len(secretMagic)+1+i*stanzaLen:][:stanzaLen]
+		ephPub, wrapped := stanza[:curve25519.PointSize], stanza[curve25519.PointSize:]
+		shared, err := curve25519.X25519(id.priv, ephPub)
+		if err != nil {
+			continue
+		}
+		wrapKey, err := secretKey(shared, append(append([]byte{}, ephPub...), pub...), secretMagic+" x25519")
+		if err != nil {
+			return nil, err
+		}
+		aead, err := chacha20poly1305.New(wrapKey)
+		if err != nil {
+			return nil, err
+		}
+		fileKey, _ = aead.Open(nil, make([]byte, aead.NonceSize()), wrapped, nil)
+	}
+	if fileKey == nil {
+		return nil, errors.New("secret isn't encrypted to this identity")
+	}
+
+	nonce := buf[headerLen : headerLen+payloadNonceN]
+	payloadKey, err := secretKey(fileKey, nonce, secretMagic+" payload")
+	if err != nil {
+		return nil, err
+	}
+	aead, err := chacha20poly1305.New(payloadKey)
+	if err != nil {
+		return nil, err
+	}
+	plain, err := aead.Open(nil, make([]byte, aead.NonceSize()), buf[headerLen+payloadNonceN:], buf[:headerLen])
+	if err != nil {
+		return nil, errors.New("secret has been tampered with")
+	}
+	return plain, nil
+}
+
+// RekeySecrets - re-encrypt every secret in some text to new recipients,
+// the text can be a secret on its own or i.e. a laws file with secrets in it
+func RekeySecrets(text []byte, id *Identity, recipients []*Recipient) ([]byte, int, error) {
+	var errs []error
+	count := 0
+	out := secretRe.ReplaceAllFunc(text, func(match []byte) []byte {
+		plain, err := DecryptSecret(string(match), id)
+		if err != nil {
+			errs = append(errs, err)
+			return match
+		}
+		value, err := EncryptSecret(plain, recipients)
+		if err != nil {
+			errs = append(errs, err)
+			return match
+		}
+		count++
+		return []byte(value)
+	})
+	return out, count, errors.Join(errs...)
+}
+
+var (
+	identityMu sync.Mutex
+	identity   *Identity
+)
+
+// hostIdentity - the identity from IdentityFile, only read once
+func hostIdentity() (*Identity, error) {
+	identityMu.Lock()
+	defer identityMu.Unlock()
+	if identity != nil {
+		return identity, nil
+	}
+	id, err := LoadIdentity(IdentityFile)
+	if err != nil {
+		return nil, err
+	}
+	identity = id
+	return identity, nil
+}
+
+// secretFunc - the secret template function. It takes an encrypted value,
+// or the path to a file with one in it (relative to the laws file). When
+// linting there may not be a key around, so the secret is only checked to
+// look right.
+func secretFunc(dir string, lint bool) func(string) (string, error) {
+	return func(value string) (string, error) {
+		if !strings.HasPrefix(strings.TrimSpace(value), SecretPrefix) {
+			path := value
+			if !filepath.IsAbs(path) {
+				path = filepath.Join(dir, path)
+			}
+			raw, err := os.ReadFile(path)
+			if err != nil {
+				return "", fmt.Errorf("secret: %w", err)
+			}
+			value = string(raw)
+		}
+		if lint {
+			_, err := base64.StdEncoding.DecodeString(strings.TrimPrefix(strings.TrimSpace(value), SecretPrefix))
+			if !strings.HasPrefix(strings.TrimSpace(value), SecretPrefix) || err != nil {
+				return "", errors.New("secret: not an encrypted secret")
+			}
+			return redacted, nil
+		}
+
+		id, err := hostIdentity()
+		if err != nil {
+			return "", fmt.Errorf("secret: can't load identity: %w", err)
+		}
+		plain, err := DecryptSecret(value, id)
+		if err != nil {
+			return "", fmt.Errorf("secret: %w", err)
+		}
+		reveal(string(plain))
+		return string(plain), nil
+	}
+}
+
+// redacted - what decrypted secrets are replaced with in logs and reports
+const redacted = "[REDACTED]"
+
+var (
+	revealedMu sync.RWMutex
+	revealed   []string // every form of every decrypted secret, longest first
+)
+
+// reveal - remember a decrypted secret so it can be redacted, along with
+// how it looks escaped in json and quoted log fields
+func reveal(secret string) {
+	if strings.TrimSpace(secret) == "" {
+		return
+	}
+	forms := []string{secret, strings.Trim(strconv.Quote(secret), `"`)}
+	if j, err := json.Marshal(secret); err == nil {
+		forms = append(forms, strings.Trim(string(j), `"`))
+	}
+
+	revealedMu.Lock()
+	defer revealedMu.Unlock()
+	for _, f := range forms {
+		known := false
+		for _, r := range revealed {
+			known = known || r == f
+		}
+		if !known {
+			revealed = append(revealed, f)
+		}
+	}
+	sort.Slice(revealed, func(i, j int) bool { return len(revealed[i]) > len(revealed[j]) })
+}
+
+// Redact - replace any decrypted secrets in a string
+func Redact(s string) string {
+	revealedMu.RLock()
+	defer revealedMu.RUnlock()
+	for _, r := range revealed {
+		s = strings.ReplaceAll(s, r, redacted)
+	}
+	return s
+}
+
+// redactWriter - redacts secrets from everything written through it
+type redactWriter struct {
+	w io.Writer
+}
+
+// RedactWriter - wrap a writer (i.e. the log output) so decrypted secrets
+// never make it out
+func RedactWriter(w io.Writer) io.Writer {
+	return &redactWriter{w: w}
+}
+
+func (rw *redactWriter) Write(p []byte) (int, error) {
+	revealedMu.RLock()
+	none := len(revealed) == 0
+	revealedMu.RUnlock()
+	if none {
+		return rw.w.Write(p)
+	}
+	_, err := rw.w.Write([]byte(Redact(string(p))))
+	return len(p), err
+}
+
+// redactResult - a copy of a result with any secrets redacted
+func redactResult(r *Result) *Result {
+	c := *r
+	c.Message = Redact(c.Message)
+	c.Before = Redact(c.Before)
+	c.After = Redact(c.After)
+	c.Error = Redact(c.Error)
+	c.Diff = Redact(c.Diff)
+	if c.Command != nil {
+		cmd := *c.Command
+		cmd.Command = Redact(cmd.Command)
+		cmd.Stdout = Redact(cmd.Stdout)
+		cmd.Stderr = Redact(cmd.Stderr)
+		c.Command = &cmd
+	}
+	if c.Commands != nil {
+		c.Commands = append([]CommandRun{}, c.Commands...)
+		for i := range c.Commands {
+			c.Commands[i].Command = Redact(c.Commands[i].Command)
+		}
+	}
+	return &c
+}
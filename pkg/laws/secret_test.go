@@ -0,0 +1,177 @@
+// Copyright © 2026 Iggy <iggy@theiggy.com>
+// All rights reserved.
+//
+// Redistribution and use in source and binary forms, with or without
+// modification, are permitted provided that the following conditions are met:
+//
+// 1. Redistributions of source code must retain the above copyright notice,
+//    this list of conditions and the following disclaimer.
+//
+// 2. Redistributions in binary form must reproduce the above copyright notice,
+//    this list of conditions and the following disclaimer in the documentation
+//    and/or other materials provided with the distribution.
+//
+// 3. Neither the name of the copyright holder nor the names of its contributors
+//    may be used to endorse or promote products derived from this software
+//    without specific prior written permission.
+//
+// THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS "AS IS"
+// AND ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT LIMITED TO, THE
+// IMPLIED WARRANTIES OF MERCHANTABILITY AND FITNESS FOR A PARTICULAR PURPOSE
+// ARE DISCLAIMED. IN NO EVENT SHALL THE COPYRIGHT HOLDER OR CONTRIBUTORS BE
+// LIABLE FOR ANY DIRECT, INDIRECT, INCIDENTAL, SPECIAL, EXEMPLARY, OR
+// CONSEQUENTIAL DAMAGES (INCLUDING, BUT NOT LIMITED TO, PROCUREMENT OF
+// SUBSTITUTE GOODS OR SERVICES; LOSS OF USE, DATA, OR PROFITS; OR BUSINESS
+// INTERRUPTION) HOWEVER CAUSED AND ON ANY THEORY OF LIABILITY, WHETHER IN
+// CONTRACT, STRICT LIABILITY, OR TORT (INCLUDING NEGLIGENCE OR OTHERWISE)
+// ARISING IN ANY WAY OUT OF THE USE OF THIS SOFTWARE, EVEN IF ADVISED OF THE
+// POSSIBILITY OF SUCH DAMAGE.
+
+package laws
+
+import (
+	"bytes"
+	"encoding/base64"
+	"strings"
+	"testing"
+)
+
+func newIdentity(t *testing.T) *Identity {
+	t.Helper()
+	id, err := GenerateIdentity()
+	if err != nil {
+		t.Fatal(err)
+	}
+	// keys go through their text form like they do from disk
+	id, err = ParseIdentity(id.String())
+	if err != nil {
+		t.Fatal(err)
+	}
+	return id
+}
+
+func TestSecretRoundTrip(t *testing.T) {
+	alice, bob := newIdentity(t), newIdentity(t)
+	bobPub, err := ParseRecipient(bob.Recipient().String())
+	if err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		name       string
+		plain      []byte
+		recipients []*Recipient
+	}{
+		{"empty", []byte{}, []*Recipient{alice.Recipient()}},
+		{"text", []byte("hunter2"), []*Recipient{alice.Recipient()}},
+		{"binary", []byte{0, 1, 2, 0xff, '\n'}, []*Recipient{alice.Recipient(), bobPub}},
+		{"long", bytes.Repeat([]byte("secret "), 10000), []*Recipient{bobPub, alice.Recipient()}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			value, err := EncryptSecret(tt.plain, tt.recipients)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !strings.HasPrefix(value, SecretPrefix) {
+				t.Fatalf("secret %q doesn't start with %s", value, SecretPrefix)
+			}
+			if bytes.Contains([]byte(value), tt.plain) && len(tt.plain) > 0 {
+				t.Fatal("secret has the plain text in it")
+			}
+			for _, id := range []*Identity{alice, bob} {
+				encryptedTo := false
+				for _, r := range tt.recipients {
+					encryptedTo = encryptedTo || r.String() == id.Recipient().String()
+				}
+				plain, err := DecryptSecret(value, id)
+				switch {
+				case encryptedTo && err != nil:
+					t.Errorf("decrypt: %v", err)
+				case encryptedTo && !bytes.Equal(plain, tt.plain):
+					t.Errorf("decrypted %q, want %q", plain, tt.plain)
+				case !encryptedTo && err == nil:
+					t.Error("decrypted with an identity it wasn't encrypted to")
+				}
+			}
+		})
+	}
+}
+
+func TestDecryptSecretWrongIdentity(t *testing.T) {
+	value, err := EncryptSecret([]byte("hunter2"), []*Recipient{newIdentity(t).Recipient()})
+	if err != nil {
+		t.Fatal(err)
+	}
+	plain, err := DecryptSecret(value, newIdentity(t))
+	if err == nil {
+		t.Fatalf("decrypted %q with the wrong identity", plain)
+	}
+}
+
+func TestDecryptSecretTampered(t *testing.T) {
+	id := newIdentity(t)
+	value, err := EncryptSecret([]byte("hunter2"), []*Recipient{id.Recipient()})
+	if err != nil {
+		t.Fatal(err)
+	}
+	buf, err := base64.StdEncoding.DecodeString(strings.TrimPrefix(value, SecretPrefix))
+	if err != nil {
+		t.Fatal(err)
+	}
+	headerLen := len(secretMagic) + 1 + stanzaLen
+	tests := []struct {
+		name string
+		at   int
+	}{
+		{"magic", 0},
+		{"count", len(secretMagic)},
+		{"ephemeral key", len(secretMagic) + 1},
+		{"wrapped key", headerLen - 1},
+		{"nonce", headerLen},
+		{"ciphertext", headerLen + payloadNonceN},
+		{"tag", len(buf) - 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tampered := append([]byte{}, buf...)
+			tampered[tt.at] ^= 0x01
+			plain, err := DecryptSecret(SecretPrefix+base64.StdEncoding.EncodeToString(tampered), id)
+			if err == nil {
+				t.Fatalf("decrypted %q from a tampered secret", plain)
+			}
+		})
+	}
+	t.Run("truncated", func(t *testing.T) {
+		plain, err := DecryptSecret(SecretPrefix+base64.StdEncoding.EncodeToString(buf[:len(buf)-1]), id)
+		if err == nil {
+			t.Fatalf("decrypted %q from a truncated secret", plain)
+		}
+	})
+}
+
+func TestRekeySecrets(t *testing.T) {
+	old, next := newIdentity(t), newIdentity(t)
+	value, err := EncryptSecret([]byte("hunter2"), []*Recipient{old.Recipient()})
+	if err != nil {
+		t.Fatal(err)
+	}
+	text := []byte("users:\n  present:\n    - name: bob\n      password: " + value + "\n")
+	out, n, err := RekeySecrets(text, old, []*Recipient{next.Recipient()})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n != 1 {
+		t.Errorf("rekeyed %d secrets, want 1", n)
+	}
+	rekeyed := secretRe.Find(out)
+	if rekeyed == nil || !bytes.HasPrefix(out, text[:bytes.Index(text, []byte(SecretPrefix))]) {
+		t.Fatalf("rekeyed text is mangled: %s", out)
+	}
+	plain, err := DecryptSecret(string(rekeyed), next)
+	if err != nil || string(plain) != "hunter2" {
+		t.Errorf("decrypt rekeyed secret = %q, %v", plain, err)
+	}
+	if _, err := DecryptSecret(string(rekeyed), old); err == nil {
+		t.Error("the old identity can still decrypt the rekeyed secret")
+	}
+}
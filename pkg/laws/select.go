@@ -0,0 +1,131 @@
+// Copyright © 2026 Iggy <iggy@theiggy.com>
+// All rights reserved.
+//
+// Redistribution and use in source and binary forms, with or without
+// modification, are permitted provided that the following conditions are met:
+//
+// 1. Redistributions of source code must retain the above copyright notice,
+//    this list of conditions and the following disclaimer.
+//
+// 2. Redistributions in binary form must reproduce the above copyright notice,
+//    this list of conditions and the following disclaimer in the documentation
+//    and/or other materials provided with the distribution.
+//
+// 3. Neither the name of the copyright holder nor the names of its contributors
+//    may be used to endorse or promote products derived from this software
+//    without specific prior written permission.
+//
+// THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS "AS IS"
+// AND ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT LIMITED TO, THE
+// IMPLIED WARRANTIES OF MERCHANTABILITY AND FITNESS FOR A PARTICULAR PURPOSE
+// ARE DISCLAIMED. IN NO EVENT SHALL THE COPYRIGHT HOLDER OR CONTRIBUTORS BE
+// LIABLE FOR ANY DIRECT, INDIRECT, INCIDENTAL, SPECIAL, EXEMPLARY, OR
+// CONSEQUENTIAL DAMAGES (INCLUDING, BUT NOT LIMITED TO, PROCUREMENT OF
+// SUBSTITUTE GOODS OR SERVICES; LOSS OF USE, DATA, OR PROFITS; OR BUSINESS
+// INTERRUPTION) HOWEVER CAUSED AND ON ANY THEORY OF LIABILITY, WHETHER IN
+// CONTRACT, STRICT LIABILITY, OR TORT (INCLUDING NEGLIGENCE OR OTHERWISE)
+// ARISING IN ANY WAY OUT OF THE USE OF THIS SOFTWARE, EVEN IF ADVISED OF THE
+// POSSIBILITY OF SUCH DAMAGE.
+
+package laws
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/hmdsefi/gograph"
+	"github.com/rs/zerolog/log"
+)
+
+// Selector - pick which laws to run instead of the whole tree
+type Selector struct {
+	Only   []string `json:"only,omitempty"`    // ids, group::type or group to run
+	Skip   []string `json:"skip,omitempty"`    // ids, group::type or group to leave out
+	Tags   []string `json:"tags,omitempty"`    // run laws with any of these tags
+	NoDeps bool     `json:"no_deps,omitempty"` // don't pull in what the selected laws come after
+}
+
+// Empty - nothing to filter, run everything
+func (s Selector) Empty() bool {
+	return len(s.Only) == 0 && len(s.Skip) == 0 && len(s.Tags) == 0
+}
+
+// matches - the law is one of the ids, or in one of the groups
+func matches(n *LawNode, patterns []string) bool {
+	id := n.ID()
+	for _, p := range patterns {
+		p = strings.ToLower(p)
+		if id == p || strings.HasPrefix(id, p+"::") {
+			return true
+		}
+	}
+	return false
+}
+
+// tagged - the law has any of the tags
+func tagged(n *LawNode, tags []string) bool {
+	if n.Options == nil {
+		return false
+	}
+	for _, t := range n.Options.Tags {
+		if slices.Contains(tags, t) {
+			return true
+		}
+	}
+	return false
+}
+
+// Filter - the vertices from ParseFiles that should be run, still in
+// order. Laws matching Only or Tags are selected (everything if neither is
+// set), then everything they come after is added unless NoDeps, then Skip
+// is taken out.
+func (s Selector) Filter(sorted []*gograph.Vertex[*LawNode]) ([]*gograph.Vertex[*LawNode], error) {
+	if s.Empty() {
+		return sorted, nil
+	}
+	g := NewLawGraph(sorted)
+
+	// catch typos, a selector that doesn't match anything is probably wrong
+	for _, p := range append(append([]string{}, s.Only...), s.Skip...) {
+		if !slices.ContainsFunc(g.Nodes, func(n *LawNode) bool { return matches(n, []string{p}) }) {
+			return nil, fmt.Errorf("%q doesn't match any laws", p)
+		}
+	}
+
+	selected := map[*LawNode]bool{}
+	for _, n := range g.Nodes {
+		if isRoot(n) {
+			continue
+		}
+		if (len(s.Only) == 0 && len(s.Tags) == 0) || matches(n, s.Only) || tagged(n, s.Tags) {
+			selected[n] = true
+		}
+	}
+
+	if !s.NoDeps {
+		var addParents func(n *LawNode)
+		addParents = func(n *LawNode) {
+			for _, p := range g.parents[n] {
+				if !isRoot(p) && !selected[p] {
+					log.Debug().Str("law", p.ID()).Str("for", n.ID()).Msg("selecting dependency")
+					selected[p] = true
+					addParents(p)
+				}
+			}
+		}
+		for n := range selected {
+			addParents(n)
+		}
+	}
+
+	var filtered []*gograph.Vertex[*LawNode]
+	for _, v := range sorted {
+		n := v.Label()
+		if isRoot(n) || (selected[n] && !matches(n, s.Skip)) {
+			filtered = append(filtered, v)
+		}
+	}
+	log.Debug().Int("selected", len(filtered)-1).Int("total", len(sorted)-1).Msg("filtered laws")
+	return filtered, nil
+}
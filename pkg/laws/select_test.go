@@ -0,0 +1,111 @@
+// Copyright © 2026 Iggy <iggy@theiggy.com>
+// All rights reserved.
+//
+// Redistribution and use in source and binary forms, with or without
+// modification, are permitted provided that the following conditions are met:
+//
+// 1. Redistributions of source code must retain the above copyright notice,
+//    this list of conditions and the following disclaimer.
+//
+// 2. Redistributions in binary form must reproduce the above copyright notice,
+//    this list of conditions and the following disclaimer in the documentation
+//    and/or other materials provided with the distribution.
+//
+// 3. Neither the name of the copyright holder nor the names of its contributors
+//    may be used to endorse or promote products derived from this software
+//    without specific prior written permission.
+//
+// THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS "AS IS"
+// AND ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT LIMITED TO, THE
+// IMPLIED WARRANTIES OF MERCHANTABILITY AND FITNESS FOR A PARTICULAR PURPOSE
+// ARE DISCLAIMED. IN NO EVENT SHALL THE COPYRIGHT HOLDER OR CONTRIBUTORS BE
+// LIABLE FOR ANY DIRECT, INDIRECT, INCIDENTAL, SPECIAL, EXEMPLARY, OR
+// CONSEQUENTIAL DAMAGES (INCLUDING, BUT NOT LIMITED TO, PROCUREMENT OF
+// SUBSTITUTE GOODS OR SERVICES; LOSS OF USE, DATA, OR PROFITS; OR BUSINESS
+// INTERRUPTION) HOWEVER CAUSED AND ON ANY THEORY OF LIABILITY, WHETHER IN
+// CONTRACT, STRICT LIABILITY, OR TORT (INCLUDING NEGLIGENCE OR OTHERWISE)
+// ARISING IN ANY WAY OUT OF THE USE OF THIS SOFTWARE, EVEN IF ADVISED OF THE
+// POSSIBILITY OF SUCH DAMAGE.
+
+package laws
+
+import (
+	"reflect"
+	"slices"
+	"testing"
+)
+
+func TestSelectorFilter(t *testing.T) {
+	law := func(group, typ, name string, tags ...string) *LawNode {
+		return &LawNode{Law: &fakeLaw{}, Group: group, Type: typ, Name: name, Options: &Options{Tags: tags}}
+	}
+	pkg := law("packages", "installed", "app")
+	conf := law("files", "templates", "/etc/app.conf")
+	svc := law("services", "running", "app", "app")
+	user := law("users", "present", "bob", "users")
+	group := law("groups", "present", "bob")
+	// the package, then its config, then the service
+	sorted := testGraph(t, []*LawNode{pkg, conf, svc, user, group},
+		[2]string{pkg.ID(), conf.ID()}, [2]string{conf.ID(), svc.ID()})
+
+	tests := []struct {
+		name     string
+		selector Selector
+		want     []*LawNode
+		err      string
+	}{
+		{name: "everything", want: []*LawNode{pkg, conf, svc, user, group}},
+		{name: "id", selector: Selector{Only: []string{svc.ID()}}, want: []*LawNode{pkg, conf, svc}},
+		{name: "id without deps", selector: Selector{Only: []string{svc.ID()}, NoDeps: true}, want: []*LawNode{svc}},
+		{name: "group", selector: Selector{Only: []string{"files"}}, want: []*LawNode{pkg, conf}},
+		{name: "group and type", selector: Selector{Only: []string{"files::templates"}, NoDeps: true}, want: []*LawNode{conf}},
+		{name: "several", selector: Selector{Only: []string{"users", "groups"}}, want: []*LawNode{user, group}},
+		{name: "tags", selector: Selector{Tags: []string{"app"}}, want: []*LawNode{pkg, conf, svc}},
+		{name: "tags without deps", selector: Selector{Tags: []string{"users", "nope"}, NoDeps: true}, want: []*LawNode{user}},
+		{name: "only or tags", selector: Selector{Only: []string{"groups"}, Tags: []string{"users"}}, want: []*LawNode{user, group}},
+		{name: "skip", selector: Selector{Skip: []string{"users"}}, want: []*LawNode{pkg, conf, svc, group}},
+		// skip wins over a dependency being pulled in
+		{name: "skip a dependency", selector: Selector{Only: []string{svc.ID()}, Skip: []string{"packages"}}, want: []*LawNode{conf, svc}},
+		{name: "unknown only", selector: Selector{Only: []string{"nope"}}, err: `"nope" doesn't match any laws`},
+		{name: "unknown skip", selector: Selector{Skip: []string{"nope::x"}}, err: `"nope::x" doesn't match any laws`},
+		{name: "partial group", selector: Selector{Only: []string{"file"}}, err: `"file" doesn't match any laws`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			filtered, err := tt.selector.Filter(sorted)
+			if tt.err != "" {
+				if err == nil || err.Error() != tt.err {
+					t.Fatalf("Filter() error = %v, want %q", err, tt.err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			// still in the order they were sorted in, root and all
+			if !isRoot(filtered[0].Label()) {
+				t.Errorf("Filter() dropped the root")
+			}
+			last := -1
+			var got, want []string
+			for _, v := range filtered[1:] {
+				i := slices.Index(sorted, v)
+				if i <= last {
+					t.Errorf("Filter() moved %s", v.Label().ID())
+				}
+				last = i
+				got = append(got, v.Label().ID())
+			}
+			for _, n := range tt.want {
+				want = append(want, n.ID())
+			}
+			slices.Sort(got)
+			slices.Sort(want)
+			if !reflect.DeepEqual(got, want) {
+				t.Errorf("Filter() = %v, want %v", got, want)
+			}
+		})
+	}
+}
@@ -33,6 +33,8 @@ package laws
 
 import (
 	"bytes"
+	"context"
+	"fmt"
 	"io"
 	"os/exec"
 
@@ -43,15 +45,11 @@ import (
 
 // Service - package info
 type Service struct {
-	// Name       string
-	State      string `yaml:",omitempty"`
-	Persistent bool   `yaml:",omitempty"`
-	RunLevel   string `yaml:",omitempty"`
-
-	// CommonFields
-	Name   string
-	Before []string
-	After  []string
+	CommonFields `yaml:"-"`
+
+	State      string `yaml:"state" enum:"started,stopped"`
+	Persistent bool   `yaml:"persistent"`
+	RunLevel   string `yaml:"runlevel"`
 }
 
 func (s *Service) UnmarshalYAML(value *yaml.Node) error {
@@ -98,11 +96,11 @@ func (s *Service) UnmarshalYAML(value *yaml.Node) error {
 // }
 
 // CurrentState - get current state of service
-func (s *Service) CurrentState() string {
+func (s *Service) CurrentState(ctx context.Context) string {
 	log.Debug().Str("distro family", facts.Facts.Distro.Family).Str("service", s.Name).Msg("checking service state")
 	switch facts.Facts.Distro.Family {
 	case "alpine":
-		cmd := exec.Command("rc-service", s.Name, "status")
+		cmd := exec.CommandContext(ctx, "rc-service", s.Name, "status")
 		var out bytes.Buffer
 		cmd.Stdout = &out
 		// FIXME return 3 just means it's stopped not that anything is wrong, but we should check other failure modes
@@ -128,62 +126,70 @@ func (s *Service) CurrentState() string {
 
 // FIXME changing the runlevel doesn't update the service
 // Ensure - ensure service is in desired state
-func (s *Service) Ensure(pretend bool) error {
+func (s *Service) Ensure(ctx context.Context, pretend bool) *Result {
 	log.Debug().Str("service name", s.Name).Msg("Service ensure")
-	cstate := s.CurrentState()
+	cstate := s.CurrentState(ctx)
 	if pretend {
 		if cstate != s.State {
 			log.Info().Str("service name", s.Name).Str("current state", cstate).Str("desired state", s.State).Msg("service not in desired state")
-		} else {
-			log.Info().Str("service name", s.Name).Str("current state", cstate).Str("desired state", s.State).Msg("service in desired state")
+			return Changed("service not in desired state", cstate, s.State)
 		}
-	} else {
-		if cstate != s.State {
-			if s.State == "started" {
-				log.Info().Str("name", s.Name).Str("current state", cstate).Str("desired state", s.State).Msg("starting service")
-				switch facts.Facts.Distro.Family {
-				case "alpine":
-					cmd := exec.Command("rc-service", s.Name, "start")
-					var out bytes.Buffer
-					cmd.Stdout = &out
-					err := cmd.Run()
-					if err != nil {
-						log.Fatal().Err(err).Msg("Failed to cmd.Run rc-service start")
-					}
-					log.Debug().Str("stdout", out.String())
-
-					if s.Persistent {
-						cmd := exec.Command("rc-update", "add", s.Name, s.RunLevel)
-						var out bytes.Buffer
-						cmd.Stdout = &out
-						err := cmd.Run()
-						if err != nil {
-							log.Fatal().Err(err).Str("service", s.Name).Msg("Failed to cmd.Run rc-update add")
-						}
-					}
-				case "debian":
-
-				}
+		log.Info().Str("service name", s.Name).Str("current state", cstate).Str("desired state", s.State).Msg("service in desired state")
+		return Unchanged("service in desired state")
+	}
+
+	if cstate != s.State {
+		action, doing := "start", "starting"
+		if s.State == "stopped" {
+			action, doing = "stop", "stopping"
+		}
+		log.Info().Str("name", s.Name).Str("current state", cstate).Str("desired state", s.State).Msg(doing + " service")
+		switch facts.Facts.Distro.Family {
+		case "alpine":
+			_, err := runCommand(ctx, "rc-service", s.Name, action)
+			if err != nil {
+				log.Error().Err(err).Msgf("Failed to cmd.Run rc-service %s", action)
+				return Failed(fmt.Sprintf("failed to %s service", action), err)
 			}
-		} else {
-			log.Debug().Msg("service in desired state")
+		case "debian":
+
 		}
-		if s.Persistent {
-			switch facts.Facts.Distro.Family {
-			case "alpine":
-				// this command is
-				cmd := exec.Command("rc-update", "add", s.Name, s.RunLevel)
-				var out bytes.Buffer
-				cmd.Stdout = &out
-				err := cmd.Run()
-				if err != nil {
-					log.Fatal().Err(err).Msg("Failed to cmd.Run rc-update add")
-				}
-				log.Debug().Str("stdout", out.String())
-			case "debian":
+	} else {
+		log.Debug().Msg("service in desired state")
+	}
+	if s.Persistent {
+		switch facts.Facts.Distro.Family {
+		case "alpine":
+			// this command is idempotent, so just always run it
+			_, err := runCommand(ctx, "rc-update", "add", s.Name, s.RunLevel)
+			if err != nil {
+				log.Error().Err(err).Str("service", s.Name).Msg("Failed to cmd.Run rc-update add")
+				return Failed("failed to add service to runlevel", err)
 			}
+		case "debian":
+		}
 
+	}
+	if cstate == s.State {
+		return Unchanged("service in desired state")
+	}
+	return Changed("service "+s.State, cstate, s.State)
+}
+
+// React - restart the service because something it watches changed
+func (s *Service) React(ctx context.Context, pretend bool) *Result {
+	if pretend {
+		log.Info().Str("service name", s.Name).Msg("service would be restarted")
+		return Changed("service would be restarted", s.State, "restarted")
+	}
+	switch facts.Facts.Distro.Family {
+	case "alpine":
+		log.Info().Str("service name", s.Name).Msg("restarting service")
+		_, err := runCommand(ctx, "rc-service", s.Name, "restart")
+		if err != nil {
+			return Failed("failed to restart service", err)
 		}
+		return Changed("service restarted", s.State, "restarted")
 	}
-	return nil
+	return Skipped(fmt.Sprintf("don't know how to restart services on %s", facts.Facts.Distro.Family))
 }
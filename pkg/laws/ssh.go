@@ -2,6 +2,7 @@ package laws
 
 import (
 	"bufio"
+	"context"
 	"errors"
 	"os"
 	"os/user"
@@ -12,29 +13,39 @@ import (
 	"github.com/rs/zerolog/log"
 )
 
+// SSHKey - a key that should be in a user's authorized_keys
 type SSHKey struct {
-	Name   string
-	Key    string
-	User   string
-	Before []string
-	After  []string
+	CommonFields `yaml:"-"`
+
+	Key  string `yaml:"key"`
+	User string `yaml:"user"`
 }
 
-func (k *SSHKey) Ensure(pretend bool) error {
+// Ensure - ensure the key is in the user's authorized_keys file
+func (k *SSHKey) Ensure(ctx context.Context, pretend bool) *Result {
 	fl := log.With().Str("key name", k.Name).Logger() // function logger adds some extra info
 
-	if pretend {
-		fl.Info().Str("user", k.User).Msg("adding authorized_key")
-		return nil
-	}
-
 	u, err := user.Lookup(k.User)
 	if err != nil {
 		fl.Error().Err(err).Msg("failed to lookup user")
-		return err
+		return Failed("failed to lookup user", err)
 	}
 	userSSHDir := path.Join(u.HomeDir, ".ssh")
 	authKeyPath := path.Join(userSSHDir, "authorized_keys")
+
+	keyFound, err := keyInFile(k.Key, authKeyPath)
+	if err != nil {
+		fl.Error().Err(err).Msg("failed to open authorized_keys file for reading")
+		return Failed("failed to read authorized_keys", err)
+	}
+	if keyFound {
+		return Unchanged("key already authorized")
+	}
+	if pretend {
+		fl.Info().Str("user", k.User).Msg("adding authorized_key")
+		return Changed("key would be authorized", "", k.Key)
+	}
+
 	_, err = os.Stat(userSSHDir)
 	if err != nil {
 		fl.Debug().Err(err).Msg("failed to stat ~/.ssh")
@@ -57,57 +68,57 @@ func (k *SSHKey) Ensure(pretend bool) error {
 				log.Error().Err(err).Msg("failed to chown ~/.ssh")
 			}
 		} else {
-			return err
+			return Failed("failed to stat ~/.ssh", err)
 		}
 	}
-	fr, err := os.Open(authKeyPath)
+
+	fw, err := os.OpenFile(authKeyPath, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0600)
 	if err != nil {
-		if !errors.Is(err, os.ErrNotExist) {
-			fl.Error().Err(err).Msg("failed to open authorized_keys file for reading")
-			return err
-		} else {
-			// TODO fix this logic to reduce duplication
-			fw, err := os.OpenFile(authKeyPath, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0600)
-			if err != nil {
-				fl.Error().Err(err).Msg("failed to open authorized_keys file for writing")
-				return err
-			}
-			_, err = fw.WriteString(k.Key + "\n")
-			if err != nil {
-				log.Error().Err(err).Msg("failed to write key to auth_keys")
-			}
-			defer fw.Close()
-			// TODO chown file if necessary
+		fl.Error().Err(err).Msg("failed to open authorized_keys file for writing")
+		return Failed("failed to open authorized_keys", err)
+	}
+	defer fw.Close()
+	_, err = fw.WriteString(k.Key + "\n")
+	if err != nil {
+		log.Error().Err(err).Msg("failed to write key to auth_keys")
+		return Failed("failed to write key to authorized_keys", err)
+	}
+	// TODO chown file if necessary
 
-			return nil
-		}
+	return Changed("key authorized", "", k.Key)
+}
+
+// ManagedFiles - the user's authorized_keys
+func (k *SSHKey) ManagedFiles() []string {
+	u, err := user.Lookup(k.User)
+	if err != nil {
+		return nil
 	}
-	scanner := bufio.NewScanner(fr)
-	// var newContent []string
-	keyFound := false
-	for scanner.Scan() {
-		line := scanner.Text()
-		fl.Info().Str("line", line).Msg("")
-		if strings.Contains(line, k.Key) {
-			keyFound = true
-			continue
+	return []string{path.Join(u.HomeDir, ".ssh", "authorized_keys")}
+}
+
+// LockKey - keys for the same user share an authorized_keys file
+func (k *SSHKey) LockKey() string {
+	return "authorized_keys:" + k.User
+}
+
+// keyInFile - check if an authorized_keys file has the key, a missing file
+// just doesn't have the key
+func keyInFile(key, authKeyPath string) (bool, error) {
+	fr, err := os.Open(authKeyPath)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return false, nil
 		}
+		return false, err
 	}
-	fr.Close()
+	defer fr.Close()
 
-	if !keyFound {
-		fw, err := os.OpenFile(authKeyPath, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0600)
-		if err != nil {
-			fl.Error().Err(err).Msg("failed to open authorized_keys file for writing")
-			return err
-		}
-		_, err = fw.WriteString(k.Key + "\n")
-		if err != nil {
-			log.Error().Err(err).Msg("failed to write key to auth_keys")
+	scanner := bufio.NewScanner(fr)
+	for scanner.Scan() {
+		if strings.Contains(scanner.Text(), key) {
+			return true, nil
 		}
-		defer fw.Close()
-		// TODO chown file if necessary
 	}
-
-	return nil
+	return false, scanner.Err()
 }
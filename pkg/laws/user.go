@@ -32,7 +32,7 @@ package laws
 
 import (
 	"bufio"
-	"bytes"
+	"context"
 	"fmt"
 	"os"
 	"os/exec"
@@ -47,85 +47,30 @@ import (
 
 // User - a user the system should have
 type User struct {
-	// Name           string   ``                       // the user's name
-	UID            uint64   `yaml:",omitempty"`      // the user's UID, uint64 matches
-	GID            uint64   `yaml:",omitempty"`      // The primary group ID
-	Fullname       string   ``                       // part of the GECOS string
-	Password       string   ``                       // the encrypted password
-	HomeDir        string   ``                       // the user's $HOME
-	Shell          string   ``                       // the system shell
-	System         bool     ``                       // whether this is a system user or not
-	Exists         bool     ``                       // Whether the user should exist on the system or not
-	ExtraGroups    []string ``                       // required extra group names
+	CommonFields `yaml:"-"` // Name is the user's name
+
+	UID            uint64   `yaml:"uid"`             // the user's UID, uint64 matches
+	GID            uint64   `yaml:"gid"`             // The primary group ID
+	Fullname       string   `yaml:"fullname"`        // part of the GECOS string
+	Password       string   `yaml:"password"`        // the encrypted password
+	HomeDir        string   `yaml:"homedir"`         // the user's $HOME
+	Shell          string   `yaml:"shell"`           // the system shell
+	System         bool     `yaml:"system"`          // whether this is a system user or not
+	Exists         bool     `yaml:"exists"`          // Whether the user should exist on the system or not
+	ExtraGroups    []string `yaml:"extra_groups"`    // required extra group names
 	OptionalGroups []string `yaml:"optional_groups"` // if these groups exist already, add the user to them, otherwise ignore
-	// CommonFields            //`yaml:",inline"` // CommonFields `yaml:"commonfields,inline"` // fields that are supported for everything, mostly dep related
-	Name   string
-	Before []string
-	After  []string
 
 	// TODO *Groups ^ should be array and should be expanded out below
 }
 
 // UnmarshalYAML - This fills in default values if they aren't specified
 func (u *User) UnmarshalYAML(value *yaml.Node) error {
-	var err error      // for use in the switch below
 	u.UID = ^uint64(0) // effectively -1, but go does math different than C
 	u.GID = ^uint64(0) // see https://blog.golang.org/constants
 	log.Trace().Interface("Node", value).Msg("UnmarshalYAML User")
-	if value.Tag != "!!map" {
-		return fmt.Errorf("unable to unmarshal yaml: value not map (%s)", value.Tag)
-	}
-	for i, node := range value.Content {
-		log.Trace().Interface("node1", node).Msg("user unmarshal")
-		switch node.Value {
-		case "name":
-			u.Name = value.Content[i+1].Value
-		case "uid":
-			u.UID, _ = strconv.ParseUint(value.Content[i+1].Value, 10, 64)
-		case "gid":
-			u.GID, _ = strconv.ParseUint(value.Content[i+1].Value, 10, 64)
-		case "fullname":
-			u.Fullname = value.Content[i+1].Value
-		case "password":
-			u.Password = value.Content[i+1].Value
-		case "homedir":
-			u.HomeDir = value.Content[i+1].Value
-		case "shell":
-			u.Shell = value.Content[i+1].Value
-		case "system":
-			u.System, err = strconv.ParseBool(value.Content[i+1].Value)
-			if err != nil {
-				log.Error().Err(err).Msg("can't parse system field")
-				return err
-			}
-		case "exists":
-			u.Exists, err = strconv.ParseBool(value.Content[i+1].Value)
-			if err != nil {
-				log.Error().Err(err).Msg("can't parse exists field")
-				return err
-			}
-		case "extra_groups":
-			for _, g := range value.Content[i+1].Content {
-				u.ExtraGroups = append(u.ExtraGroups, g.Value)
-			}
-		case "optional_groups":
-			for _, g := range value.Content[i+1].Content {
-				u.OptionalGroups = append(u.OptionalGroups, g.Value)
-			}
-		// common fields
-		case "after":
-			for _, j := range value.Content[i+1].Content {
-				u.After = append(u.After, j.Value)
-			}
-		case "before":
-			for _, j := range value.Content[i+1].Content {
-				u.Before = append(u.Before, j.Value)
-			}
-		}
-	}
-	log.Trace().Interface("user", u).Msg("what's in the box?!?!")
 
-	return nil
+	type rawUser User
+	return value.Decode((*rawUser)(u))
 }
 
 // GetPassword - Get the password for the user
@@ -155,21 +100,21 @@ func (u *User) GetPassword() (string, error) {
 }
 
 // Create - create the user
-func (u *User) Create() {
+func (u *User) Create(ctx context.Context) error {
 	var args []string
 	var cmd *exec.Cmd
-	var stdOut bytes.Buffer
-	var stdErr bytes.Buffer
 
 	log.Debug().Msg("Creating user")
 
-	if facts.Facts.Distro.Family == "alpine" {
+	switch facts.Facts.Distro.Family {
+	case "alpine":
 		log.Debug().Msg("on Alpine, using adduser")
 		// TODO handle optionalgroups, extragroups, system, password
 		// adduser expects the group to be a name not a gid
 		group, err := user.LookupGroupId(fmt.Sprintf("%d", u.GID))
 		if err != nil {
 			log.Error().Err(err).Uint64("gid", u.GID).Msg("failed to lookup group name from GID")
+			return err
 		}
 		args = append(args,
 			"-u", fmt.Sprintf("%d", u.UID),
@@ -180,89 +125,120 @@ func (u *User) Create() {
 			"-h", u.HomeDir,
 		)
 		args = append(args, u.Name)
-		cmd = exec.Command("adduser", args...)
-	} else if facts.Facts.Distro.Family == "debian" {
+		cmd = exec.CommandContext(ctx, "adduser", args...)
+	case "debian":
 		log.Debug().Msg("on a debian based system, using useradd")
-		args := []string{
+		args = []string{
 			"-u", fmt.Sprintf("%d", u.UID),
 			"-s", u.Shell,
 			"-g", fmt.Sprintf("%d", u.GID),
 			"-c", u.Fullname,
 			"-d", u.HomeDir,
-			"-p", u.Password,
 		}
 		args = append(args, u.Name)
-		cmd = exec.Command("useradd", args...)
+		cmd = exec.CommandContext(ctx, "useradd", args...)
+	default:
+		return fmt.Errorf("don't know how to create users on distro: %s", facts.Facts.Distro.Family)
 	}
 	log.Debug().Strs("args", args).Msg("calling add user command with args")
-	cmd.Stdout = &stdOut
-	cmd.Stdout = &stdErr
-	err := cmd.Run()
+	_, _, err := runCmd(ctx, cmd)
 	if err != nil {
-		log.Fatal().Err(err).Str("stdout", stdOut.String()).Str("stderr", stdErr.String()).Msg("failed to create user")
+		log.Error().Err(err).Msg("failed to create user")
+		return err
+	}
+	if u.Password != "" && facts.Facts.Distro.Family == "debian" {
+		return u.setPassword(ctx)
 	}
-	log.Debug().Str("stdout", stdOut.String()).Str("stderr", stdErr.String()).Msg("add user command output")
+	return nil
+}
 
+// setPassword - set the user's password hash. It goes to chpasswd on stdin,
+// so it isn't in the command line (or the command log and journal).
+func (u *User) setPassword(ctx context.Context) error {
+	cmd := exec.CommandContext(ctx, "chpasswd", "-e")
+	cmd.Stdin = strings.NewReader(u.Name + ":" + u.Password + "\n")
+	_, _, err := runCmd(ctx, cmd)
+	if err != nil {
+		log.Error().Err(err).Str("user", u.Name).Msg("failed to set user password")
+		return err
+	}
+	return nil
 }
 
 // Ensure - ensure the user exists, if not create it
-func (u *User) Ensure(pretend bool) error {
-	log.Trace().Interface("user", u).Msgf("ensuring user: %s (%d:%d)", u.Name, u.UID, u.GID)
+func (u *User) Ensure(ctx context.Context, pretend bool) *Result {
+	log.Trace().Msgf("ensuring user: %s (%d:%d)", u.Name, u.UID, u.GID)
 	eu, err := user.Lookup(u.Name)
 	switch err.(type) {
 	case user.UnknownUserError:
 		if pretend {
 			log.Info().Msg("user doesn't exist, creating")
-		} else {
-			log.Trace().Msg("user doesn't exist, creating")
-			u.Create()
+			return Changed("user would be created", "absent", "present")
 		}
-	default: // Probably nil (user exists)
-		if pretend {
-			log.Info().Msg("user exists, updating if necessary")
+		log.Trace().Msg("user doesn't exist, creating")
+		err = u.Create(ctx)
+		if err != nil {
+			return Failed("failed to create user", err)
 		}
+		return Changed("user created", "absent", "present")
+	default: // Probably nil (user exists)
+		// the user exists, existing users aren't changed yet so anything
+		// that doesn't match fails the law
+		var differs []string
 		log.Debug().Interface("eu", eu).Err(err).Msg("user exists, making sure it matches")
-		if eu.Uid != strconv.FormatUint(u.UID, 10) {
-			log.Info().Str("existing UID", eu.Uid).Uint64("wanted UID", u.UID).Msg("UID doesn't match, changing")
+		if u.UID != ^uint64(0) && eu.Uid != strconv.FormatUint(u.UID, 10) {
+			log.Info().Str("existing UID", eu.Uid).Uint64("wanted UID", u.UID).Msg("UID doesn't match")
+			differs = append(differs, "uid")
 		}
-		if eu.Gid != strconv.FormatUint(u.GID, 10) {
-			log.Info().Str("existing GID", eu.Gid).Uint64("wanted GID", u.GID).Msg("GID doesn't match, changing")
+		if u.GID != ^uint64(0) && eu.Gid != strconv.FormatUint(u.GID, 10) {
+			log.Info().Str("existing GID", eu.Gid).Uint64("wanted GID", u.GID).Msg("GID doesn't match")
+			differs = append(differs, "gid")
 		}
 		if u.HomeDir != "" && strings.TrimSpace(eu.HomeDir) != strings.TrimSpace(u.HomeDir) {
-			log.Info().Str("existing homedir", eu.HomeDir).Str("wanted homedir", u.HomeDir).Msg("homedir doesn't match, changing")
+			log.Info().Str("existing homedir", eu.HomeDir).Str("wanted homedir", u.HomeDir).Msg("homedir doesn't match")
+			differs = append(differs, "homedir")
 		}
 		if u.Fullname != "" && eu.Name != u.Fullname {
-			log.Info().Str("existing fullname", eu.Name).Str("wanted fullname", u.Fullname).Msg("fullname doesn't match, changing")
+			log.Info().Str("existing fullname", eu.Name).Str("wanted fullname", u.Fullname).Msg("fullname doesn't match")
+			differs = append(differs, "fullname")
 		}
 		pwd, perr := u.GetPassword()
 		if perr != nil {
-			return perr
+			return Failed("failed to get user password", perr)
 		}
 		if u.Password != "" && pwd != u.Password {
-			log.Info().Str("u.Password", u.Password).Str("user", u.Name).Msg("password doesn't match, changing")
+			log.Info().Str("user", u.Name).Msg("password doesn't match")
 			// TODO
+			differs = append(differs, "password")
 		}
 		// system field is only interesting when creating new users
 		// if eu.System != u.System {
 		// log.Debug().Str("eu.System", eu.System).Str("u.System", u.System).Msg("System doesn't match, changing")
 		// }
+		if len(differs) > 0 {
+			// TODO actually modify existing users
+			msg := fmt.Sprintf("user exists but its %s don't match, updating users isn't supported", strings.Join(differs, ", "))
+			return Failed(msg, fmt.Errorf("user %s: %s", u.Name, msg))
+		}
 	}
-	return nil
+	return Unchanged("user exists")
+}
+
+// LockKey - user and group changes all edit /etc/passwd and friends
+func (u *User) LockKey() string {
+	return "/etc/passwd"
 }
 
 // Group - a group the system should have
 type Group struct {
-	// Name   string
-	GID    uint64
-	System bool
-	// CommonFields
-	Name   string
-	Before []string
-	After  []string
+	CommonFields `yaml:"-"`
+
+	GID    uint64 `yaml:"gid"`
+	System bool   `yaml:"system"`
 }
 
 // Ensure - check if the group exists
-func (g *Group) Ensure(pretend bool) error {
+func (g *Group) Ensure(ctx context.Context, pretend bool) *Result {
 	log.Trace().Msgf("Group.Ensure(): %s", g.Name)
 	grp, err := user.LookupGroup(g.Name)
 	// log.Debug().Interface("grp", grp).Interface("g", g).Str("g.gid", fmt.Sprintf("%d", g.GID)).Str("grp.gid", grp.Gid).Msg("grp lookup")
@@ -271,35 +247,44 @@ func (g *Group) Ensure(pretend bool) error {
 		// group doesn't exist, create it
 		if pretend {
 			log.Info().Msgf("group will be created: %s", g.Name)
-		} else {
-			err = g.Create()
-			if err != nil {
-				log.Error().Err(err).Msg("failed to create group")
-				return err
-			}
+			return Changed("group would be created", "absent", "present")
 		}
+		err = g.Create(ctx)
+		if err != nil {
+			log.Error().Err(err).Msg("failed to create group")
+			return Failed("failed to create group", err)
+		}
+		return Changed("group created", "absent", "present")
 	case nil:
 		// group exists, check it
-		if grp.Gid == fmt.Sprintf("%d", g.GID) {
+		// no gid in the law decodes as 0, which only root's group has
+		if g.GID == 0 || grp.Gid == fmt.Sprintf("%d", g.GID) {
 			if pretend {
 				log.Info().Msgf("group exists: %s", g.Name)
 			}
 		} else {
 			log.Debug().Msg("group exists, but GID doesn't match")
+			log.Trace().Msgf("group group: %#v - %#v", g, grp)
+			// TODO actually modify existing groups
+			msg := fmt.Sprintf("group exists but its GID is %s, not %d, updating groups isn't supported", grp.Gid, g.GID)
+			return Failed(msg, fmt.Errorf("group %s: %s", g.Name, msg))
 		}
 	default:
 		// some other kind of error
 		log.Error().Err(err).Msg("failed to lookup group")
-		return err
+		return Failed("failed to lookup group", err)
 	}
 	log.Trace().Msgf("group group: %#v - %#v", g, grp)
-	return nil
+	return Unchanged("group exists")
+}
+
+// LockKey - user and group changes all edit /etc/passwd and friends
+func (g *Group) LockKey() string {
+	return "/etc/passwd"
 }
 
 // Create - create a group
-func (g *Group) Create() error {
-	var stdOut bytes.Buffer
-	var stdErr bytes.Buffer
+func (g *Group) Create(ctx context.Context) error {
 	var args []string
 	var cmd *exec.Cmd
 
@@ -312,28 +297,25 @@ func (g *Group) Create() error {
 		}
 		args = append(args, g.Name)
 		log.Trace().Interface("args", args).Msg("group create() args")
-		cmd = exec.Command("addgroup", args...)
+		cmd = exec.CommandContext(ctx, "addgroup", args...)
 	case "debian":
-		args := []string{"-g", fmt.Sprintf("%d", g.GID)}
+		args = []string{"-g", fmt.Sprintf("%d", g.GID)}
 		if g.System {
 			args = append(args, "-r")
 		}
 		args = append(args, g.Name)
 		log.Trace().Interface("args", args).Msg("group create() args")
-		cmd = exec.Command("groupadd", args...)
+		cmd = exec.CommandContext(ctx, "groupadd", args...)
+	default:
+		return fmt.Errorf("don't know how to create groups on distro: %s", facts.Facts.Distro.Family)
 	}
 	log.Debug().Strs("args", args).Msg("calling add group command with args")
 
-	cmd.Stdout = &stdOut
-	cmd.Stderr = &stdErr
-	err := cmd.Run()
+	_, _, err := runCmd(ctx, cmd)
 	if err != nil {
-		log.Fatal().Err(err).Msg("failed to create group")
+		log.Error().Err(err).Msg("failed to create group")
+		return err
 	}
-	log.Debug().
-		Str("stdout", stdOut.String()).
-		Str("stderr", stdErr.String()).
-		Msg("add group command output")
 
 	return nil
 }
@@ -0,0 +1,222 @@
+// Copyright © 2026 Iggy <iggy@theiggy.com>
+// All rights reserved.
+//
+// Redistribution and use in source and binary forms, with or without
+// modification, are permitted provided that the following conditions are met:
+//
+// 1. Redistributions of source code must retain the above copyright notice,
+//    this list of conditions and the following disclaimer.
+//
+// 2. Redistributions in binary form must reproduce the above copyright notice,
+//    this list of conditions and the following disclaimer in the documentation
+//    and/or other materials provided with the distribution.
+//
+// 3. Neither the name of the copyright holder nor the names of its contributors
+//    may be used to endorse or promote products derived from this software
+//    without specific prior written permission.
+//
+// THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS "AS IS"
+// AND ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT LIMITED TO, THE
+// IMPLIED WARRANTIES OF MERCHANTABILITY AND FITNESS FOR A PARTICULAR PURPOSE
+// ARE DISCLAIMED. IN NO EVENT SHALL THE COPYRIGHT HOLDER OR CONTRIBUTORS BE
+// LIABLE FOR ANY DIRECT, INDIRECT, INCIDENTAL, SPECIAL, EXEMPLARY, OR
+// CONSEQUENTIAL DAMAGES (INCLUDING, BUT NOT LIMITED TO, PROCUREMENT OF
+// SUBSTITUTE GOODS OR SERVICES; LOSS OF USE, DATA, OR PROFITS; OR BUSINESS
+// INTERRUPTION) HOWEVER CAUSED AND ON ANY THEORY OF LIABILITY, WHETHER IN
+// CONTRACT, STRICT LIABILITY, OR TORT (INCLUDING NEGLIGENCE OR OTHERWISE)
+// ARISING IN ANY WAY OUT OF THE USE OF THIS SOFTWARE, EVEN IF ADVISED OF THE
+// POSSIBILITY OF SUCH DAMAGE.
+
+package laws
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/iggy/govern/pkg/facts"
+	"gopkg.in/yaml.v3"
+)
+
+// VarsDir - the directory of data vars next to the laws, it isn't read as laws
+const VarsDir = "vars"
+
+// VarsLayer - one layer of vars, later layers override earlier ones
+type VarsLayer struct {
+	Name string // defaults, family, distro, host or --var
+	File string // the file the layer was read from, empty for --var
+}
+
+func (l VarsLayer) String() string {
+	if l.File == "" {
+		return l.Name
+	}
+	return fmt.Sprintf("%s (%s)", l.Name, l.File)
+}
+
+// Vars - the merged vars templates get as .vars, and where each value came from
+type Vars struct {
+	Values map[string]interface{}
+	From   map[string]VarsLayer // by dotted key, i.e. nginx.port
+}
+
+// varOverrides - the --var k=v overrides, the last layer
+var varOverrides = map[string]interface{}{}
+
+// SetVarOverrides - set the --var overrides, k is a dotted path into the
+// vars and v is parsed as yaml, so numbers and bools keep their type
+func SetVarOverrides(kvs []string) error {
+	overrides := map[string]interface{}{}
+	for _, kv := range kvs {
+		k, v, ok := strings.Cut(kv, "=")
+		if !ok || k == "" {
+			return fmt.Errorf("--var %q should be key=value", kv)
+		}
+		var value interface{}
+		err := yaml.Unmarshal([]byte(v), &value)
+		if err != nil {
+			value = v
+		}
+		setVar(overrides, strings.Split(k, "."), value)
+	}
+	varOverrides = overrides
+	return nil
+}
+
+// setVar - set a value at a path of keys, making maps along the way
+func setVar(m map[string]interface{}, path []string, value interface{}) {
+	for _, k := range path[:len(path)-1] {
+		next, ok := m[k].(map[string]interface{})
+		if !ok {
+			next = map[string]interface{}{}
+			m[k] = next
+		}
+		m = next
+	}
+	m[path[len(path)-1]] = value
+}
+
+// varsDir - the vars directory for a file or directory of laws
+func varsDir(path string) string {
+	if path == Stdin {
+		return VarsDir
+	}
+	if fi, err := os.Stat(path); err == nil && fi.IsDir() {
+		return filepath.Join(path, VarsDir)
+	}
+	return filepath.Join(filepath.Dir(path), VarsDir)
+}
+
+// varsLayers - the layers in the order they're merged
+//
+//	vars/defaults.yaml
+//	vars/family/<Distro.Family>.yaml
+//	vars/distro/<Distro.Slug>.yaml
+//	vars/hosts/<hostname>.yaml
+//
+// missing files, and layers for facts that weren't found, are skipped
+func varsLayers(dir string) []VarsLayer {
+	layers := []struct {
+		VarsLayer
+		fact string
+	}{
+		{VarsLayer{Name: "defaults", File: filepath.Join(dir, "defaults")}, "defaults"},
+		{VarsLayer{Name: "family", File: filepath.Join(dir, "family", facts.Facts.Distro.Family)}, facts.Facts.Distro.Family},
+		{VarsLayer{Name: "distro", File: filepath.Join(dir, "distro", facts.Facts.Distro.Slug)}, facts.Facts.Distro.Slug},
+		{VarsLayer{Name: "host", File: filepath.Join(dir, "hosts", facts.Facts.Hostname)}, facts.Facts.Hostname},
+	}
+	var found []VarsLayer
+	for _, l := range layers {
+		if l.fact == "" {
+			continue // i.e. the distro wasn't detected
+		}
+		for _, ext := range []string{".yaml", ".yml"} {
+			if _, err := os.Stat(l.File + ext); err == nil {
+				found = append(found, VarsLayer{Name: l.Name, File: l.File + ext})
+				break
+			}
+		}
+	}
+	return found
+}
+
+// LoadVars - merge the vars for a file or directory of laws
+func LoadVars(path string) (*Vars, error) {
+	vars := &Vars{Values: map[string]interface{}{}, From: map[string]VarsLayer{}}
+	for _, layer := range varsLayers(varsDir(path)) {
+		raw, err := os.ReadFile(layer.File)
+		if err != nil {
+			return nil, err
+		}
+		values := map[string]interface{}{}
+		err = yaml.Unmarshal(raw, &values)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", layer.File, err)
+		}
+		vars.merge(values, layer)
+	}
+	vars.merge(varOverrides, VarsLayer{Name: "--var"})
+	return vars, nil
+}
+
+// merge - merge a layer over the vars, maps are merged key by key and
+// anything else (including lists) is replaced
+func (v *Vars) merge(values map[string]interface{}, layer VarsLayer) {
+	var merge func(dst, src map[string]interface{}, prefix string)
+	merge = func(dst, src map[string]interface{}, prefix string) {
+		for k, value := range src {
+			key := prefix + k
+			srcMap, srcIsMap := value.(map[string]interface{})
+			dstMap, dstIsMap := dst[k].(map[string]interface{})
+			if srcIsMap && dstIsMap {
+				merge(dstMap, srcMap, key+".")
+				continue
+			}
+			v.forget(key)
+			if srcIsMap {
+				dstMap = map[string]interface{}{}
+				dst[k] = dstMap
+				merge(dstMap, srcMap, key+".")
+				continue
+			}
+			dst[k] = value
+			v.From[key] = layer
+		}
+	}
+	merge(v.Values, values, "")
+}
+
+// forget - drop where a key, and anything under it, came from
+func (v *Vars) forget(key string) {
+	delete(v.From, key)
+	for k := range v.From {
+		if strings.HasPrefix(k, key+".") {
+			delete(v.From, k)
+		}
+	}
+}
+
+// Keys - the dotted keys of every value, sorted
+func (v *Vars) Keys() []string {
+	keys := make([]string, 0, len(v.From))
+	for k := range v.From {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// Get - the value for a dotted key
+func (v *Vars) Get(key string) interface{} {
+	var value interface{} = v.Values
+	for _, k := range strings.Split(key, ".") {
+		m, ok := value.(map[string]interface{})
+		if !ok {
+			return nil
+		}
+		value = m[k]
+	}
+	return value
+}
@@ -0,0 +1,513 @@
+// Copyright © 2026 Iggy <iggy@theiggy.com>
+// All rights reserved.
+//
+// Redistribution and use in source and binary forms, with or without
+// modification, are permitted provided that the following conditions are met:
+//
+// 1. Redistributions of source code must retain the above copyright notice,
+//    this list of conditions and the following disclaimer.
+//
+// 2. Redistributions in binary form must reproduce the above copyright notice,
+//    this list of conditions and the following disclaimer in the documentation
+//    and/or other materials provided with the distribution.
+//
+// 3. Neither the name of the copyright holder nor the names of its contributors
+//    may be used to endorse or promote products derived from this software
+//    without specific prior written permission.
+//
+// THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS "AS IS"
+// AND ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT LIMITED TO, THE
+// IMPLIED WARRANTIES OF MERCHANTABILITY AND FITNESS FOR A PARTICULAR PURPOSE
+// ARE DISCLAIMED. IN NO EVENT SHALL THE COPYRIGHT HOLDER OR CONTRIBUTORS BE
+// LIABLE FOR ANY DIRECT, INDIRECT, INCIDENTAL, SPECIAL, EXEMPLARY, OR
+// CONSEQUENTIAL DAMAGES (INCLUDING, BUT NOT LIMITED TO, PROCUREMENT OF
+// SUBSTITUTE GOODS OR SERVICES; LOSS OF USE, DATA, OR PROFITS; OR BUSINESS
+// INTERRUPTION) HOWEVER CAUSED AND ON ANY THEORY OF LIABILITY, WHETHER IN
+// CONTRACT, STRICT LIABILITY, OR TORT (INCLUDING NEGLIGENCE OR OTHERWISE)
+// ARISING IN ANY WAY OUT OF THE USE OF THIS SOFTWARE, EVEN IF ADVISED OF THE
+// POSSIBILITY OF SUCH DAMAGE.
+
+package laws
+
+import (
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"github.com/iggy/govern/pkg/facts"
+	"gopkg.in/yaml.v3"
+)
+
+// When - a condition on the facts that has to be true for a law to run
+// i.e. `Distro.Family == "debian" && MemoryTotal > 8G`
+//
+// Supports ==, !=, <, <=, >, >=, &&, ||, ! and parens. Fields are looked
+// up in facts.Facts, numbers can have a K/M/G/T (1024 based) suffix and
+// strings can use double or single quotes.
+type When struct {
+	src  string
+	root whenNode
+}
+
+// ParseWhen - compile a when expression, unknown facts are an error here
+// rather than when the law runs
+func ParseWhen(src string) (*When, error) {
+	p := &whenParser{src: src}
+	err := p.tokenize()
+	if err != nil {
+		return nil, err
+	}
+	root, err := p.parseOr()
+	if err != nil {
+		return nil, err
+	}
+	if p.pos < len(p.tokens) {
+		return nil, fmt.Errorf("unexpected %q at %d", p.tokens[p.pos].text, p.tokens[p.pos].offset)
+	}
+	if root.typ() != whenBool {
+		return nil, fmt.Errorf("%q is a %s, not true or false", src, root.typ())
+	}
+	return &When{src: src, root: root}, nil
+}
+
+// String - the expression as written
+func (w *When) String() string {
+	return w.src
+}
+
+// Eval - check the expression against the current facts
+func (w *When) Eval() (bool, error) {
+	v, err := w.root.eval()
+	if err != nil {
+		return false, err
+	}
+	b, ok := v.(bool)
+	if !ok {
+		return false, fmt.Errorf("%q is not true or false", w.src)
+	}
+	return b, nil
+}
+
+// UnmarshalYAML - compile the expression while loading the laws
+func (w *When) UnmarshalYAML(value *yaml.Node) error {
+	parsed, err := ParseWhen(value.Value)
+	if err != nil {
+		return fmt.Errorf("line %d: when: %w", value.Line, err)
+	}
+	*w = *parsed
+	return nil
+}
+
+type whenNode interface {
+	eval() (interface{}, error)
+	typ() string // string, number or bool, checked while parsing
+}
+
+// value types in when expressions
+const (
+	whenString = "string"
+	whenNumber = "number"
+	whenBool   = "bool"
+)
+
+// whenLiteral - a string, number (float64) or bool
+type whenLiteral struct {
+	v interface{}
+}
+
+func (n whenLiteral) eval() (interface{}, error) {
+	return n.v, nil
+}
+
+func (n whenLiteral) typ() string {
+	switch n.v.(type) {
+	case float64:
+		return whenNumber
+	case bool:
+		return whenBool
+	}
+	return whenString
+}
+
+// whenField - a field in facts.Facts, i.e. Distro.Family
+type whenField struct {
+	path []string
+	t    string
+}
+
+func (n whenField) typ() string {
+	return n.t
+}
+
+func (n whenField) eval() (interface{}, error) {
+	v := reflect.ValueOf(facts.Facts)
+	for _, name := range n.path {
+		v = v.FieldByName(name)
+	}
+	switch v.Kind() {
+	case reflect.String:
+		return v.String(), nil
+	case reflect.Bool:
+		return v.Bool(), nil
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return float64(v.Int()), nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return float64(v.Uint()), nil
+	case reflect.Float32, reflect.Float64:
+		return v.Float(), nil
+	}
+	return nil, fmt.Errorf("fact %s can't be compared", strings.Join(n.path, "."))
+}
+
+// checkFact - make sure a fact exists and is something we can compare,
+// returns its type so comparisons with the wrong type are caught early
+func checkFact(path []string) (string, error) {
+	t := reflect.TypeOf(facts.Facts)
+	for i, name := range path {
+		if t.Kind() != reflect.Struct {
+			return "", fmt.Errorf("fact %s has no field %s", strings.Join(path[:i], "."), name)
+		}
+		f, ok := t.FieldByName(name)
+		if !ok || !f.IsExported() {
+			return "", fmt.Errorf("unknown fact %s", strings.Join(path[:i+1], "."))
+		}
+		t = f.Type
+	}
+	switch t.Kind() {
+	case reflect.String:
+		return whenString, nil
+	case reflect.Bool:
+		return whenBool, nil
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
+		reflect.Float32, reflect.Float64:
+		return whenNumber, nil
+	}
+	return "", fmt.Errorf("fact %s can't be compared", strings.Join(path, "."))
+}
+
+// checkOperands - the two sides of an operator have types it works on
+func checkOperands(op string, x, y whenNode) error {
+	switch op {
+	case "&&", "||":
+		if x.typ() != whenBool || y.typ() != whenBool {
+			return fmt.Errorf("%s needs true or false on both sides, got %s and %s", op, x.typ(), y.typ())
+		}
+		return nil
+	}
+	if x.typ() != y.typ() {
+		return fmt.Errorf("can't compare a %s with a %s using %s", x.typ(), y.typ(), op)
+	}
+	if x.typ() == whenBool && op != "==" && op != "!=" {
+		return fmt.Errorf("can't use %s on true or false", op)
+	}
+	return nil
+}
+
+// whenNot - !x
+type whenNot struct {
+	x whenNode
+}
+
+func (n whenNot) typ() string {
+	return whenBool
+}
+
+func (n whenNot) eval() (interface{}, error) {
+	v, err := n.x.eval()
+	if err != nil {
+		return nil, err
+	}
+	b, ok := v.(bool)
+	if !ok {
+		return nil, fmt.Errorf("can't use ! on %v", v)
+	}
+	return !b, nil
+}
+
+// whenBinary - x op y
+type whenBinary struct {
+	op   string
+	x, y whenNode
+}
+
+func (n whenBinary) typ() string {
+	return whenBool
+}
+
+func (n whenBinary) eval() (interface{}, error) {
+	x, err := n.x.eval()
+	if err != nil {
+		return nil, err
+	}
+
+	// short circuit the logic ops
+	if n.op == "&&" || n.op == "||" {
+		xb, ok := x.(bool)
+		if !ok {
+			return nil, fmt.Errorf("can't use %s on %v", n.op, x)
+		}
+		if (n.op == "&&" && !xb) || (n.op == "||" && xb) {
+			return xb, nil
+		}
+		y, err := n.y.eval()
+		if err != nil {
+			return nil, err
+		}
+		yb, ok := y.(bool)
+		if !ok {
+			return nil, fmt.Errorf("can't use %s on %v", n.op, y)
+		}
+		return yb, nil
+	}
+
+	y, err := n.y.eval()
+	if err != nil {
+		return nil, err
+	}
+	switch n.op {
+	case "==":
+		return x == y, nil
+	case "!=":
+		return x != y, nil
+	}
+
+	var cmp int
+	switch xv := x.(type) {
+	case float64:
+		yv, ok := y.(float64)
+		if !ok {
+			return nil, fmt.Errorf("can't compare %v and %v", x, y)
+		}
+		switch {
+		case xv < yv:
+			cmp = -1
+		case xv > yv:
+			cmp = 1
+		}
+	case string:
+		yv, ok := y.(string)
+		if !ok {
+			return nil, fmt.Errorf("can't compare %v and %v", x, y)
+		}
+		cmp = strings.Compare(xv, yv)
+	default:
+		return nil, fmt.Errorf("can't use %s on %v", n.op, x)
+	}
+	switch n.op {
+	case "<":
+		return cmp < 0, nil
+	case "<=":
+		return cmp <= 0, nil
+	case ">":
+		return cmp > 0, nil
+	case ">=":
+		return cmp >= 0, nil
+	}
+	return nil, fmt.Errorf("unknown operator %s", n.op)
+}
+
+type whenToken struct {
+	kind   string // op, string, number, ident
+	text   string
+	offset int
+}
+
+type whenParser struct {
+	src    string
+	tokens []whenToken
+	pos    int
+}
+
+// sizeSuffixes - multipliers for numbers like 8G
+var sizeSuffixes = map[string]float64{
+	"":  1,
+	"K": 1 << 10,
+	"M": 1 << 20,
+	"G": 1 << 30,
+	"T": 1 << 40,
+}
+
+func (p *whenParser) tokenize() error {
+	src := p.src
+	for i := 0; i < len(src); {
+		c := rune(src[i])
+		switch {
+		case unicode.IsSpace(c):
+			i++
+		case strings.HasPrefix(src[i:], "&&"), strings.HasPrefix(src[i:], "||"),
+			strings.HasPrefix(src[i:], "=="), strings.HasPrefix(src[i:], "!="),
+			strings.HasPrefix(src[i:], "<="), strings.HasPrefix(src[i:], ">="):
+			p.tokens = append(p.tokens, whenToken{"op", src[i : i+2], i})
+			i += 2
+		case strings.ContainsRune("<>!()", c):
+			p.tokens = append(p.tokens, whenToken{"op", src[i : i+1], i})
+			i++
+		case c == '"' || c == '\'':
+			end := strings.IndexByte(src[i+1:], src[i])
+			if end < 0 {
+				return fmt.Errorf("unterminated string at %d", i)
+			}
+			p.tokens = append(p.tokens, whenToken{"string", src[i+1 : i+1+end], i})
+			i += end + 2
+		case unicode.IsDigit(c):
+			start := i
+			for i < len(src) && (unicode.IsDigit(rune(src[i])) || src[i] == '.') {
+				i++
+			}
+			num := src[start:i]
+			for i < len(src) && unicode.IsLetter(rune(src[i])) {
+				i++
+			}
+			p.tokens = append(p.tokens, whenToken{"number", num + "|" + src[start+len(num):i], start})
+		case unicode.IsLetter(c) || c == '_':
+			start := i
+			for i < len(src) && (unicode.IsLetter(rune(src[i])) || unicode.IsDigit(rune(src[i])) || src[i] == '_' || src[i] == '.') {
+				i++
+			}
+			p.tokens = append(p.tokens, whenToken{"ident", src[start:i], start})
+		default:
+			return fmt.Errorf("unexpected %q at %d", c, i)
+		}
+	}
+	return nil
+}
+
+func (p *whenParser) peek() *whenToken {
+	if p.pos < len(p.tokens) {
+		return &p.tokens[p.pos]
+	}
+	return nil
+}
+
+// accept - move past the next token if it's one of the ops
+func (p *whenParser) accept(ops ...string) string {
+	t := p.peek()
+	if t == nil || t.kind != "op" {
+		return ""
+	}
+	for _, op := range ops {
+		if t.text == op {
+			p.pos++
+			return op
+		}
+	}
+	return ""
+}
+
+func (p *whenParser) parseOr() (whenNode, error) {
+	x, err := p.parseAnd()
+	if err != nil {
+		return nil, err
+	}
+	for p.accept("||") != "" {
+		y, err := p.parseAnd()
+		if err != nil {
+			return nil, err
+		}
+		if err := checkOperands("||", x, y); err != nil {
+			return nil, err
+		}
+		x = whenBinary{"||", x, y}
+	}
+	return x, nil
+}
+
+func (p *whenParser) parseAnd() (whenNode, error) {
+	x, err := p.parseNot()
+	if err != nil {
+		return nil, err
+	}
+	for p.accept("&&") != "" {
+		y, err := p.parseNot()
+		if err != nil {
+			return nil, err
+		}
+		if err := checkOperands("&&", x, y); err != nil {
+			return nil, err
+		}
+		x = whenBinary{"&&", x, y}
+	}
+	return x, nil
+}
+
+func (p *whenParser) parseNot() (whenNode, error) {
+	if p.accept("!") != "" {
+		x, err := p.parseNot()
+		if err != nil {
+			return nil, err
+		}
+		if x.typ() != whenBool {
+			return nil, fmt.Errorf("can't use ! on a %s", x.typ())
+		}
+		return whenNot{x}, nil
+	}
+	return p.parseCompare()
+}
+
+func (p *whenParser) parseCompare() (whenNode, error) {
+	x, err := p.parsePrimary()
+	if err != nil {
+		return nil, err
+	}
+	if op := p.accept("==", "!=", "<", "<=", ">", ">="); op != "" {
+		y, err := p.parsePrimary()
+		if err != nil {
+			return nil, err
+		}
+		if err := checkOperands(op, x, y); err != nil {
+			return nil, err
+		}
+		return whenBinary{op, x, y}, nil
+	}
+	return x, nil
+}
+
+func (p *whenParser) parsePrimary() (whenNode, error) {
+	t := p.peek()
+	if t == nil {
+		return nil, fmt.Errorf("unexpected end of expression")
+	}
+	p.pos++
+	switch t.kind {
+	case "string":
+		return whenLiteral{t.text}, nil
+	case "number":
+		num, suffix, _ := strings.Cut(t.text, "|")
+		mult, ok := sizeSuffixes[strings.TrimSuffix(strings.TrimSuffix(strings.ToUpper(suffix), "B"), "I")]
+		if !ok {
+			return nil, fmt.Errorf("unknown size suffix %q at %d", suffix, t.offset)
+		}
+		f, err := strconv.ParseFloat(num, 64)
+		if err != nil {
+			return nil, fmt.Errorf("bad number %q at %d", num, t.offset)
+		}
+		return whenLiteral{f * mult}, nil
+	case "ident":
+		switch t.text {
+		case "true":
+			return whenLiteral{true}, nil
+		case "false":
+			return whenLiteral{false}, nil
+		}
+		path := strings.Split(t.text, ".")
+		t, err := checkFact(path)
+		if err != nil {
+			return nil, err
+		}
+		return whenField{path, t}, nil
+	}
+	if t.text == "(" {
+		x, err := p.parseOr()
+		if err != nil {
+			return nil, err
+		}
+		if p.accept(")") == "" {
+			return nil, fmt.Errorf("missing ) for ( at %d", t.offset)
+		}
+		return x, nil
+	}
+	return nil, fmt.Errorf("unexpected %q at %d", t.text, t.offset)
+}
@@ -0,0 +1,132 @@
+// Copyright © 2026 Iggy <iggy@theiggy.com>
+// All rights reserved.
+//
+// Redistribution and use in source and binary forms, with or without
+// modification, are permitted provided that the following conditions are met:
+//
+// 1. Redistributions of source code must retain the above copyright notice,
+//    this list of conditions and the following disclaimer.
+//
+// 2. Redistributions in binary form must reproduce the above copyright notice,
+//    this list of conditions and the following disclaimer in the documentation
+//    and/or other materials provided with the distribution.
+//
+// 3. Neither the name of the copyright holder nor the names of its contributors
+//    may be used to endorse or promote products derived from this software
+//    without specific prior written permission.
+//
+// THIS SOFTWARE IS PROVIDED BY THE COPYRIGHT HOLDERS AND CONTRIBUTORS "AS IS"
+// AND ANY EXPRESS OR IMPLIED WARRANTIES, INCLUDING, BUT NOT LIMITED TO, THE
+// IMPLIED WARRANTIES OF MERCHANTABILITY AND FITNESS FOR A PARTICULAR PURPOSE
+// ARE DISCLAIMED. IN NO EVENT SHALL THE COPYRIGHT HOLDER OR CONTRIBUTORS BE
+// LIABLE FOR ANY DIRECT, INDIRECT, INCIDENTAL, SPECIAL, EXEMPLARY, OR
+// CONSEQUENTIAL DAMAGES (INCLUDING, BUT NOT LIMITED TO, PROCUREMENT OF
+// SUBSTITUTE GOODS OR SERVICES; LOSS OF USE, DATA, OR PROFITS; OR BUSINESS
+// INTERRUPTION) HOWEVER CAUSED AND ON ANY THEORY OF LIABILITY, WHETHER IN
+// CONTRACT, STRICT LIABILITY, OR TORT (INCLUDING NEGLIGENCE OR OTHERWISE)
+// ARISING IN ANY WAY OUT OF THE USE OF THIS SOFTWARE, EVEN IF ADVISED OF THE
+// POSSIBILITY OF SUCH DAMAGE.
+
+package laws
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/iggy/govern/pkg/facts"
+	"gopkg.in/yaml.v3"
+)
+
+func TestWhen(t *testing.T) {
+	saved := facts.Facts
+	t.Cleanup(func() { facts.Facts = saved })
+	facts.Facts.Hostname = "web1"
+	facts.Facts.MemoryTotal = 16 << 30
+	facts.Facts.Distro.Family = "debian"
+	facts.Facts.Distro.Version = "12"
+
+	tests := []struct {
+		when string
+		want bool
+		err  string // from ParseWhen
+	}{
+		{when: `Distro.Family == "debian"`, want: true},
+		{when: `Distro.Family == 'debian'`, want: true},
+		{when: `Distro.Family != "debian"`, want: false},
+		{when: `Distro.Version >= "12"`, want: true},
+		{when: `Hostname < "web2"`, want: true},
+		{when: `MemoryTotal > 8G`, want: true},
+		{when: `MemoryTotal >= 16GiB`, want: true},
+		{when: `MemoryTotal <= 16384M`, want: true},
+		{when: `MemoryTotal < 1.5T`, want: true},
+		{when: `MemoryTotal == 17179869184`, want: true},
+		{when: `true`, want: true},
+		{when: `!true`, want: false},
+		{when: `!!true`, want: true},
+		{when: `true == false`, want: false},
+		// && binds tighter than ||
+		{when: `true || true && false`, want: true},
+		{when: `(true || true) && false`, want: false},
+		{when: `!(Distro.Family == "debian") || MemoryTotal > 32G`, want: false},
+		{when: `Distro.Family == "debian" && MemoryTotal > 8G && Hostname != "db1"`, want: true},
+
+		{when: `Distro.Family == 1`, err: "can't compare a string with a number using =="},
+		{when: `MemoryTotal > "8G"`, err: "can't compare a number with a string using >"},
+		{when: `true < false`, err: "can't use < on true or false"},
+		{when: `Hostname && true`, err: "&& needs true or false on both sides, got string and bool"},
+		{when: `!Hostname`, err: "can't use ! on a string"},
+		{when: `Hostname`, err: `"Hostname" is a string, not true or false`},
+		{when: `MemoryTotal`, err: `"MemoryTotal" is a number, not true or false`},
+		{when: `Nope == "x"`, err: "unknown fact Nope"},
+		{when: `Hostname.Short == "x"`, err: "fact Hostname has no field Short"},
+		{when: `Groups == 1`, err: "fact Groups can't be compared"},
+		{when: `Distro.Family == "debian`, err: "unterminated string at 17"},
+		{when: `MemoryTotal > 8Q`, err: `unknown size suffix "Q" at 14`},
+		{when: `MemoryTotal > 1.2.3`, err: `bad number "1.2.3" at 14`},
+		{when: `(true`, err: "missing ) for ( at 0"},
+		{when: `true true`, err: `unexpected "true" at 5`},
+		{when: `Hostname ==`, err: "unexpected end of expression"},
+		{when: `Hostname = "x"`, err: `unexpected '=' at 9`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.when, func(t *testing.T) {
+			w, err := ParseWhen(tt.when)
+			if tt.err != "" {
+				if err == nil || err.Error() != tt.err {
+					t.Fatalf("ParseWhen() error = %v, want %q", err, tt.err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if w.String() != tt.when {
+				t.Errorf("String() = %q, want %q", w.String(), tt.when)
+			}
+			got, err := w.Eval()
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got != tt.want {
+				t.Errorf("Eval() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestWhenYAML(t *testing.T) {
+	var law struct {
+		When *When `yaml:"when"`
+	}
+	err := yaml.Unmarshal([]byte("name: x\nwhen: Nope == 1\n"), &law)
+	if err == nil || !strings.Contains(err.Error(), "line 2: when: unknown fact Nope") {
+		t.Fatalf("Unmarshal() error = %v, want the line and the unknown fact", err)
+	}
+	if err := yaml.Unmarshal([]byte(`when: Hostname != ""`), &law); err != nil {
+		t.Fatal(err)
+	}
+	if law.When.String() != `Hostname != ""` {
+		t.Errorf("When = %q", law.When.String())
+	}
+}
@@ -186,6 +186,16 @@ func (s *Service) ExecuteCommand(ctx context.Context, cmd Command) (*CommandResu
 			result.Output, _ = json.Marshal(lawsResult)
 		}
 
+	case CommandTypeJournal:
+		journalResult, err := s.executeJournalCommand(ctx, cmd)
+		if err != nil {
+			result.Success = false
+			result.Error = err.Error()
+		} else {
+			result.Success = true
+			result.Output, _ = json.Marshal(journalResult)
+		}
+
 	default:
 		result.Success = false
 		result.Error = fmt.Sprintf("unknown command type: %s", cmd.Type)
@@ -241,44 +251,43 @@ func (s *Service) executeApplyLawsCommand(ctx context.Context, cmd Command) (int
 	if err := json.Unmarshal(cmd.Payload, &payload); err != nil {
 		return nil, fmt.Errorf("invalid apply laws payload: %w", err)
 	}
+	onError := laws.OnErrorContinue
+	if payload.OnError != "" {
+		var err error
+		onError, err = laws.ParseErrorPolicy(payload.OnError)
+		if err != nil {
+			return nil, fmt.Errorf("invalid apply laws payload: %w", err)
+		}
+	}
 
-	results := make(map[string]interface{})
+	results := ApplyLawsOutput{}
 	for _, lawFile := range payload.LawFiles {
+		started := time.Now()
 		vertices, err := laws.ParseFiles(lawFile)
 		if err != nil {
-			results[lawFile] = map[string]interface{}{
-				"success": false,
-				"error":   err.Error(),
-			}
+			report := laws.NewReport(nil, payload.DryRun, started)
+			report.Error = laws.Redact(err.Error())
+			results[lawFile] = report
 			continue
 		}
 
-		if payload.DryRun {
-			results[lawFile] = map[string]interface{}{
-				"success":   true,
-				"dry_run":   true,
-				"law_count": len(vertices),
-				"message":   "would apply laws (dry run)",
-			}
-		} else {
-			applied := 0
-			errors := []string{}
-			for _, vertex := range vertices {
-				lawNode := vertex.Label()
-				if lawNode.Law != nil {
-					if err := lawNode.Law.Ensure(false); err != nil {
-						errors = append(errors, fmt.Sprintf("%s: %v", lawNode.Name, err))
-					} else {
-						applied++
-					}
-				}
-			}
+		vertices, err = payload.Selector.Filter(vertices)
+		if err != nil {
+			report := laws.NewReport(nil, payload.DryRun, started)
+			report.Error = laws.Redact(err.Error())
+			results[lawFile] = report
+			continue
+		}
 
-			results[lawFile] = map[string]interface{}{
-				"success":    len(errors) == 0,
-				"applied":    applied,
-				"total_laws": len(vertices),
-				"errors":     errors,
+		executor := laws.NewExecutor(payload.Workers, payload.DryRun)
+		executor.OnError = onError
+		lawResults := executor.Run(ctx, vertices)
+		results[lawFile] = laws.NewReport(lawResults, payload.DryRun, started)
+
+		if !payload.DryRun {
+			run := &laws.JournalRun{ID: laws.NewRunID(), Laws: lawFile, Report: *results[lawFile]}
+			if err := laws.WriteJournal(run); err != nil {
+				s.logger.Error().Err(err).Str("dir", laws.JournalDir).Msg("failed to write journal")
 			}
 		}
 	}
@@ -286,6 +295,30 @@ func (s *Service) executeApplyLawsCommand(ctx context.Context, cmd Command) (int
 	return results, nil
 }
 
+func (s *Service) executeJournalCommand(ctx context.Context, cmd Command) (interface{}, error) {
+	var payload JournalPayload
+	if err := json.Unmarshal(cmd.Payload, &payload); err != nil {
+		return nil, fmt.Errorf("invalid journal payload: %w", err)
+	}
+
+	if payload.RunID != "" {
+		run, err := laws.ReadJournal(payload.RunID)
+		if err != nil {
+			return nil, err
+		}
+		return JournalOutput{run}, nil
+	}
+
+	runs, err := laws.ListJournal()
+	if err != nil {
+		return nil, err
+	}
+	if payload.Limit > 0 && len(runs) > payload.Limit {
+		runs = runs[len(runs)-payload.Limit:]
+	}
+	return JournalOutput(runs), nil
+}
+
 func (s *Service) BroadcastCommand(ctx context.Context, cmd Command) (map[uint64]*CommandResult, error) {
 	if cmd.ID == "" {
 		cmd.ID = uuid.New().String()
@@ -4,6 +4,8 @@ import (
 	"context"
 	"encoding/json"
 	"time"
+
+	"github.com/iggy/govern/pkg/laws"
 )
 
 type CommandType string
@@ -12,6 +14,7 @@ const (
 	CommandTypeExec      CommandType = "exec"
 	CommandTypeFacts     CommandType = "facts"
 	CommandTypeApplyLaws CommandType = "apply_laws"
+	CommandTypeJournal   CommandType = "journal"
 )
 
 type Command struct {
@@ -36,8 +39,26 @@ type FactsPayload struct {
 type ApplyLawsPayload struct {
 	LawFiles []string `json:"law_files"`
 	DryRun   bool     `json:"dry_run,omitempty"`
+	Workers  int      `json:"workers,omitempty"`
+	OnError  string   `json:"on_error,omitempty"` // continue|abort, defaults to continue
+
+	laws.Selector // only/skip/tags/no_deps, the same as govern local apply
+}
+
+// ApplyLawsOutput - the output of an apply_laws command, a run report for
+// each law file, the same as `govern local apply --report json`
+type ApplyLawsOutput map[string]*laws.Report
+
+// JournalPayload - which runs to get from a node's journal, every run if
+// RunID isn't set
+type JournalPayload struct {
+	RunID string `json:"run_id,omitempty"` // an id, unique prefix of one, or last
+	Limit int    `json:"limit,omitempty"`  // only the most recent runs
 }
 
+// JournalOutput - the output of a journal command, oldest run first
+type JournalOutput []*laws.JournalRun
+
 type CommandResult struct {
 	ID        string          `json:"id"`
 	Success   bool            `json:"success"`